@@ -0,0 +1,323 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The urlq command reads a JSON object and prints it as a query string
+// encoded by the rules of the query package, which helps writing curl
+// scripts and checking what a server expects:
+//
+//	$ echo '{"q": "go lang", "page": 2, "filter": {"state": "open"}}' | urlq
+//	q=go+lang&page=2&filter%5Bstate%5D=open
+//
+// Usage:
+//
+//	urlq [flags] [file]
+//
+// The object is read from file, or from the standard input.  Parameters are
+// written in the order of the document, unless -sort is given.  Nested
+// objects are encoded as nested structs, arrays as slices, and null values
+// are left out.
+//
+// The flags are:
+//
+//	-schema file
+//		read url tags from the JSON object in file, which maps the
+//		dotted paths of keys to the tag of their field, as in
+//		{"tags": "tag,comma", "filter.since": "since"}
+//	-style name
+//		follow the conventions of "rails", "qs", "jquery", "stripe",
+//		"deepobject", "gorilla" or "grpc-gateway"
+//	-strict
+//		escape keys and values strictly as RFC 3986 recommends
+//	-sort
+//		sort parameters by name
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+)
+
+// styles maps the names accepted by -style to their options.
+var styles = map[string]query.Option{
+	"rails":        query.WithRails(),
+	"qs":           query.WithQS(query.QSOptions{}),
+	"jquery":       query.WithJQuery(false),
+	"stripe":       query.WithStripe(),
+	"deepobject":   query.WithDeepObject(),
+	"gorilla":      query.WithGorillaSchema(),
+	"grpc-gateway": query.WithGRPCGateway(),
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "urlq:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs urlq with the command line arguments args.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("urlq", flag.ContinueOnError)
+	schemaFile := fs.String("schema", "", "read url tags by key path from the JSON object in `file`")
+	style := fs.String("style", "", "follow the conventions of rails, qs, jquery, stripe, deepobject, gorilla or grpc-gateway")
+	strict := fs.Bool("strict", false, "escape keys and values strictly as RFC 3986 recommends")
+	sorted := fs.Bool("sort", false, "sort parameters by name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var opts []query.Option
+	if *style != "" {
+		opt, ok := styles[*style]
+		if !ok {
+			return fmt.Errorf("unknown style %q", *style)
+		}
+		opts = append(opts, opt)
+	}
+	if *strict {
+		opts = append(opts, query.WithStrictEscaping())
+	}
+	if *sorted {
+		opts = append(opts, query.WithKeyOrder(func(a, b string) bool { return a < b }))
+	}
+
+	var schema map[string]string
+	if *schemaFile != "" {
+		data, err := os.ReadFile(*schemaFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return fmt.Errorf("schema: %v", err)
+		}
+	}
+
+	in := stdin
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		return errors.New("too many arguments")
+	}
+
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	doc, err := decodeValue(dec)
+	if err != nil {
+		return err
+	}
+	obj, ok := doc.(object)
+	if !ok {
+		return errors.New("input is not a JSON object")
+	}
+
+	v := obj.value(schema, "")
+	s, err := query.EncodeString(v.Interface(), opts...)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, s)
+	return err
+}
+
+// An object is a JSON object, with its members in document order.
+type object []member
+
+type member struct {
+	key   string
+	value interface{} // object, []interface{}, string, json.Number, bool or nil
+}
+
+// decodeValue reads the next JSON value from dec, keeping the order of the
+// members of objects.
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		var obj object
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key.(string), v})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// structType returns a struct type with a field for each of the keys of
+// objs, in order of appearance, tagged with the tag given by schema for their
+// path under prefix, or named after the key.  The tag is given for both the
+// "url" and "schema" keys, the latter being read with the gorilla style.
+// Fields are pointers with the "omitempty" option, so that keys missing from
+// an object or null are left out.
+func structType(objs []object, schema map[string]string, prefix string) reflect.Type {
+	var keys []string
+	values := make(map[string][]interface{})
+	for _, obj := range objs {
+		for _, m := range obj {
+			if _, ok := values[m.key]; !ok {
+				keys = append(keys, m.key)
+			}
+			values[m.key] = append(values[m.key], m.value)
+		}
+	}
+
+	fields := make([]reflect.StructField, len(keys))
+	for i, key := range keys {
+		tag, ok := schema[prefix+key]
+		if !ok {
+			tag = key
+		}
+		if !strings.Contains(tag, ",omitempty") {
+			tag += ",omitempty"
+		}
+		fields[i] = reflect.StructField{
+			Name: "F" + strconv.Itoa(i),
+			Type: reflect.PtrTo(valueType(values[key], schema, prefix+key+".")),
+			Tag:  reflect.StructTag(fmt.Sprintf("url:%[1]s schema:%[1]s urlq:%[2]s", strconv.Quote(tag), strconv.Quote(key))),
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// valueType returns the Go type holding all of the JSON values vs.
+func valueType(vs []interface{}, schema map[string]string, prefix string) reflect.Type {
+	var t reflect.Type
+	var objs []object
+	var elems []interface{}
+	for _, v := range vs {
+		var vt reflect.Type
+		switch v := v.(type) {
+		case nil:
+			continue
+		case object:
+			objs = append(objs, v)
+			vt = reflect.TypeOf(object(nil))
+		case []interface{}:
+			elems = append(elems, v...)
+			vt = reflect.TypeOf([]interface{}(nil))
+		default:
+			vt = reflect.TypeOf(v)
+		}
+		if t != nil && t != vt {
+			return reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+		t = vt
+	}
+
+	switch t {
+	case nil:
+		return reflect.TypeOf((*interface{})(nil)).Elem()
+	case reflect.TypeOf(object(nil)):
+		return structType(objs, schema, prefix)
+	case reflect.TypeOf([]interface{}(nil)):
+		return reflect.SliceOf(valueType(elems, schema, prefix))
+	}
+	return t
+}
+
+// value returns obj as a value of the struct type built by structType.
+func (obj object) value(schema map[string]string, prefix string) reflect.Value {
+	return convert(obj, structType([]object{obj}, schema, prefix))
+}
+
+// convert returns the JSON value v as a value of type t, as built by
+// valueType.
+func convert(v interface{}, t reflect.Type) reflect.Value {
+	rv := reflect.New(t).Elem()
+	switch v := v.(type) {
+	case nil:
+	case object:
+		if t.Kind() != reflect.Struct {
+			rv.Set(reflect.ValueOf(fmt.Sprint(v)))
+			break
+		}
+		for _, m := range v {
+			for i := 0; i < t.NumField(); i++ {
+				if fieldKey(t.Field(i)) == m.key && m.value != nil {
+					f := t.Field(i)
+					p := reflect.New(f.Type.Elem())
+					p.Elem().Set(convert(m.value, f.Type.Elem()))
+					rv.Field(i).Set(p)
+				}
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice {
+			rv.Set(reflect.ValueOf(v))
+			break
+		}
+		s := reflect.MakeSlice(t, len(v), len(v))
+		for i, e := range v {
+			s.Index(i).Set(convert(e, t.Elem()))
+		}
+		rv.Set(s)
+	default:
+		rv.Set(reflect.ValueOf(v))
+	}
+	return rv
+}
+
+// fieldKey returns the JSON key held by the field f of a struct type built
+// by structType.
+func fieldKey(f reflect.StructField) string {
+	return f.Tag.Get("urlq")
+}
+
+// String implements fmt.Stringer, for objects met where a scalar was
+// expected.
+func (obj object) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range obj {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(m.key)
+		v, _ := json.Marshal(m.value)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
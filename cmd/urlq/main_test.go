@@ -0,0 +1,61 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	schema := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schema, []byte(`{"tags": "tag,comma", "filter.since": "from"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		in   string
+		want string
+	}{
+		{nil, `{}`, ""},
+		{nil, `{"q": "go lang", "page": 2, "all": true, "none": null}`, "q=go+lang&page=2&all=true"},
+		{nil, `{"filter": {"state": "open"}, "ids": [1, 2.5]}`, "filter%5Bstate%5D=open&ids=1&ids=2.5"},
+		{[]string{"-sort"}, `{"b": 1, "a": 2}`, "a=2&b=1"},
+		{[]string{"-schema", schema}, `{"tags": ["a", "b"], "filter": {"since": "2020"}}`, "tag=a%2Cb&filter%5Bfrom%5D=2020"},
+		{[]string{"-style", "rails"}, `{"items": [{"id": 1}, {"id": 2, "x": "y"}]}`, "items%5B%5D%5Bid%5D=1&items%5B%5D%5Bid%5D=2&items%5B%5D%5Bx%5D=y"},
+		{[]string{"-style", "gorilla"}, `{"a": {"b": "c"}}`, "a.b=c"},
+		{[]string{"-strict"}, `{"q": "a b"}`, "q=a%20b"},
+		{[]string{"-sort", "-strict"}, `{"q": "a b", "b": "*"}`, "b=%2A&q=a%20b"},
+		{nil, `{"mixed": [1, "x", {"k": "v"}]}`, "mixed=1&mixed=x&mixed=%7B%22k%22%3A%22v%22%7D"},
+	}
+	for i, tt := range tests {
+		var out strings.Builder
+		if err := run(tt.args, strings.NewReader(tt.in), &out); err != nil {
+			t.Errorf("%d. run(%q) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got := strings.TrimSuffix(out.String(), "\n"); got != tt.want {
+			t.Errorf("%d. run(%q) printed %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		args []string
+		in   string
+	}{
+		{nil, `[1]`},
+		{nil, `{`},
+		{[]string{"-style", "bogus"}, `{}`},
+		{[]string{"a", "b"}, `{}`},
+	} {
+		var out strings.Builder
+		if err := run(tt.args, strings.NewReader(tt.in), &out); err == nil {
+			t.Errorf("run(%q, %q) returned no error", tt.args, tt.in)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package query
+
+import "iter"
+
+// All returns an iterator over the parameters encoded from v with opts, as
+// key and value pairs, so that they can be streamed into a writer without
+// building a url.Values.
+//
+// Pairs are yielded as their fields are encoded, in the deterministic order
+// of Pairs, except that the values of a parameter produced by several fields
+// are not grouped together.  Encoding stops as soon as iteration does.  An
+// encoding error ends the sequence early; use Values or Pairs to learn the
+// error.  Options that constrain the whole query, namely WithMaxLength,
+// WithMaxParams, WithMaxRepeats, WithReservedKeys, WithKeyOrder,
+// WithKeyPriority, WithDedupe and WithOverrides, make All encode v entirely
+// before yielding the pairs of Pairs, so that nothing is yielded if they
+// fail.
+func All(v interface{}, opts ...Option) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		e := &encoder{config: newConfig(opts)}
+		if e.constrainsQuery() {
+			values, err := e.encode(v)
+			if err != nil {
+				return
+			}
+			for _, p := range e.pairs(values) {
+				if !yield(p.Key, p.Value) {
+					return
+				}
+			}
+			return
+		}
+		e.yield = yield
+		e.encode(v)
+	}
+}
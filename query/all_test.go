@@ -0,0 +1,77 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	type Embedded struct {
+		E string `url:"e"`
+	}
+	in := struct {
+		Embedded
+		Q    string      `url:"q"`
+		Tags []string    `url:"tag"`
+		Args EncodedArgs `url:"args"`
+	}{Embedded{"x"}, "go", []string{"a", "b"}, EncodedArgs{"p"}}
+
+	var got []Pair
+	for k, v := range All(in) {
+		got = append(got, Pair{k, v})
+	}
+	want := []Pair{{"q", "go"}, {"tag", "a"}, {"tag", "b"}, {"args.0", "p"}, {"e", "x"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All(%v) yielded %v, want %v", in, got, want)
+	}
+
+	// stopping early
+	got = nil
+	for k, v := range All(in) {
+		got = append(got, Pair{k, v})
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("All(%v) yielded %v before break, want %v", in, got, want[:2])
+	}
+
+	// whole-query options
+	got = nil
+	for k, v := range All(in, WithKeyPriority("e")) {
+		got = append(got, Pair{k, v})
+	}
+	want = []Pair{{"e", "x"}, {"q", "go"}, {"tag", "a"}, {"tag", "b"}, {"args.0", "p"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All(%v) with WithKeyPriority yielded %v, want %v", in, got, want)
+	}
+	for k, v := range All(in, WithMaxParams(2)) {
+		t.Errorf("All(%v) with WithMaxParams(2) yielded %s=%s, want nothing", in, k, v)
+	}
+	for k, v := range All("") {
+		t.Errorf("All(\"\") yielded %s=%s, want nothing", k, v)
+	}
+}
+
+func TestAll_loopPanic(t *testing.T) {
+	in := struct {
+		Q string `url:"q"`
+	}{"go"}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("loop body panic recovered as %v, want %q", r, "boom")
+		}
+	}()
+	for range All(in) {
+		panic("boom")
+	}
+	t.Errorf("loop body panic did not reach the caller")
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+)
+
+// A Builder builds a URL from a base URL, the parameters of structs, and
+// individual parameters.  Builders are created by URL, and their methods can
+// be chained for the common case of a struct plus a few ad-hoc tweaks:
+//
+//	u, err := query.URL("https://api.example.com/items").
+//		With(opt).
+//		Set("debug", "1").
+//		Del("verbose").
+//		Build()
+//
+// Errors are deferred until Build: once a step fails, later steps do nothing
+// and Build returns the first error.
+type Builder struct {
+	u      *url.URL
+	values url.Values
+	err    error
+}
+
+// URL returns a Builder starting from base, whose existing query parameters
+// are kept.
+func URL(base string) *Builder {
+	u, err := url.Parse(base)
+	if err != nil {
+		return &Builder{err: err}
+	}
+	return &Builder{u: u, values: u.Query()}
+}
+
+// With merges the parameters encoded from v by Values with opts, replacing
+// parameters of the same name, as done by BuildURL.
+func (b *Builder) With(v interface{}, opts ...Option) *Builder {
+	if b.err != nil {
+		return b
+	}
+	values, err := Values(v, opts...)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	mergeValues(b.values, values)
+	return b
+}
+
+// Set sets the parameter key to value, replacing any existing values.
+func (b *Builder) Set(key, value string) *Builder {
+	if b.err == nil {
+		b.values.Set(key, value)
+	}
+	return b
+}
+
+// Add adds value to the parameter key, keeping any existing values.
+func (b *Builder) Add(key, value string) *Builder {
+	if b.err == nil {
+		b.values.Add(key, value)
+	}
+	return b
+}
+
+// Del removes the parameter key.
+func (b *Builder) Del(key string) *Builder {
+	if b.err == nil {
+		b.values.Del(key)
+	}
+	return b
+}
+
+// Build returns the resulting URL, with its query encoded by
+// url.Values.Encode, or the first error met while building it.  Hosts with
+// non-ASCII characters are converted to their punycode form, as in
+// "xn--bcher-kva.example" for "bücher.example", or reported as errors if they
+// are not valid internationalized domain names.  Non-ASCII characters in the
+// path and fragment are percent-encoded.
+func (b *Builder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	u := *b.u
+	if err := asciiHost(&u); err != nil {
+		return "", err
+	}
+	u.RawQuery = b.values.Encode()
+	return u.String(), nil
+}
+
+// String returns the resulting URL, or the empty string if an error was met
+// while building it.  Use Build to get the error.
+func (b *Builder) String() string {
+	s, _ := b.Build()
+	return s
+}
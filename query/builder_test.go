@@ -0,0 +1,58 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	opt := struct {
+		Query   string `url:"q"`
+		Verbose bool   `url:"verbose"`
+	}{"a b", true}
+
+	tests := []struct {
+		b    *Builder
+		want string
+	}{
+		{URL("http://example.com/search"), "http://example.com/search"},
+		{
+			URL("http://example.com/search?page=2#top").With(opt).Set("debug", "1").Del("verbose"),
+			"http://example.com/search?debug=1&page=2&q=a+b#top",
+		},
+		{
+			URL("/search?q=old").With(opt, WithTagKey("none")).Add("tag", "a").Add("tag", "b"),
+			"/search?Query=a+b&Verbose=true&q=old&tag=a&tag=b",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := tt.b.Build()
+		if err != nil {
+			t.Errorf("%d. Build() returned error: %v", i, err)
+		}
+		if got != tt.want {
+			t.Errorf("%d. Build() returned %q, want %q", i, got, tt.want)
+		}
+		if s := tt.b.String(); s != tt.want {
+			t.Errorf("%d. String() returned %q, want %q", i, s, tt.want)
+		}
+	}
+}
+
+func TestBuilder_errors(t *testing.T) {
+	for i, b := range []*Builder{
+		URL("http://[::1").Set("a", "b"),
+		URL("http://example.com").With("invalid").Set("a", "b").Del("c"),
+	} {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("%d. Build() returned no error", i)
+		}
+		if s := b.String(); s != "" {
+			t.Errorf("%d. String() returned %q, want empty string", i, s)
+		}
+	}
+}
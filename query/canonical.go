@@ -0,0 +1,127 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SigV4Query returns the canonical query string of the parameters encoded from
+// v, as used by AWS Signature Version 4 to sign requests.  The options are the
+// ones accepted by Values.
+//
+// Keys and values are percent-encoded per RFC 3986, leaving only unreserved
+// characters unescaped and using uppercase hex digits, so spaces become "%20".
+// Pairs are sorted by encoded key and, for repeated keys, by encoded value.
+// Empty values are kept as "key=".
+func SigV4Query(v interface{}, opts ...Option) (string, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return "", err
+	}
+	return canonicalQuery(values), nil
+}
+
+// Canonicalize parses rawQuery, with or without its leading "?", and
+// re-encodes it in a canonical form, so that queries differing only in the
+// order of their parameters or in how they are escaped produce the same
+// string.  This makes it suitable for cache keys, deduplication and signature
+// verification.
+//
+// Parameters are sorted by name.  The values of a repeated parameter keep
+// their order, which is usually significant.  Keys and values are escaped with
+// url.QueryEscape unless an option, such as WithStrictEscaping, selects
+// another escaping; WithSemicolons accepts ";" as a separator as well, and
+// writes it.
+func Canonicalize(rawQuery string, opts ...Option) (string, error) {
+	c := newConfig(opts)
+	values, err := c.parseQuery(strings.TrimPrefix(rawQuery, "?"))
+	if err != nil {
+		return "", fmt.Errorf("query: Canonicalize() %v", err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []Pair
+	for _, k := range keys {
+		for _, v := range values[k] {
+			pairs = append(pairs, Pair{k, v})
+		}
+	}
+	return c.writePairs(pairs), nil
+}
+
+// canonicalQuery returns values encoded as escapeRFC3986 pairs joined by "&",
+// sorted by encoded key and then by encoded value.
+func canonicalQuery(values url.Values) string {
+	var pairs [][2]string
+	for k, vs := range values {
+		k = escapeRFC3986(k)
+		for _, v := range vs {
+			pairs = append(pairs, [2]string{k, escapeRFC3986(v)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	var buf strings.Builder
+	for i, p := range pairs {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(p[0] + "=" + p[1])
+	}
+	return buf.String()
+}
+
+// escapeRFC3986 percent-encodes every byte of s outside the unreserved set of
+// RFC 3986 (ALPHA, DIGIT, "-", ".", "_" and "~"), using uppercase hex digits.
+func escapeRFC3986(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+// isUnreserved reports whether c is an unreserved character of RFC 3986.
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// escapeURIComponent escapes s as the encodeURIComponent function of
+// JavaScript does, except that spaces are written as "+", as done by
+// jQuery.param and HTML forms.
+func escapeURIComponent(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == ' ':
+			buf.WriteByte('+')
+		case isUnreserved(c) || strings.IndexByte("!*'()", c) >= 0:
+			buf.WriteByte(c)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestSigV4Query(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, ""},
+		{
+			struct {
+				Version string
+				Action  string
+			}{"2010-05-08", "ListUsers"},
+			"Action=ListUsers&Version=2010-05-08",
+		},
+		{
+			// repeated keys are sorted by value, and keys sharing a prefix
+			// by the full key
+			struct {
+				A  []string `url:"a"`
+				AB string   `url:"a-b"`
+				AZ string   `url:"a_z"`
+			}{[]string{"z", "b", ""}, "x", "y"},
+			"a=&a=b&a=z&a-b=x&a_z=y",
+		},
+		{
+			struct {
+				Q string `url:"q"`
+			}{"a b+c/~é*"},
+			"q=a%20b%2Bc%2F~%C3%A9%2A",
+		},
+		{
+			struct {
+				Filter []string `url:"filter,brackets"`
+			}{[]string{"x"}},
+			"filter%5B%5D=x",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := SigV4Query(tt.in)
+		if err != nil {
+			t.Errorf("%d. SigV4Query(%v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. SigV4Query(%v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts []Option
+		want string
+	}{
+		{"", nil, ""},
+		{"?b=2&a=1", nil, "a=1&b=2"},
+		{"a=2&b=x&a=1", nil, "a=2&a=1&b=x"},
+		{"q=a%20b&&r=%7e%2a", nil, "q=a+b&r=~%2A"},
+		{"q=a+b", []Option{WithStrictEscaping()}, "q=a%20b"},
+		{"k", nil, "k="},
+		{"b=1;a=2", []Option{WithSemicolons()}, "a=2;b=1"},
+	}
+	for i, tt := range tests {
+		got, err := Canonicalize(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Canonicalize(%q) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. Canonicalize(%q) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := Canonicalize("a=%zz"); err == nil {
+		t.Errorf("Canonicalize with an invalid escape returned no error")
+	}
+}
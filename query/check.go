@@ -0,0 +1,350 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// knownOptions lists the options recognized in url tags.
+var knownOptions = map[string]bool{
+	"omitempty": true,
+	"int":       true,
+	"unix":      true,
+	"comma":     true,
+	"space":     true,
+	"semicolon": true,
+	"brackets":  true,
+	"numbered":  true,
+	"required":  true,
+	"file":      true,
+	"style":     true,
+	"explode":   true,
+	"default":   true,
+	"redact":    true,
+	"min":       true,
+	"max":       true,
+	"len":       true,
+	"utc":       true,
+	"tz":        true,
+	"dateonly":  true,
+	"start":     true,
+	"end":       true,
+	"range":     true,
+	"precision": true,
+	"lnglat":    true,
+	"minor":     true,
+	"currency":  true,
+}
+
+// valueOptions lists the options given as "key=value".
+var valueOptions = map[string]bool{
+	"style":     true,
+	"explode":   true,
+	"default":   true,
+	"min":       true,
+	"max":       true,
+	"len":       true,
+	"tz":        true,
+	"start":     true,
+	"end":       true,
+	"range":     true,
+	"precision": true,
+	"currency":  true,
+}
+
+// delimiterOptions lists the options that control how slices and arrays are
+// encoded.  At most one of them may be given for a field.
+var delimiterOptions = []string{"comma", "space", "semicolon", "brackets", "numbered"}
+
+// Check reports problems with the url tags of the struct type of v, which may
+// be a struct, a pointer to a struct, or a nil pointer to a struct.  Nested
+// and embedded structs are checked as well.  The problems reported are:
+//
+//   - unknown tag options and OpenAPI styles
+//   - more than one of the "comma", "space", "semicolon", "brackets" and
+//     "numbered" options on a field
+//   - the "int" option on a field that is not a bool or a slice of bools
+//   - the "unix", "utc", "tz" and "dateonly" options on a field that is not
+//     a time.Time, a TimeRange or a slice of them, and "tz" options naming
+//     unknown locations
+//   - the "start", "end" and "range" options on a field that is not a
+//     TimeRange
+//   - the "precision" and "lnglat" options on a field that is not a LatLng
+//     or a BoundingBox, or a slice of them, and invalid precisions
+//   - the "minor" and "currency" options on a field that is not a Money
+//   - the "file" option on a field that is not an io.Reader or a []byte
+//   - "min" and "max" options that are not numbers, or on a field that is not
+//     a number or a slice of them
+//   - a "len" option that is not an integer, or on a field that is not a
+//     string, slice, array or map
+//   - "pattern" tags that are not valid regular expressions
+//   - fields that encode to the same URL parameter name
+//
+// Check is meant to be called from tests or init functions, so that tag
+// mistakes are caught early instead of producing unexpected queries.  The
+// options are the ones later passed to Values, as some of them change how
+// names are derived.  All problems found are joined into the returned error.
+func Check(v interface{}, opts ...Option) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("query: Check() expects struct input. Got %v", t)
+	}
+
+	c := &checker{
+		config:   newConfig(opts),
+		names:    make(map[string]string),
+		visiting: make(map[reflect.Type]bool),
+	}
+	c.checkStruct(t, t.Name(), "")
+	return errors.Join(c.errs...)
+}
+
+// checker holds the state of a call to Check.
+type checker struct {
+	*config
+
+	// names maps each URL parameter name seen to the field producing it.
+	names map[string]string
+
+	// visiting holds the struct types being checked, to stop at recursive
+	// types.
+	visiting map[reflect.Type]bool
+
+	errs []error
+}
+
+func (c *checker) errorf(field string, format string, args ...interface{}) {
+	c.errs = append(c.errs, fmt.Errorf("query: field %s: %s", field, fmt.Sprintf(format, args...)))
+}
+
+// checkStruct checks the fields of the struct type t, whose fields are named
+// path.Field in error messages and scope[name] in URL parameters.  It follows
+// the same rules as encoder.reflectValue.
+func (c *checker) checkStruct(t reflect.Type, path, scope string) {
+	if c.visiting[t] {
+		return
+	}
+	c.visiting[t] = true
+	defer delete(c.visiting, t)
+
+	// embedded holds the indexes of embedded struct fields
+	var embedded []int
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := structField(t, i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+
+		tag := c.fieldTag(sf)
+		if tag == "-" || companionOnly(sf, tag) {
+			continue
+		}
+		name, opts := parseTag(tag)
+		field := path + "." + sf.Name
+
+		if sf.Anonymous && (name == "" || c.embeddedNaming == EmbeddedFlatten) {
+			if et, ok := embeddedStructType(sf.Type); ok {
+				if c.embeddedOrder == EmbeddedInline {
+					c.checkStruct(et, field, scope)
+				} else {
+					embedded = append(embedded, i)
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+		}
+
+		if name == "" {
+			name = c.fieldName(sf)
+		}
+		if scope != "" {
+			name = c.scopedName(scope, name)
+		}
+
+		c.checkOptions(field, sf.Type, opts)
+		if p, ok := sf.Tag.Lookup("pattern"); ok {
+			if _, err := compilePattern(p); err != nil {
+				c.errorf(field, "%v", err)
+			}
+		}
+
+		ft := sf.Type
+		if !ft.Implements(encoderType) {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType && !ft.Implements(encoderType) {
+				c.checkStruct(ft, field, name)
+				continue
+			}
+			if (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && opts.Contains("brackets") {
+				name += "[]"
+			}
+		}
+
+		if prev, ok := c.names[name]; ok {
+			c.errorf(field, "URL parameter %q is also produced by field %s", name, prev)
+			continue
+		}
+		c.names[name] = field
+	}
+
+	for _, i := range embedded {
+		sf := structField(t, i)
+		et, _ := embeddedStructType(sf.Type)
+		c.checkStruct(et, path+"."+sf.Name, scope)
+	}
+}
+
+// checkOptions checks the tag options opts of the field of type t.
+func (c *checker) checkOptions(field string, t reflect.Type, opts tagOptions) {
+	var delims []string
+	for _, o := range opts {
+		key, value, hasValue := strings.Cut(o, "=")
+		if !knownOptions[key] || hasValue && !valueOptions[key] {
+			c.errorf(field, "unknown option %q", o)
+		}
+		if _, ok := styleDelimiters[value]; key == "style" && !ok {
+			c.errorf(field, "unknown style %q", value)
+		}
+	}
+	for _, o := range delimiterOptions {
+		if opts.Contains(o) {
+			delims = append(delims, o)
+		}
+	}
+	if len(delims) > 1 {
+		c.errorf(field, "conflicting options %s", strings.Join(delims, ", "))
+	}
+
+	et := elemType(t)
+	if opts.Contains("int") && et.Kind() != reflect.Bool {
+		c.errorf(field, `option "int" requires a bool, not %v`, t)
+	}
+	if opts.Contains("unix") && !isTimeType(et) {
+		c.errorf(field, `option "unix" requires a time.Time, not %v`, t)
+	}
+	if opts.Contains("utc") && !isTimeType(et) {
+		c.errorf(field, `option "utc" requires a time.Time, not %v`, t)
+	}
+	if opts.Contains("dateonly") && !isTimeType(et) {
+		c.errorf(field, `option "dateonly" requires a time.Time, not %v`, t)
+	}
+	if name, ok := opts.Value("tz"); ok {
+		if !isTimeType(et) {
+			c.errorf(field, `option "tz" requires a time.Time, not %v`, t)
+		}
+		if _, err := loadLocation(name); err != nil {
+			c.errorf(field, `option "tz" has unknown location %q`, name)
+		}
+	}
+	if opts.Contains("file") && !isFileType(t) {
+		c.errorf(field, `option "file" requires an io.Reader or []byte, not %v`, t)
+	}
+	for _, rule := range []string{"min", "max"} {
+		limit, ok := opts.Value(rule)
+		if !ok {
+			continue
+		}
+		if _, err := strconv.ParseFloat(limit, 64); err != nil {
+			c.errorf(field, "option %q has invalid bound %q", rule, limit)
+		}
+		if !isNumberKind(et.Kind()) {
+			c.errorf(field, "option %q requires a number, not %v", rule, t)
+		}
+	}
+	for _, o := range []string{"start", "end", "range"} {
+		if _, ok := opts.Value(o); (ok || opts.Contains(o)) && indirectType(t) != timeRangeType {
+			c.errorf(field, "option %q requires a query.TimeRange, not %v", o, t)
+		}
+	}
+	if p, ok := opts.Value("precision"); ok {
+		if n, err := strconv.Atoi(p); err != nil || n < 0 {
+			c.errorf(field, `option "precision" has invalid value %q`, p)
+		}
+	}
+	for _, o := range []string{"precision", "lnglat"} {
+		if _, ok := opts.Value(o); (ok || opts.Contains(o)) && !isGeoType(et) {
+			c.errorf(field, "option %q requires a query.LatLng or query.BoundingBox, not %v", o, t)
+		}
+	}
+	for _, o := range []string{"minor", "currency"} {
+		if _, ok := opts.Value(o); (ok || opts.Contains(o)) && indirectType(t) != moneyType {
+			c.errorf(field, "option %q requires a query.Money, not %v", o, t)
+		}
+	}
+	if n, ok := opts.Value("len"); ok {
+		if _, err := strconv.Atoi(n); err != nil {
+			c.errorf(field, `option "len" has invalid length %q`, n)
+		}
+		switch indirectType(t).Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		default:
+			c.errorf(field, `option "len" requires a string, slice, array or map, not %v`, t)
+		}
+	}
+}
+
+// isTimeType reports whether the options for times apply to values of type
+// t.
+func isTimeType(t reflect.Type) bool {
+	return t == timeType || t == timeRangeType
+}
+
+// isNumberKind reports whether values of kind k are numbers.
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isFileType reports whether fields of type t may have the "file" option.
+func isFileType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr && !t.Implements(readerType) {
+		t = t.Elem()
+	}
+	return t.Implements(readerType) || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// elemType returns the type of the individual values encoded for a field of
+// type t, following pointers, slices and arrays.
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// embeddedStructType is the type based counterpart of embeddedStruct.  It
+// returns the struct type of the anonymous field of type t, following
+// pointers, and reports whether the field is flattened.
+func embeddedStructType(t reflect.Type) (reflect.Type, bool) {
+	if !nestedStructType(t) {
+		return nil, false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, true
+}
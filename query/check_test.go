@@ -0,0 +1,124 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recursive struct {
+	Name string     `url:"name"`
+	Next *recursive `url:"next"`
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want []string // substrings of the expected errors, in order
+	}{
+		{
+			struct {
+				A []string  `url:"a,comma,omitempty"`
+				B bool      `url:"b,int"`
+				C []bool    `url:"c,int,space"`
+				D time.Time `url:"d,unix"`
+				E Nested    `url:"e"`
+				F EncodedArgs
+				G io.Reader `url:"g,file"`
+				H []byte    `url:"h,file"`
+				I int       `url:"i,default=20"`
+			}{},
+			nil,
+		},
+		{(*recursive)(nil), nil},
+		{A{}, nil},
+		{
+			struct {
+				A string `url:"a,omitempty,comma,bogus"`
+			}{},
+			[]string{`.A: unknown option "bogus"`},
+		},
+		{
+			struct {
+				A []string `url:"a,comma,brackets"`
+			}{},
+			[]string{".A: conflicting options comma, brackets"},
+		},
+		{
+			struct {
+				A int       `url:"a,int"`
+				B string    `url:"b,unix"`
+				C time.Time `url:"c,unix"`
+				D string    `url:"d,file"`
+			}{},
+			[]string{`.A: option "int" requires a bool`, `.B: option "unix" requires a time.Time`, `.D: option "file" requires an io.Reader or []byte`},
+		},
+		{
+			struct {
+				A time.Time   `url:"a,utc"`
+				B []time.Time `url:"b,tz=Asia/Tokyo"`
+				C string      `url:"c,utc"`
+				D int         `url:"d,tz=UTC"`
+				E time.Time   `url:"e,tz=Nowhere/Special"`
+				F time.Time   `url:"f,dateonly"`
+				G string      `url:"g,dateonly"`
+			}{},
+			[]string{`.C: option "utc" requires a time.Time`, `.D: option "tz" requires a time.Time`, `.E: option "tz" has unknown location "Nowhere/Special"`, `.G: option "dateonly" requires a time.Time`},
+		},
+		{
+			struct {
+				A int      `url:"a,min=1,max=500"`
+				B []string `url:"b,len=2" pattern:"^[a-z]+$"`
+				C string   `url:"c,min=1"`
+				D int      `url:"d,max=x,len=2"`
+				E string   `pattern:"("`
+			}{},
+			[]string{`.C: option "min" requires a number`, `.D: option "max" has invalid bound "x"`, `.D: option "len" requires a string`, `.E: invalid pattern "("`},
+		},
+		{
+			struct {
+				A string `url:"a"`
+				B string `url:"a"`
+				N struct {
+					C string `url:"c"`
+				} `url:"n"`
+				D string `url:"n[c]"`
+			}{},
+			[]string{`.B: URL parameter "a" is also produced by field .A`, `.D: URL parameter "n[c]" is also produced by field .N.C`},
+		},
+		{
+			D{},
+			[]string{`D.B.C: URL parameter "C" is also produced by field D.C`},
+		},
+		{"", []string{"expects struct input"}},
+	}
+
+	for i, tt := range tests {
+		err := Check(tt.in)
+		if len(tt.want) == 0 {
+			if err != nil {
+				t.Errorf("%d. Check(%T) returned error: %v", i, tt.in, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%d. Check(%T) returned no error, want %q", i, tt.in, tt.want)
+			continue
+		}
+		got := strings.Split(err.Error(), "\n")
+		if len(got) != len(tt.want) {
+			t.Errorf("%d. Check(%T) returned %q, want %q", i, tt.in, got, tt.want)
+			continue
+		}
+		for j := range got {
+			if !strings.Contains(got[j], tt.want[j]) {
+				t.Errorf("%d. Check(%T) returned %q, want %q", i, tt.in, got[j], tt.want[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Cookies returns the cookies encoded from v.  It follows the rules of
+// Values, except that names and options are read from "cookie" tags, and
+// only fields with a cookie tag are encoded.  Each value becomes a cookie
+// with only its name and value set, suitable for http.Request.AddCookie.
+// Cookies are sorted by name.  A name or value that is not valid in a cookie,
+// such as a value holding a semicolon or a double quote, is reported as a
+// *FieldError rather than left for net/http to drop.  For example:
+//
+//	type Options struct {
+//		Query   string `url:"q"`
+//		Session string `cookie:"session_id,omitempty"`
+//	}
+//
+// Since fields with a cookie tag but no url tag are skipped by Values, the
+// same struct can describe both the query and the cookies of a request.
+func Cookies(v interface{}, opts ...Option) ([]*http.Cookie, error) {
+	values, err := companionValues("cookie", v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var cookies []*http.Cookie
+	for _, name := range names {
+		for _, s := range values[name] {
+			c := &http.Cookie{Name: name, Value: s}
+			if err := c.Valid(); err != nil {
+				return nil, &FieldError{Name: name, Err: err}
+			}
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	opt := struct {
+		Query   string   `url:"q"`
+		Session string   `cookie:"session_id"`
+		Theme   string   `cookie:"theme,omitempty"`
+		Flags   []string `cookie:"flag"`
+		Tracked bool     `cookie:"tracked,int"`
+	}{
+		Query:   "foo",
+		Session: "abc123",
+		Flags:   []string{"a", "b"},
+		Tracked: true,
+	}
+
+	cookies, err := Cookies(opt)
+	if err != nil {
+		t.Fatalf("Cookies(%v) returned error: %v", opt, err)
+	}
+	var got []string
+	for _, c := range cookies {
+		got = append(got, c.String())
+	}
+	want := []string{"flag=a", "flag=b", "session_id=abc123", "tracked=1"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Cookies(%v) returned %v, want %v", opt, got, want)
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if c, err := req.Cookie("session_id"); err != nil || c.Value != "abc123" {
+		t.Errorf("request cookie session_id = %v, %v", c, err)
+	}
+
+	v, err := Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	if want := (url.Values{"q": {"foo"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+}
+
+func TestCookies_invalid(t *testing.T) {
+	tests := []interface{}{
+		struct {
+			Session string `cookie:"session_id"`
+		}{"a;b"},
+		struct {
+			Theme string `cookie:"theme"`
+		}{`"dark"`},
+		struct {
+			Theme string `cookie:"the me"`
+		}{"dark"},
+	}
+	for i, in := range tests {
+		_, err := Cookies(in)
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			t.Errorf("%d. Cookies(%v) returned error %v, want a *FieldError", i, in, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrCursorVersion is returned, wrapped, by DecodeCursor for cursors encoded
+// with another version.
+var ErrCursorVersion = errors.New("query: cursor version mismatch")
+
+// EncodeCursor encodes v using Values and returns it as an opaque cursor
+// string for keyset pagination, tagged with version.  The cursor uses the URL
+// safe base64 alphabet without padding, so it can be used as a parameter value
+// without escaping.  The options are the ones accepted by Values.
+//
+// Cursors are encoded, not encrypted: clients can read and forge them.  Use
+// EncryptValues for cursors that must be tamper-proof.
+//
+// Bumping version when the struct changes lets DecodeCursor reject cursors
+// handed out before the change.
+func EncodeCursor(version uint8, v interface{}, opts ...Option) (string, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return "", err
+	}
+	b := append([]byte{version}, values.Encode()...)
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes cursor, produced by EncodeCursor with the same
+// version, into v using Decode.  It returns an error wrapping
+// ErrCursorVersion if the cursor has another version; CursorVersion can be
+// used to decode older cursors into the structs of their version.
+func DecodeCursor(cursor string, version uint8, v interface{}, opts ...Option) error {
+	got, values, err := parseCursor(cursor)
+	if err != nil {
+		return err
+	}
+	if got != version {
+		return fmt.Errorf("%w: got %d, want %d", ErrCursorVersion, got, version)
+	}
+	return Decode(values, v, opts...)
+}
+
+// CursorVersion returns the version cursor was encoded with by EncodeCursor.
+func CursorVersion(cursor string) (uint8, error) {
+	version, _, err := parseCursor(cursor)
+	return version, err
+}
+
+// parseCursor returns the version and parameters of cursor.
+func parseCursor(cursor string) (uint8, url.Values, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) == 0 {
+		return 0, nil, fmt.Errorf("query: malformed cursor %q", cursor)
+	}
+	values, err := url.ParseQuery(string(b[1:]))
+	if err != nil {
+		return 0, nil, fmt.Errorf("query: malformed cursor %q: %v", cursor, err)
+	}
+	return b[0], values, nil
+}
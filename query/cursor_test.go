@@ -0,0 +1,55 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type keyset struct {
+	ID      int       `url:"id"`
+	Created time.Time `url:"created,unix"`
+}
+
+func TestEncodeCursor(t *testing.T) {
+	in := keyset{ID: 42, Created: time.Unix(1500000000, 0).UTC()}
+
+	cursor, err := EncodeCursor(2, in)
+	if err != nil {
+		t.Fatalf("EncodeCursor(%v) returned error: %v", in, err)
+	}
+	if url.QueryEscape(cursor) != cursor {
+		t.Errorf("EncodeCursor(%v) returned %q, which needs escaping", in, cursor)
+	}
+
+	if version, err := CursorVersion(cursor); err != nil || version != 2 {
+		t.Errorf("CursorVersion(%q) returned %v, %v, want 2", cursor, version, err)
+	}
+
+	var out keyset
+	if err := DecodeCursor(cursor, 2, &out); err != nil {
+		t.Fatalf("DecodeCursor(%q) returned error: %v", cursor, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("DecodeCursor(%q) decoded %v, want %v", cursor, out, in)
+	}
+
+	if err := DecodeCursor(cursor, 3, &out); !errors.Is(err, ErrCursorVersion) {
+		t.Errorf("DecodeCursor(%q, 3) returned %v, want ErrCursorVersion", cursor, err)
+	}
+}
+
+func TestDecodeCursor_invalid(t *testing.T) {
+	for _, cursor := range []string{"", "not base64!", "ASU"} {
+		var out keyset
+		if err := DecodeCursor(cursor, 1, &out); err == nil {
+			t.Errorf("DecodeCursor(%q) returned no error", cursor)
+		}
+	}
+}
@@ -0,0 +1,712 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var decoderType = reflect.TypeOf(new(Decoder)).Elem()
+
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
+// Decoder is an interface implemented by any type that wishes to decode
+// itself from URL values in a non-standard way.  It is the counterpart of
+// Encoder.
+type Decoder interface {
+	DecodeValues(key string, v url.Values) error
+}
+
+// Decode populates the struct pointed to by v from values.  It is the inverse
+// of Values and follows the same rules, reading each field from the URL
+// parameter that Values would encode it to.  In particular:
+//
+// Fields whose parameter is not present in values are left unchanged.  For a
+// field holding a single value, only the first value of its parameter is
+// used.
+//
+// Slices and arrays are decoded from multiple values, or from a single
+// delimited value if the "comma", "space" or "semicolon" option is given.
+// The "brackets" and "numbered" options are honored as well.
+//
+// Booleans with the "int" option are decoded from "1" and "0", and
+// time.Time values with the "unix" option from Unix times, which result in
+// UTC times.  time.Time values with the "dateonly" option, or decoded with
+// WithDateOnly, are parsed as dates.  Other time.Time values are parsed as
+// RFC3339 timestamps.
+//
+// Nil pointers are allocated when their parameter is present.  Pointer
+// fields whose parameter holds an empty value, which Values encodes nil
+// pointers as, are set to nil instead, unless they point to strings.  Types
+// implementing Decoder decode themselves; other types implementing
+// encoding.TextUnmarshaler are decoded using their UnmarshalText method.
+//
+// Fields with the "required" option whose parameter is missing are reported
+// as errors.
+//
+// Decode stops at the first field that fails to decode, unless the
+// WithCollectErrors option is given.
+func Decode(values url.Values, v interface{}, opts ...Option) error {
+	c := newConfig(opts)
+	o := c.currentObserver()
+	if o == nil {
+		return c.decode(values, v)
+	}
+	start := time.Now()
+	err := c.decode(values, v)
+	o.Observe(Stats{
+		Op:       OpDecode,
+		Type:     reflect.TypeOf(v),
+		Duration: time.Since(start),
+		Params:   countPairs(values),
+		Err:      err,
+	})
+	return err
+}
+
+// decode implements Decode.
+func (c *config) decode(values url.Values, v interface{}) (err error) {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("query: Decode() expects a non-nil struct pointer. Got %T", v)
+	}
+	val = val.Elem()
+
+	d := &decoder{config: c, values: values}
+
+	// Report unexpected panics as errors, as Values does.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("query: panic decoding field %s.%s: %v", val.Type(), strings.Join(d.path, "."), r)
+		}
+	}()
+
+	err = d.reflectValue(val, "")
+	if err == nil && len(d.errs) > 0 {
+		err = errors.Join(d.errs...)
+	}
+	return err
+}
+
+// decoder holds the state of a single call to Decode.
+type decoder struct {
+	*config
+
+	values url.Values
+
+	// errs holds the field errors collected so far when collectErrors is
+	// set.
+	errs []error
+
+	// path holds the Go field names leading to the field being decoded.
+	path []string
+
+	// redacting is set while decoding a redacted field.
+	redacting bool
+}
+
+// fieldError handles err, which occurred while decoding the field named name,
+// like encoder.fieldError does.  Unlike encoding errors, decoding errors are
+// always wrapped in a *FieldError, so that they name the parameter at fault.
+func (d *decoder) fieldError(name string, err error) error {
+	if _, ok := err.(*FieldError); !ok {
+		if vs := d.values[name]; d.redacting && len(vs) > 0 {
+			err = redactError(err, vs...)
+		}
+		err = &FieldError{Name: name, Err: err}
+	}
+	if !d.collectErrors {
+		return err
+	}
+	d.errs = append(d.errs, err)
+	return nil
+}
+
+// reflectValue populates the fields of the struct val from d.values.
+func (d *decoder) reflectValue(val reflect.Value, scope string) error {
+	depth := len(d.path)
+	typ := val.Type()
+
+	// Fields nested in a redacted field are redacted as well
+	redacting := d.redacting
+	defer func() { d.redacting = redacting }()
+
+	for i := 0; i < typ.NumField(); i++ {
+		sf := structField(typ, i)
+		d.path = append(d.path[:depth], sf.Name)
+
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+
+		tag := d.fieldTag(sf)
+		if tag == "-" || companionOnly(sf, tag) {
+			continue
+		}
+		name, opts := parseTag(tag)
+		sv := val.Field(i)
+		d.redacting = redacting || d.redacts(sf, name, opts)
+
+		if sf.Anonymous && (name == "" || d.embeddedNaming == EmbeddedFlatten) {
+			if _, ok := embeddedStructType(sf.Type); ok {
+				// Follow pointers to the embedded struct, allocating
+				// them where possible.
+				for sv.Kind() == reflect.Ptr {
+					if sv.IsNil() {
+						if !sv.CanSet() {
+							break
+						}
+						sv.Set(reflect.New(sv.Type().Elem()))
+					}
+					sv = sv.Elem()
+				}
+				if sv.Kind() == reflect.Struct {
+					if err := d.reflectValue(sv, scope); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+		}
+
+		if name == "" {
+			name = d.fieldName(sf)
+		}
+		if scope != "" {
+			name = d.scopedName(scope, name)
+		}
+
+		if err := d.decodeField(sv, name, opts); err != nil {
+			return err
+		}
+	}
+
+	d.path = d.path[:depth]
+	return nil
+}
+
+// decodeField populates the field value sv from the URL parameter name.
+func (d *decoder) decodeField(sv reflect.Value, name string, opts tagOptions) error {
+	t := sv.Type()
+
+	// Time ranges, amounts of money and geographic types are decoded
+	// following their options, as they are encoded
+	switch ft := indirectType(t); {
+	case ft == timeRangeType:
+		return d.decodeTyped(sv, name, opts, d.decodeTimeRange)
+	case ft == moneyType:
+		return d.decodeTyped(sv, name, opts, d.decodeMoney)
+	case isGeoType(ft):
+		return d.decodeTyped(sv, name, opts, d.decodeGeo(ft))
+	}
+
+	// Detect if sv or a pointer to sv implements Decoder
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(decoderType) {
+		sv = sv.Addr()
+		t = sv.Type()
+	}
+	if t.Implements(decoderType) {
+		if t.Kind() == reflect.Ptr && sv.IsNil() {
+			if !d.hasPrefix(name) {
+				return nil
+			}
+			sv.Set(reflect.New(t.Elem()))
+		}
+		if err := sv.Interface().(Decoder).DecodeValues(name, d.values); err != nil {
+			return d.fieldError(name, err)
+		}
+		return nil
+	}
+
+	if opts.Contains("required") && !d.present(name, t, opts) {
+		return d.fieldError(name, errors.New("missing required parameter"))
+	}
+
+	if t.Kind() == reflect.Ptr {
+		if !d.present(name, t, opts) {
+			return nil
+		}
+		// Values encodes nil pointers as empty values
+		if d.emptyValue(name, t) {
+			sv.Set(reflect.Zero(t))
+			return nil
+		}
+		if sv.IsNil() {
+			sv.Set(reflect.New(t.Elem()))
+		}
+		return d.decodeField(sv.Elem(), name, opts)
+	}
+
+	if t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		if style, explode, ok := d.fieldStyle(opts); ok {
+			return d.decodeStyledStruct(sv, name, style, explode)
+		}
+		return d.reflectValue(sv, name)
+	}
+
+	if t.Kind() == reflect.Map && d.nestedMaps {
+		return d.decodeMap(sv, name, opts)
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if d.expandsStructs() && nestedStructType(t.Elem()) {
+			return d.decodeStructSlice(sv, name)
+		}
+		return d.decodeSlice(sv, name, opts)
+	}
+
+	vs := d.values[name]
+	if len(vs) == 0 {
+		return nil
+	}
+	if err := d.setValue(sv, vs[0], opts); err != nil {
+		return d.fieldError(name, err)
+	}
+	return nil
+}
+
+// decodeTyped populates the field value sv, of one of the types decoded
+// following their options, from the URL parameter name.  decode returns the
+// value, nil if its parameters are empty, and whether they are present.
+// Empty parameters set the field to its zero value, leaving pointers nil.
+func (d *decoder) decodeTyped(sv reflect.Value, name string, opts tagOptions, decode func(string, tagOptions) (interface{}, bool, error)) error {
+	v, ok, err := decode(name, opts)
+	switch {
+	case err != nil:
+		return d.fieldError(name, err)
+	case !ok && opts.Contains("required"):
+		return d.fieldError(name, errors.New("missing required parameter"))
+	case !ok:
+		return nil
+	case v == nil:
+		sv.Set(reflect.Zero(sv.Type()))
+	default:
+		indirectValue(sv).Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// decodeSlice populates the slice or array sv from the URL parameter name.
+func (d *decoder) decodeSlice(sv reflect.Value, name string, opts tagOptions) error {
+	strs, ok := d.sliceValues(name, opts)
+	if !ok {
+		return nil
+	}
+
+	if sv.Kind() == reflect.Array {
+		if len(strs) > sv.Len() {
+			return d.fieldError(name, fmt.Errorf("%d values do not fit in %v", len(strs), sv.Type()))
+		}
+		// Clear elements that are not set below
+		sv.Set(reflect.Zero(sv.Type()))
+	} else {
+		sv.Set(reflect.MakeSlice(sv.Type(), len(strs), len(strs)))
+	}
+
+	for i, s := range strs {
+		if err := d.setValue(sv.Index(i), s, opts); err != nil {
+			return d.fieldError(name, err)
+		}
+	}
+	return nil
+}
+
+// decodeMap populates the map sv from the URL parameters scoped under name,
+// the counterpart of encoder.mapValue.  Existing entries are kept.
+func (d *decoder) decodeMap(sv reflect.Value, name string, opts tagOptions) error {
+	t := sv.Type()
+	prefix := name + d.nestOpen
+	seen := make(map[string]bool)
+	for k := range d.values {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		key, rest := d.mapKey(k[len(prefix):])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		scoped := d.scopedName(name, key)
+
+		kv := reflect.New(t.Key()).Elem()
+		if err := d.setValue(kv, key, nil); err != nil {
+			return d.fieldError(scoped, err)
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		switch et := indirectType(t.Elem()); {
+		case nestedStructType(et):
+			if err := d.reflectValue(indirectValue(ev), scoped); err != nil {
+				return err
+			}
+		case rest != "":
+			// a nested parameter, not an entry of this map
+			continue
+		case et.Kind() == reflect.Slice:
+			if err := d.decodeSlice(indirectValue(ev), scoped, opts); err != nil {
+				return err
+			}
+		default:
+			if err := d.setValue(ev, d.values[k][0], opts); err != nil {
+				return d.fieldError(scoped, err)
+			}
+		}
+		if sv.IsNil() {
+			sv.Set(reflect.MakeMap(t))
+		}
+		sv.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+// mapKey splits the part of a parameter name following the scope of a map
+// into the key of an entry and the rest of the name.
+func (d *decoder) mapKey(s string) (key, rest string) {
+	if d.nestClose != "" {
+		if i := strings.Index(s, d.nestClose); i >= 0 {
+			return s[:i], s[i+len(d.nestClose):]
+		}
+		return s, ""
+	}
+	if i := strings.Index(s, d.nestOpen); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// indirectValue returns the value v points to, allocating nil pointers.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// decodeStructSlice populates the slice or array of structs sv, whose
+// elements are scoped under their index in the URL parameter name.  Elements
+// are decoded in order until one has no parameters.
+func (d *decoder) decodeStructSlice(sv reflect.Value, name string) error {
+	if d.structScope(name, 0) == d.structScope(name, 1) {
+		return d.decodeSharedStructSlice(sv, name)
+	}
+
+	n := 0
+	for d.hasPrefix(d.structScope(name, n) + d.nestOpen) {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	if sv.Kind() == reflect.Array {
+		if n > sv.Len() {
+			return d.fieldError(name, fmt.Errorf("%d values do not fit in %v", n, sv.Type()))
+		}
+	} else {
+		sv.Set(reflect.MakeSlice(sv.Type(), n, n))
+	}
+
+	for i := 0; i < n; i++ {
+		ev := sv.Index(i)
+		for ev.Kind() == reflect.Ptr {
+			if ev.IsNil() {
+				ev.Set(reflect.New(ev.Type().Elem()))
+			}
+			ev = ev.Elem()
+		}
+		if err := d.reflectValue(ev, d.structScope(name, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSharedStructSlice populates the slice or array of structs sv, whose
+// elements all share the same scope, as in "name[][field]".  The i-th value of
+// each parameter in that scope is decoded into the i-th element.
+func (d *decoder) decodeSharedStructSlice(sv reflect.Value, name string) error {
+	scope := d.structScope(name, 0)
+	prefix := scope + d.nestOpen
+
+	n := 0
+	for k, vs := range d.values {
+		if strings.HasPrefix(k, prefix) && len(vs) > n {
+			n = len(vs)
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	if sv.Kind() == reflect.Array {
+		if n > sv.Len() {
+			return d.fieldError(name, fmt.Errorf("%d values do not fit in %v", n, sv.Type()))
+		}
+	} else {
+		sv.Set(reflect.MakeSlice(sv.Type(), n, n))
+	}
+
+	all := d.values
+	defer func() { d.values = all }()
+	for i := 0; i < n; i++ {
+		// decode the element from the i-th values only
+		d.values = make(url.Values)
+		for k, vs := range all {
+			if strings.HasPrefix(k, prefix) && i < len(vs) {
+				d.values[k] = vs[i : i+1]
+			}
+		}
+
+		ev := sv.Index(i)
+		for ev.Kind() == reflect.Ptr {
+			if ev.IsNil() {
+				ev.Set(reflect.New(ev.Type().Elem()))
+			}
+			ev = ev.Elem()
+		}
+		if err := d.reflectValue(ev, scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sliceValues returns the strings a slice or array encoded with opts to the
+// URL parameter name is decoded from, and whether the parameter is present.
+func (d *decoder) sliceValues(name string, opts tagOptions) ([]string, bool) {
+	var del string
+	if style, explode, ok := d.fieldStyle(opts); ok {
+		if !explode && style != "deepObject" {
+			del = styleDelimiters[style]
+		}
+		vs, ok := d.values[name]
+		if !ok || del == "" {
+			return vs, ok
+		}
+		if len(vs) == 0 || vs[0] == "" {
+			return nil, true
+		}
+		return strings.Split(vs[0], del), true
+	} else if opts.Contains("comma") {
+		del = ","
+	} else if opts.Contains("space") {
+		del = " "
+	} else if opts.Contains("semicolon") {
+		del = ";"
+	} else if opts.Contains("brackets") {
+		name = name + "[]"
+	} else if d.arrayFormat == ArrayComma && !opts.Contains("numbered") {
+		del = ","
+	}
+
+	if d.arrayFormat == ArrayBrackets && del == "" && !opts.Contains("numbered") && !opts.Contains("brackets") {
+		name = d.elementScope(name, 0)
+	}
+
+	indexed := d.arrayFormat == ArrayIndices && !opts.Contains("brackets")
+	if (opts.Contains("numbered") || indexed) && del == "" {
+		var strs []string
+		for i := 0; ; i++ {
+			key := name + strconv.Itoa(i)
+			if !opts.Contains("numbered") {
+				key = d.elementScope(name, i)
+			}
+			vs := d.values[key]
+			if len(vs) == 0 {
+				return strs, i > 0
+			}
+			strs = append(strs, vs[0])
+		}
+	}
+
+	vs, ok := d.values[name]
+	if !ok || del == "" {
+		return vs, ok
+	}
+	if len(vs) == 0 || vs[0] == "" {
+		return nil, true
+	}
+	return strings.Split(vs[0], del), true
+}
+
+// present reports whether d.values holds a URL parameter for a field of type
+// t encoded with opts to the name name.
+func (d *decoder) present(name string, t reflect.Type, opts tagOptions) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(textUnmarshalerType):
+		if style, explode, ok := d.fieldStyle(opts); ok {
+			switch {
+			case style == "deepObject":
+				return d.hasPrefix(name + "[")
+			case explode:
+				// the fields are not scoped, so assume they are there
+				return true
+			}
+			_, ok := d.values[name]
+			return ok
+		}
+		return d.hasPrefix(name + d.nestOpen)
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && d.expandsStructs() && nestedStructType(t.Elem()):
+		return d.hasPrefix(d.structScope(name, 0) + d.nestOpen)
+	case t.Kind() == reflect.Map && d.nestedMaps:
+		return d.hasPrefix(name + d.nestOpen)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		_, ok := d.sliceValues(name, opts)
+		return ok
+	}
+	_, ok := d.values[name]
+	return ok
+}
+
+// emptyValue reports whether the URL parameter name of a pointer field of
+// type t holds the empty value Values encodes a nil pointer as.  Pointers to
+// strings are not concerned, as the empty value is a valid string.
+func (d *decoder) emptyValue(name string, t reflect.Type) bool {
+	t = indirectType(t)
+	switch {
+	case t.Kind() == reflect.String,
+		t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(textUnmarshalerType),
+		t.Kind() == reflect.Slice, t.Kind() == reflect.Array, t.Kind() == reflect.Map:
+		return false
+	}
+	vs := d.values[name]
+	return len(vs) > 0 && vs[0] == ""
+}
+
+// hasPrefix reports whether any URL parameter name in d.values starts with
+// prefix.
+func (d *decoder) hasPrefix(prefix string) bool {
+	for k := range d.values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setValue sets v to the value represented by s.  It is the inverse of
+// valueString.
+func (c *config) setValue(v reflect.Value, s string, opts tagOptions) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return c.setValue(v.Elem(), s, opts)
+	}
+
+	if v.Type() == timeType {
+		if opts.Contains("unix") {
+			sec, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(time.Unix(sec, 0).UTC()))
+			return nil
+		}
+		layout := c.timeLayout
+		if c.dateOnly || opts.Contains("dateonly") {
+			layout = dateLayout
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if isGeoType(v.Type()) {
+		g, err := parseGeo(v.Type(), s, opts)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(g))
+		return nil
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			// also accept a number of nanoseconds
+			n, nerr := strconv.ParseInt(s, 10, 64)
+			if nerr != nil {
+				return err
+			}
+			d = time.Duration(n)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if opts.Contains("int") {
+			switch s {
+			case "1":
+				v.SetBool(true)
+			case "0":
+				v.SetBool(false)
+			default:
+				return fmt.Errorf("invalid boolean %q", s)
+			}
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if c.decimalSeparator != "" {
+			s = strings.Replace(s, c.decimalSeparator, ".", 1)
+		}
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("cannot decode into %v", v.Type())
+		}
+		v.Set(reflect.ValueOf(s))
+	default:
+		return fmt.Errorf("cannot decode into %v", v.Type())
+	}
+	return nil
+}
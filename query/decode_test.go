@@ -0,0 +1,185 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (m *EncodedArgs) DecodeValues(key string, v url.Values) error {
+	for i := 0; ; i++ {
+		vs, ok := v[fmt.Sprintf("%s.%d", key, i)]
+		if !ok {
+			return nil
+		}
+		*m = append(*m, vs[0])
+	}
+}
+
+type roundTrip struct {
+	A string
+	B int        `url:"b"`
+	C uint8      `url:"c,omitempty"`
+	D float64    `url:"d"`
+	E bool       `url:"e,int"`
+	F *string    `url:"f"`
+	G []string   `url:"g,comma"`
+	H []int      `url:"h,brackets"`
+	I [2]string  `url:"i,numbered"`
+	J []bool     `url:"j,space,int"`
+	K time.Time  `url:"k"`
+	L time.Time  `url:"l,unix"`
+	M Nested     `url:"m"`
+	N *SubNested `url:"n"`
+	O EncodedArgs
+	B2
+}
+
+type B2 struct {
+	P string `url:"p"`
+}
+
+func TestDecode_roundTrip(t *testing.T) {
+	str := "string"
+	in := roundTrip{
+		A:  "a b&c",
+		B:  -1,
+		C:  2,
+		D:  1.5,
+		E:  true,
+		F:  &str,
+		G:  []string{"x", "y"},
+		H:  []int{1, 2},
+		I:  [2]string{"p", "q"},
+		J:  []bool{true, false},
+		K:  time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC),
+		L:  time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC),
+		M:  Nested{A: SubNested{"a"}, B: &SubNested{"b"}},
+		N:  &SubNested{"n"},
+		O:  EncodedArgs{"o1", "o2"},
+		B2: B2{P: "p"},
+	}
+
+	values, err := Values(in)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	var out roundTrip
+	if err := Decode(values, &out); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", values, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded\n%+v, want\n%+v", values, out, in)
+	}
+}
+
+func TestDecode_nilPointers(t *testing.T) {
+	type s struct {
+		I *int       `url:"i"`
+		T *time.Time `url:"t"`
+		F *float64   `url:"f,omitempty"`
+	}
+	in := s{}
+	values, err := Values(in)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	one := 1
+	out := s{I: &one}
+	if err := Decode(values, &out); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", values, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", values, out, in)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type s struct {
+		A string    `url:"a"`
+		B *int      `url:"b"`
+		C []string  `url:"c"`
+		D *[]string `url:"d,comma"`
+		E *Nested   `url:"e"`
+		F map[string]int
+	}
+
+	two := 2
+	tests := []struct {
+		in   string
+		want s
+	}{
+		{"", s{A: "keep"}},
+		{"a=x&a=y", s{A: "x"}},
+		{"b=2", s{A: "keep", B: &two}},
+		{"b=", s{A: "keep"}},
+		{"c=x&c=y", s{A: "keep", C: []string{"x", "y"}}},
+		{"d=x,y", s{A: "keep", D: &[]string{"x", "y"}}},
+		{"d=", s{A: "keep", D: &[]string{}}},
+		{"e[b][value]=v", s{A: "keep", E: &Nested{B: &SubNested{"v"}}}},
+		{"F[x]=1&F[y]=2&F[z][w]=3", s{A: "keep", F: map[string]int{"x": 1, "y": 2}}},
+	}
+
+	for i, tt := range tests {
+		values, _ := url.ParseQuery(tt.in)
+		got := s{A: "keep"}
+		if err := Decode(values, &got, WithNestedMaps()); err != nil {
+			t.Errorf("%d. Decode(%q) returned error: %v", i, tt.in, err)
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%d. Decode(%q) decoded %+v, want %+v", i, tt.in, got, tt.want)
+		}
+	}
+
+	// without WithNestedMaps, nested parameters are not read into maps
+	values, _ := url.ParseQuery("F[x]=1")
+	got := s{A: "keep"}
+	if err := Decode(values, &got); err != nil {
+		t.Errorf("Decode(%q) returned error: %v", "F[x]=1", err)
+	}
+	if got.F != nil {
+		t.Errorf("Decode(%q) decoded F %v, want nil", "F[x]=1", got.F)
+	}
+}
+
+func TestDecode_errors(t *testing.T) {
+	var s struct {
+		A int     `url:"a"`
+		B bool    `url:"b,int"`
+		C [1]int  `url:"c"`
+		D float64 `url:"d"`
+	}
+	values := url.Values{"a": {"x"}, "b": {"2"}, "c": {"1", "2"}, "d": {"1.5"}}
+
+	err := Decode(values, &s)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Name != "a" {
+		t.Errorf("Decode(%v) returned error %v, want error for a", values, err)
+	}
+
+	err = Decode(values, &s, WithCollectErrors())
+	var names []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		names = append(names, err.(*FieldError).Name)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(want, names) {
+		t.Errorf("Decode(%v) returned errors for %v, want %v", values, names, want)
+	}
+	if s.D != 1.5 {
+		t.Errorf("Decode(%v) did not continue past errors: D = %v", values, s.D)
+	}
+
+	for _, v := range []interface{}{nil, s, (*struct{})(nil), new(string)} {
+		if err := Decode(values, v); err == nil || !strings.Contains(err.Error(), "expects a non-nil struct pointer") {
+			t.Errorf("Decode(%T) returned error %v", v, err)
+		}
+	}
+}
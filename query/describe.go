@@ -0,0 +1,116 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A Schema describes the URL parameters produced by a struct type, as
+// returned by Describe.
+type Schema struct {
+	Type   reflect.Type
+	Params []ParamSchema
+}
+
+// A ParamSchema describes a URL parameter.
+type ParamSchema struct {
+	// Name is the name of the parameter, as produced by Values.
+	Name string
+
+	// Field is the path of the field producing the parameter in Go, as in
+	// "ListOptions.Filter.State", and GoType its type.
+	Field  string
+	GoType reflect.Type
+
+	// Type and Format describe the individual values of the parameter in
+	// the terms of JSON Schema: Type is "string", "integer", "number",
+	// "boolean", or "object" for fields encoded by an OpenAPI style, and
+	// Format is empty or one of the formats of OpenAPI, such as "int64" or
+	// "date-time".
+	Type   string
+	Format string
+
+	// Repeated reports whether the parameter may be given several times,
+	// and Delimiter the separator of the values of slices encoded as a
+	// single value, such as ",".
+	Repeated  bool
+	Delimiter string
+
+	// Required, Enum and Default reflect the "required" and "default" tag
+	// options and the "enum" tag of the field.
+	Required bool
+	Enum     []string
+	Default  string
+}
+
+// Describe returns the schema of the URL parameters encoded by Values with
+// opts from the struct type of v, which may be a struct or a (possibly nil)
+// pointer to one, so that documentation generators and gateways can work from
+// the same structs as clients.  Fields of nested structs are described
+// individually, in the order Values encodes them.  See OpenAPIParams for
+// OpenAPI parameter definitions.
+func Describe(v interface{}, opts ...Option) (Schema, error) {
+	t, err := structType(v)
+	if err != nil {
+		return Schema{}, fmt.Errorf("query: Describe() %v", err)
+	}
+	s := Schema{Type: t}
+	newConfig(opts).describe(&s.Params, t, t.Name(), "", make(map[reflect.Type]bool))
+	return s, nil
+}
+
+// describe appends the schemas of the parameters of the fields of the struct
+// type t, named path in Go and scope in the URL, to params.
+func (c *config) describe(params *[]ParamSchema, t reflect.Type, path, scope string, visiting map[reflect.Type]bool) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	c.walkFields(t, func(f typeField) {
+		p := ParamSchema{
+			Name:     c.scopedName(scope, f.name),
+			Field:    path + "." + goPath(t, f.index),
+			GoType:   f.sf.Type,
+			Required: f.opts.Contains("required"),
+		}
+		p.Default, _ = f.opts.Value("default")
+		if enum := f.sf.Tag.Get("enum"); enum != "" {
+			p.Enum = strings.Split(enum, ",")
+		}
+
+		_, _, styled := c.fieldStyle(f.opts)
+		if !styled && nestedStructType(f.sf.Type) {
+			c.describe(params, indirectType(f.sf.Type), p.Field, p.Name, visiting)
+			return
+		}
+
+		s := c.schemaFor(f.sf.Type, f.opts, "", nil)
+		if s.Type == "array" && !styled {
+			switch {
+			case f.opts.Contains("comma"):
+				p.Delimiter = ","
+			case f.opts.Contains("space"):
+				p.Delimiter = " "
+			case f.opts.Contains("semicolon"):
+				p.Delimiter = ";"
+			case c.arrayFormat == ArrayComma && !f.opts.Contains("numbered"):
+				p.Delimiter = ","
+			default:
+				p.Repeated = true
+			}
+			if f.opts.Contains("brackets") {
+				p.Name += "[]"
+			}
+			s = s.Items
+		}
+		p.Type, p.Format = s.Type, s.Format
+		*params = append(*params, p)
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	type filter struct {
+		State string `url:"state,default=open" enum:"open,closed"`
+	}
+	type options struct {
+		Q      string      `url:"q,required"`
+		Page   int         `url:"page,omitempty"`
+		Since  time.Time   `url:"since"`
+		Tags   []string    `url:"tags,comma"`
+		IDs    []int64     `url:"ids,brackets"`
+		Filter filter      `url:"filter"`
+		Range  filter      `url:"range,style=form"`
+		Next   *recursive  `url:"next"`
+		Skip   interface{} `url:"-"`
+	}
+
+	got, err := Describe((*options)(nil))
+	if err != nil {
+		t.Fatalf("Describe returned error: %v", err)
+	}
+	typ := reflect.TypeOf(options{})
+	want := Schema{Type: typ, Params: []ParamSchema{
+		{Name: "q", Field: "options.Q", GoType: reflect.TypeOf(""), Type: "string", Required: true},
+		{Name: "page", Field: "options.Page", GoType: reflect.TypeOf(0), Type: "integer", Format: "int64"},
+		{Name: "since", Field: "options.Since", GoType: timeType, Type: "string", Format: "date-time"},
+		{Name: "tags", Field: "options.Tags", GoType: reflect.TypeOf([]string{}), Type: "string", Delimiter: ","},
+		{Name: "ids[]", Field: "options.IDs", GoType: reflect.TypeOf([]int64{}), Type: "integer", Format: "int64", Repeated: true},
+		{Name: "filter[state]", Field: "options.Filter.State", GoType: reflect.TypeOf(""), Type: "string", Enum: []string{"open", "closed"}, Default: "open"},
+		{Name: "range", Field: "options.Range", GoType: reflect.TypeOf(filter{}), Type: "object"},
+		{Name: "next[name]", Field: "options.Next.Name", GoType: reflect.TypeOf(""), Type: "string"},
+	}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Describe returned\n%+v, want\n%+v", got, want)
+	}
+
+	if _, err := Describe(""); err == nil {
+		t.Errorf("expected Describe() to return an error on invalid input")
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// Diff encodes old and new, which must be structs of the same type or
+// pointers to them, and returns only the parameters whose values differ, as
+// needed for minimal PATCH or update requests.  A parameter of new is
+// returned with all its values if they differ from those of old, in value or
+// in order.  A parameter of old that new no longer produces, such as an
+// omitempty field that was cleared, is returned with a single empty value.
+// A nil old pointer is taken as a struct producing no parameters.
+func Diff(old, new interface{}, opts ...Option) (url.Values, error) {
+	ot, err := structType(old)
+	if err != nil {
+		return nil, fmt.Errorf("query: Diff() %v", err)
+	}
+	nt, err := structType(new)
+	if err != nil {
+		return nil, fmt.Errorf("query: Diff() %v", err)
+	}
+	if ot != nt {
+		return nil, fmt.Errorf("query: Diff() expects values of the same type. Got %v and %v", ot, nt)
+	}
+
+	ov, err := Values(old, opts...)
+	if err != nil {
+		return nil, err
+	}
+	nv, err := Values(new, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(url.Values)
+	for k, vs := range nv {
+		if !reflect.DeepEqual(vs, ov[k]) {
+			diff[k] = vs
+		}
+	}
+	for k := range ov {
+		if _, ok := nv[k]; !ok {
+			diff[k] = []string{""}
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type update struct {
+		Name  string   `url:"name"`
+		Note  string   `url:"note,omitempty"`
+		Tags  []string `url:"tag"`
+		Count int      `url:"count"`
+	}
+	tests := []struct {
+		old, new interface{}
+		want     url.Values
+	}{
+		{update{Name: "a"}, update{Name: "a"}, url.Values{}},
+		{update{Name: "a", Count: 1}, &update{Name: "b", Count: 1}, url.Values{"name": {"b"}}},
+		{update{Note: "x"}, update{}, url.Values{"note": {""}}},
+		{update{Tags: []string{"x", "y"}}, update{Tags: []string{"y", "x"}}, url.Values{"tag": {"y", "x"}}},
+		{(*update)(nil), update{Note: "x"}, url.Values{"name": {""}, "note": {"x"}, "count": {"0"}}},
+	}
+	for i, tt := range tests {
+		got, err := Diff(tt.old, tt.new)
+		if err != nil {
+			t.Errorf("%d. Diff(%v, %v) returned error: %v", i, tt.old, tt.new, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Diff(%v, %v) returned %v, want %v", i, tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	type a struct{ A string }
+	type b struct{ A string }
+	tests := []struct {
+		old, new interface{}
+	}{
+		{a{}, b{}},
+		{a{}, "a"},
+		{nil, a{}},
+	}
+	for i, tt := range tests {
+		if _, err := Diff(tt.old, tt.new); err == nil {
+			t.Errorf("%d. Diff(%v, %v) did not return an error", i, tt.old, tt.new)
+		}
+	}
+}
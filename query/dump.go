@@ -0,0 +1,96 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dump returns a listing of the URL parameters encoded by Values from v with
+// opts, meant for bug reports and debugging.  Each line holds a parameter and
+// its quoted value, followed by the Go type of the field producing it and the
+// options of its tag, with the columns aligned:
+//
+//	q      = "go"  string
+//	tag    = "a"   []string  omitempty
+//	tag    = "b"   []string  omitempty
+//	page   = "2"   int
+//
+// Unlike the formatting of url.Values, which follows the order of map
+// iteration, the listing is deterministic: parameters are listed in the order
+// their fields are encoded, so that dumps may be compared with diff.  The
+// values of redacted fields are replaced by Redacted, and encoding errors are
+// reported on a final line starting with "error:".
+func Dump(v interface{}, opts ...Option) string {
+	var tr Trace
+	e := &encoder{config: newConfig(opts)}
+	e.trace = &tr
+	_, err := e.encode(v)
+
+	t := reflect.TypeOf(v)
+	if t != nil {
+		t = indirectType(t)
+	}
+
+	// rows holds the cells of each line: key, value, type and options
+	var rows [][4]string
+	for _, s := range tr.Steps {
+		typ, opts := "", ""
+		if sf, ok := fieldByPath(t, s.Field); ok {
+			typ = sf.Type.String()
+			_, o := parseTag(e.fieldTag(sf))
+			opts = strings.Join(o, ",")
+		}
+		for _, p := range s.Written {
+			rows = append(rows, [4]string{p.Key, "= " + strconv.Quote(p.Value), typ, opts})
+		}
+	}
+
+	var widths [4]int
+	for _, r := range rows {
+		for i, cell := range r {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		line := ""
+		for i, cell := range r {
+			if i > 0 {
+				line += "  "
+			}
+			line += fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "error: %v\n", err)
+	}
+	return b.String()
+}
+
+// fieldByPath returns the field of the struct type t at path, a list of
+// field names separated by dots as in TraceStep.Field.
+func fieldByPath(t reflect.Type, path string) (sf reflect.StructField, ok bool) {
+	for _, name := range strings.Split(path, ".") {
+		if t == nil || t.Kind() != reflect.Struct {
+			return sf, false
+		}
+		if sf, ok = fieldByName(t, name); !ok {
+			return sf, false
+		}
+		t = indirectType(sf.Type)
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = indirectType(t.Elem())
+		}
+	}
+	return sf, ok
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	type Filter struct {
+		State string `url:"state"`
+	}
+	type Options struct {
+		Q      string   `url:"q"`
+		Tags   []string `url:"tag,omitempty"`
+		Filter Filter   `url:"filter"`
+		Page   int      `url:"page"`
+		Token  string   `url:"token,redact"`
+		Empty  string   `url:"empty,omitempty"`
+	}
+
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{
+			Options{Q: "go lang", Tags: []string{"a", "b"}, Filter: Filter{"open"}, Page: 2, Token: "s3cret"},
+			"q              = \"go lang\"     string\n" +
+				"tag            = \"a\"           []string  omitempty\n" +
+				"tag            = \"b\"           []string  omitempty\n" +
+				"filter[state]  = \"open\"        string\n" +
+				"page           = \"2\"           int\n" +
+				"token          = \"[REDACTED]\"  string    redact\n",
+		},
+		{(*Options)(nil), ""},
+		{0, "error: query: Values() expects struct input. Got int\n"},
+	}
+
+	for i, tt := range tests {
+		if got := Dump(tt.in); got != tt.want {
+			t.Errorf("%d. Dump(%v) returned:\n%s\nwant:\n%s", i, tt.in, got, tt.want)
+		}
+	}
+
+	// Dumps are deterministic
+	in := struct {
+		M map[string]int `url:"m"`
+	}{map[string]int{"c": 3, "a": 1, "b": 2}}
+	want := Dump(in)
+	for i := 0; i < 10; i++ {
+		if got := Dump(in); got != want {
+			t.Fatalf("Dump(%v) returned:\n%s\nthen:\n%s", in, want, got)
+		}
+	}
+}
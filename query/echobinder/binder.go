@@ -0,0 +1,53 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package echobinder provides an Echo binder backed by the query package, so
+// that the structs used to encode client requests can also bind incoming
+// requests on the server, using the same url tags.
+//
+// To use it for all requests of an Echo instance:
+//
+//	e := echo.New()
+//	e.Binder = echobinder.New()
+package echobinder
+
+import (
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+	"github.com/labstack/echo/v4"
+)
+
+// Binder implements the echo.Binder interface using query.Decode.
+type Binder struct {
+	// Options are passed to query.Decode.
+	Options []query.Option
+}
+
+// New returns a Binder that decodes using opts.
+func New(opts ...query.Option) *Binder {
+	return &Binder{Options: opts}
+}
+
+// Bind decodes the parameters of the request of c into i, which must be a
+// pointer to a struct.  For GET, HEAD and DELETE requests only the URL query
+// is used; for other requests the form values, which include the URL query
+// and any application/x-www-form-urlencoded body, are used.  Failures are
+// reported as *echo.HTTPError with status 400 Bad Request.
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	req := c.Request()
+	values := req.URL.Query()
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+	default:
+		if err := req.ParseForm(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+		values = req.Form
+	}
+	if err := query.Decode(values, i, b.Options...); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
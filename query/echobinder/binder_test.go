@@ -0,0 +1,54 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package echobinder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type options struct {
+	Query string `url:"q"`
+	Page  int    `url:"page"`
+}
+
+func TestBinder(t *testing.T) {
+	e := echo.New()
+	e.Binder = New()
+
+	post := httptest.NewRequest("POST", "/?q=foo", strings.NewReader("page=3"))
+	post.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	tests := []struct {
+		req  *http.Request
+		want options
+	}{
+		{httptest.NewRequest("GET", "/?q=foo&page=2", nil), options{"foo", 2}},
+		{post, options{"foo", 3}},
+	}
+
+	for i, tt := range tests {
+		c := e.NewContext(tt.req, httptest.NewRecorder())
+		var got options
+		if err := c.Bind(&got); err != nil {
+			t.Errorf("%d. Bind() returned error: %v", i, err)
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%d. Bind() bound %+v, want %+v", i, got, tt.want)
+		}
+	}
+
+	c := e.NewContext(httptest.NewRequest("GET", "/?page=x", nil), httptest.NewRecorder())
+	var got options
+	err := c.Bind(&got)
+	if he, ok := err.(*echo.HTTPError); !ok || he.Code != http.StatusBadRequest {
+		t.Errorf("Bind() returned error %v, want 400 *echo.HTTPError", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package elastic provides option structs for the URI search API of
+// Elasticsearch, to be encoded by the query package:
+//
+//	opt := elastic.SearchOptions{
+//		Q:      "user:kimchy",
+//		Sort:   []elastic.Sort{elastic.Desc("date"), elastic.Asc("_score")},
+//		Source: []string{"title", "date"},
+//		Scroll: elastic.Duration(time.Minute),
+//	}
+//	v, _ := query.Values(opt)
+//	// q: user:kimchy, sort: date:desc,_score:asc, _source: title,date, scroll: 1m
+package elastic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchOptions holds the query parameters of a URI search request, as in
+// GET /index/_search?q=....  Zero fields are left out.
+type SearchOptions struct {
+	Q               string   `url:"q,omitempty"`
+	DF              string   `url:"df,omitempty"`
+	Analyzer        string   `url:"analyzer,omitempty"`
+	DefaultOperator string   `url:"default_operator,omitempty"` // "AND" or "OR"
+	Lenient         bool     `url:"lenient,omitempty"`
+	Sort            []Sort   `url:"sort,comma,omitempty"`
+	Source          []string `url:"_source,comma,omitempty"`
+	SourceIncludes  []string `url:"_source_includes,comma,omitempty"`
+	SourceExcludes  []string `url:"_source_excludes,comma,omitempty"`
+	StoredFields    []string `url:"stored_fields,comma,omitempty"`
+	From            int      `url:"from,omitempty"`
+	Size            *int     `url:"size,omitempty"`
+	TrackTotalHits  *bool    `url:"track_total_hits,omitempty"`
+	TerminateAfter  int      `url:"terminate_after,omitempty"`
+	Timeout         Duration `url:"timeout,omitempty"`
+	Routing         []string `url:"routing,comma,omitempty"`
+	Preference      string   `url:"preference,omitempty"`
+	Scroll          Duration `url:"scroll,omitempty"`
+}
+
+// ScrollOptions holds the query parameters of a request for the next batch of
+// a scroll, as in GET /_search/scroll?scroll=1m&scroll_id=....
+type ScrollOptions struct {
+	Scroll   Duration `url:"scroll,omitempty"`
+	ScrollID string   `url:"scroll_id"`
+}
+
+// A Sort is an item of the sort parameter, written "field:order".
+type Sort struct {
+	Field string
+	Order string // "asc", "desc", or empty for the default order
+}
+
+// Asc sorts by field in ascending order.
+func Asc(field string) Sort {
+	return Sort{field, "asc"}
+}
+
+// Desc sorts by field in descending order.
+func Desc(field string) Sort {
+	return Sort{field, "desc"}
+}
+
+// String returns s as written in the sort parameter.
+func (s Sort) String() string {
+	if s.Order == "" {
+		return s.Field
+	}
+	return s.Field + ":" + s.Order
+}
+
+// UnmarshalText parses s from its form in the sort parameter.
+func (s *Sort) UnmarshalText(text []byte) error {
+	field, order, _ := strings.Cut(string(text), ":")
+	*s = Sort{field, order}
+	return nil
+}
+
+// A Duration is a time.Duration written in the time units of Elasticsearch,
+// such as "1m" or "500ms", which do not accept the compound form used by
+// time.Duration, such as "1m30s".
+type Duration time.Duration
+
+// durationUnits lists the time units of Elasticsearch, from largest to
+// smallest.
+var durationUnits = []struct {
+	name string
+	d    time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"micros", time.Microsecond},
+	{"nanos", time.Nanosecond},
+}
+
+// String returns d in the largest unit it is a whole number of.
+func (d Duration) String() string {
+	for _, u := range durationUnits {
+		if time.Duration(d)%u.d == 0 {
+			return strconv.FormatInt(int64(time.Duration(d)/u.d), 10) + u.name
+		}
+	}
+	panic("unreachable")
+}
+
+// UnmarshalText parses a duration written in a unit of Elasticsearch.
+func (d *Duration) UnmarshalText(text []byte) error {
+	s := string(text)
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return fmt.Errorf("elastic: invalid duration %q", s)
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil {
+		return fmt.Errorf("elastic: invalid duration %q", s)
+	}
+	for _, u := range durationUnits {
+		if s[i:] == u.name {
+			*d = Duration(time.Duration(n) * u.d)
+			return nil
+		}
+	}
+	return fmt.Errorf("elastic: invalid duration unit in %q", s)
+}
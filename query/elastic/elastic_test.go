@@ -0,0 +1,80 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package elastic
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestSearchOptions(t *testing.T) {
+	size := 0
+	in := SearchOptions{
+		Q:       "user:kimchy",
+		Sort:    []Sort{Desc("date"), Asc("_score"), {Field: "name"}},
+		Source:  []string{"title", "date"},
+		Size:    &size,
+		Timeout: Duration(1500 * time.Millisecond),
+		Scroll:  Duration(time.Minute),
+	}
+
+	v, err := query.Values(in)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{
+		"q":       {"user:kimchy"},
+		"sort":    {"date:desc,_score:asc,name"},
+		"_source": {"title,date"},
+		"size":    {"0"},
+		"timeout": {"1500ms"},
+		"scroll":  {"1m"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	var out SearchOptions
+	if err := query.Decode(v, &out); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0d"},
+		{48 * time.Hour, "2d"},
+		{90 * time.Minute, "90m"},
+		{30 * time.Second, "30s"},
+		{time.Microsecond, "1micros"},
+		{1500 * time.Nanosecond, "1500nanos"},
+	}
+	for _, tt := range tests {
+		if got := Duration(tt.d).String(); got != tt.want {
+			t.Errorf("Duration(%v).String() returned %q, want %q", tt.d, got, tt.want)
+		}
+		var d Duration
+		if err := d.UnmarshalText([]byte(tt.want)); err != nil || time.Duration(d) != tt.d {
+			t.Errorf("UnmarshalText(%q) returned %v, %v, want %v", tt.want, time.Duration(d), err, tt.d)
+		}
+	}
+
+	for _, s := range []string{"", "m", "1", "1w", "-1s"} {
+		var d Duration
+		if err := d.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("UnmarshalText(%q) returned no error", s)
+		}
+	}
+}
@@ -2,7 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package query implements encoding of structs into URL query parameters.
+// Package query implements encoding of structs into URL query parameters,
+// and decoding of URL query parameters back into structs.
 //
 // As a simple example:
 //
@@ -22,12 +23,12 @@ package query
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"net/url"
-	"path"
 	"reflect"
-	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +38,17 @@ var timeType = reflect.TypeOf(time.Time{})
 
 var encoderType = reflect.TypeOf(new(Encoder)).Elem()
 
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var stringerType = reflect.TypeOf(new(fmt.Stringer)).Elem()
+
+// zeroer is implemented by types that can report whether they hold their
+// zero value, such as time.Time.  It is used to decide emptiness for the
+// "omitempty" option.
+type zeroer interface {
+	IsZero() bool
+}
+
 // Encoder is an interface implemented by any type that wishes to encode
 // itself into URL values in a non-standard way.
 type Encoder interface {
@@ -54,8 +66,9 @@ type Encoder interface {
 //	- the field is empty and its tag specifies the "omitempty" option
 //
 // The empty values are false, 0, any nil pointer or interface value, any array
-// slice, map, or string of length zero, and any time.Time that returns true
-// for IsZero().
+// slice, map, or string of length zero, and any value (or non-nil pointer to
+// a value) with an IsZero() bool method that returns true, such as a zero
+// time.Time.
 //
 // The URL parameter name defaults to the struct field name but can be
 // specified in the struct field's tag value.  The "url" key in the struct
@@ -85,7 +98,9 @@ type Encoder interface {
 //
 // time.Time values default to encoding as RFC3339 timestamps.  Including the
 // "unix" option signals that the field should be encoded as a Unix time (see
-// time.Unix())
+// time.Unix())  The "utc" option converts times to UTC before formatting
+// them, and the "tz" option to the named location, as in "tz=Europe/Paris".
+// The "dateonly" option encodes the date alone, as in "2006-01-02".
 //
 // Slice and Array values default to encoding as multiple URL values of the
 // same name.  Including the "comma" option signals that the field should be
@@ -97,10 +112,15 @@ type Encoder interface {
 // the end of each incidence of the value name, example:
 // name0=value0&name1=value1, etc.
 //
-// Anonymous struct fields are usually encoded as if their inner exported
-// fields were fields in the outer struct, subject to the standard Go
-// visibility rules.  An anonymous struct field with a name given in its URL
-// tag is treated as having that name, rather than being anonymous.
+// Anonymous struct fields (and anonymous pointers to structs) are usually
+// encoded as if their inner exported fields were fields in the outer struct,
+// subject to the standard Go visibility rules.  Nil anonymous struct pointers
+// are skipped.  An anonymous struct field with a name given in its URL tag is
+// treated as having that name, rather than being anonymous, so its fields are
+// scoped under that name like those of any other nested struct.  Passing
+// WithEmbeddedNaming(EmbeddedFlatten) flattens such fields as well.  An
+// anonymous struct field whose type implements Encoder is encoded by its
+// EncodeValues method, the same as a named field of that type.
 //
 // Non-nil pointer values are encoded as the value pointed to.
 //
@@ -109,29 +129,87 @@ type Encoder interface {
 //
 // 	"user[name]=acme&user[addr][postcode]=1234&user[addr][city]=SFO"
 //
+// Maps are encoded using their default string representation, unless
+// WithNestedMaps or WithStripe is given, in which case they are encoded like
+// nested structs, with one URL parameter per entry named after its key, as in
+// "metadata[color]=red&metadata[size]=L".
+//
+// The "style" option, as in "style=pipeDelimited", encodes a slice or nested
+// struct following an OpenAPI 3 serialization style instead: "form",
+// "spaceDelimited", "pipeDelimited" or "deepObject".  The "explode" and
+// "explode=false" options set whether the value is exploded, which defaults to
+// true for "form" and "deepObject" and false otherwise.  For example, with the
+// tag `url:"id,style=form,explode=false"` a struct is encoded as
+// "id=role,admin,firstName,Alex".  WithOpenAPIStyle sets a style for all
+// other fields.
+//
+// Fields with a "path", "header" or "cookie" tag but no "url" tag describe
+// other parts of a request and are skipped by Values; see ExpandPath, Headers
+// and Cookies.
+//
+// Fields with the "file" option are skipped by Values.  They are meant for
+// multipart forms written by WriteMultipart.
+//
+// Fields with "min", "max" or "len" options, or a "pattern" tag, are checked
+// against these constraints before being encoded; see ValidationError.
+//
+// Float values that are NaN or infinite encode as "NaN", "+Inf" or "-Inf"
+// unless another policy is chosen with WithNonFiniteFloats.
+//
 // All other values are encoded using their default string representation.
 //
 // Multiple fields that encode to the same URL parameter name will be included
-// as multiple URL values of the same name.
+// as multiple URL values of the same name.  By default the fields of embedded
+// structs are encoded after the other fields of the embedding struct; use
+// WithEmbeddedOrder to encode them in declaration order instead, or
+// WithCollisionCheck to treat such fields as an error.
+//
+// The encoding rules may be adjusted by passing Options.
+//
+// A panic while encoding a field, such as one raised by a custom Encoder, is
+// recovered and returned as an error naming the path of the offending field.
+func Values(v interface{}, opts ...Option) (url.Values, error) {
+	e := &encoder{config: newConfig(opts)}
+	return e.encode(v)
+}
 
+// encode implements Values, reporting to the Observer in effect, if any.
+func (e *encoder) encode(v interface{}) (url.Values, error) {
+	o := e.currentObserver()
+	if o == nil {
+		return e.encodeValue(v)
+	}
+	start := time.Now()
+	values, err := e.encodeValue(v)
+	o.Observe(Stats{
+		Op:       OpEncode,
+		Type:     reflect.TypeOf(v),
+		Duration: time.Since(start),
+		Params:   countPairs(values),
+		Err:      err,
+	})
+	return values, err
+}
+
+// encodeValue implements encode.
 // v is generally a struct or pointer-to-struct
 // Return empty values if nil-pointer or a nil value
 // Return error if v is neither struct nor ptr-to-struct
-func Values(v interface{}) (url.Values, error) {
-	logit("\n\nv", v)
+func (e *encoder) encodeValue(v interface{}) (_ url.Values, err error) {
+	e.logit("\n\nv", v)
 
 	// url.Values is a map[string] []string
 	values := make(url.Values)
 
 	// Set val to the interfaces Value
 	val := reflect.ValueOf(v)
-	logit("val", val)
+	e.logit("val", val)
 
 	// Update val to remove 'Pointieness' (dereference the pointer)
 	for val.Kind() == reflect.Ptr {
 		// Return if nil pointer
 		if val.IsNil() {
-			logit("val is a nil pointer = ", true)
+			e.logit("val is a nil pointer = ", true)
 			return values, nil
 		}
 		// Dereference the pointer
@@ -140,117 +218,542 @@ func Values(v interface{}) (url.Values, error) {
 
 	// Return if nil value
 	if v == nil {
-		logit("val is a nil value = ", true)
+		e.logit("val is a nil value = ", true)
 		return values, nil
 	}
 
-	logit("val", val)
+	e.logit("val", val)
 	// Return if non-struct value
 	if val.Kind() != reflect.Struct {
-		logit("val is not a struct = ", true)
+		e.logit("val is not a struct = ", true)
 		return nil, fmt.Errorf("query: Values() expects struct input. Got %v", val.Kind())
 	}
 
+	if e.validator != nil {
+		if err := e.validateValue(v, val.Type()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Report unexpected panics, whether from reflection or a custom
+	// Encoder, as errors naming the field being encoded.  Panics of the
+	// loop body ranging over All are left to reach the caller.
+	defer func() {
+		if r := recover(); r != nil {
+			if e.yielding {
+				panic(r)
+			}
+			err = fmt.Errorf("query: panic encoding field %s.%s: %v", val.Type(), strings.Join(e.path, "."), r)
+		}
+	}()
+
 	// Populate values with tag name and values
 	// maps (values) are modifiable by the called function
-	err := reflectValue(values, val, "")
-	logit("values", values)
-	logit("--------", "--------")
+	err = e.reflectValue(values, val, "")
+	if err == nil && len(e.errs) > 0 {
+		err = errors.Join(e.errs...)
+	}
+	if len(e.overrides) > 0 {
+		e.applyOverrides(values)
+	}
+	if e.dedupe {
+		dedupeValues(values)
+	}
+	if err == nil && len(e.reserved) > 0 {
+		err = e.checkReserved(values)
+	}
+	if err == nil && (e.maxParams > 0 || e.maxRepeats > 0) {
+		err = e.checkCounts(values)
+	}
+	if err == nil && e.maxLength > 0 {
+		err = e.checkLength(values)
+	}
+	e.logit("values", values)
+	e.logit("--------", "--------")
 	return values, err
 }
 
+// FieldError describes a failure to encode or decode a single struct field.
+// When the WithCollectErrors option is used, Values and Decode return the
+// FieldErrors for all failing fields joined with errors.Join.
+type FieldError struct {
+	Name string // URL parameter name of the field
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return "query: parameter " + strconv.Quote(e.Name) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// encoder holds the state of a single call to Values.
+type encoder struct {
+	*config
+
+	// errs holds the field errors collected so far when collectErrors is
+	// set.
+	errs []error
+
+	// path holds the Go field names leading to the field being encoded.
+	path []string
+
+	// names maps the URL parameter names encoded so far to the path of the
+	// field that produced them, when checkCollisions is set.
+	names map[string]string
+
+	// files collects the fields with the "file" option when collectFiles
+	// is set.  Otherwise such fields are skipped.
+	collectFiles bool
+	files        []filePart
+
+	// keys holds the URL parameter names in the order they were first
+	// added, for the functions producing ordered output.
+	keys []string
+	seen map[string]bool
+
+	// redacting is set while encoding a redacted field, and redactedKeys
+	// holds the URL parameters written by such fields.
+	redacting    bool
+	redactedKeys map[string]bool
+
+	// included is set while encoding a field selected by Only, whose
+	// nested fields are then all encoded.
+	included bool
+
+	// yield receives each parameter value as it is encoded, if not nil,
+	// until it returns false, which sets stopped.  yielding is set while it
+	// runs, so that panics of the caller's loop body are not recovered.
+	yield    func(k, s string) bool
+	stopped  bool
+	yielding bool
+
+	// keyFields maps the URL parameters encoded so far to the path of the
+	// first field producing them, when maxLength or reserved is set.
+	keyFields map[string]string
+
+	// structs holds the structs being encoded, outermost first, to find
+	// the functions registered with RegisterFieldEncoder.
+	structs []structFrame
+}
+
+// add adds the value s to the URL parameter k, recording the order of k.
+func (e *encoder) add(values url.Values, k, s string) {
+	e.record(k)
+	values.Add(k, s)
+	e.emit(k, s)
+	if e.redacting {
+		if e.redactedKeys == nil {
+			e.redactedKeys = make(map[string]bool)
+		}
+		e.redactedKeys[k] = true
+	}
+	e.traceValue(k, s)
+}
+
+// emit passes the parameter value k=s to e.yield, unless it asked to stop.
+func (e *encoder) emit(k, s string) {
+	if e.yield == nil || e.stopped {
+		return
+	}
+	e.yielding = true
+	more := e.yield(k, s)
+	e.yielding = false
+	if !more {
+		e.stopped = true
+	}
+}
+
+// record notes k as encoded, if it is not already.
+func (e *encoder) record(k string) {
+	if e.seen[k] {
+		return
+	}
+	if e.seen == nil {
+		e.seen = make(map[string]bool)
+	}
+	e.seen[k] = true
+	e.keys = append(e.keys, k)
+	if e.maxLength > 0 || len(e.reserved) > 0 {
+		if e.keyFields == nil {
+			e.keyFields = make(map[string]string)
+		}
+		e.keyFields[k] = strings.Join(e.path, ".")
+	}
+}
+
+// recordAdded notes the URL parameters of values added without e.add, such as
+// those of custom Encoders, in sorted order.
+func (e *encoder) recordAdded(values url.Values) {
+	var added []string
+	for k := range values {
+		if !e.seen[k] {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(added)
+	for _, k := range added {
+		e.record(k)
+	}
+}
+
+// fieldError handles err, which occurred while encoding the field named name.
+// If errors are being collected, err is recorded and nil returned so that
+// encoding continues with the next field.  Otherwise err is returned
+// unchanged.
+func (e *encoder) fieldError(name string, err error) error {
+	if !e.collectErrors {
+		return err
+	}
+	if _, ok := err.(*FieldError); !ok {
+		err = &FieldError{Name: name, Err: err}
+	}
+	e.errs = append(e.errs, err)
+	return nil
+}
+
+// claim records that the field being encoded produces the URL parameter
+// name.  If collisions are being checked and another field already produced
+// name, an error is returned.
+func (e *encoder) claim(name string) error {
+	if !e.checkCollisions {
+		return nil
+	}
+	field := strings.Join(e.path, ".")
+	if prev, ok := e.names[name]; ok {
+		err := fmt.Errorf("field %s collides with field %s", field, prev)
+		return e.fieldError(name, &FieldError{Name: name, Err: err})
+	}
+	if e.names == nil {
+		e.names = make(map[string]string)
+	}
+	e.names[name] = field
+	return nil
+}
+
 // reflectValue populates the values parameter from the struct fields in val.
 // Embedded structs are followed recursively (using the rules defined in the
-// Values function documentation) breadth-first.
+// Values function documentation) breadth-first, or depth-first when the
+// embedded order is EmbeddedInline.
 // Caller should have filtered out non-structs
-func reflectValue(values url.Values, val reflect.Value, scope string) error {
-	logit("\n\nval", val)
-	logit("\n\nscope", scope)
+func (e *encoder) reflectValue(values url.Values, val reflect.Value, scope string) error {
+	e.logit("\n\nval", val)
+	e.logit("\n\nscope", scope)
+
+	// embedded holds the indexes of embedded struct fields
+	var embedded []int
+
+	// Fields of val extend the field path of the caller.  The path is
+	// deliberately left untouched when panicking, so that it names the
+	// offending field.
+	depth := len(e.path)
 
-	var embedded []reflect.Value
+	// Fields nested in a redacted field are redacted as well
+	redacting, included := e.redacting, e.included
 
 	typ := val.Type()
-	logit("typ", typ)
+	e.logit("typ", typ)
+
+	e.structs = append(e.structs, structFrame{typ, depth})
+	defer func() { e.structs = e.structs[:len(e.structs)-1] }()
 
 	for i := 0; i < typ.NumField(); i++ {
-		logit("\n\n**** Field #", i)
+		// Stop early once the consumer of All has had enough
+		if e.stopped {
+			break
+		}
+		e.logit("\n\n**** Field #", i)
 
-		sf := typ.Field(i)
-		logit("sf", sf)
-		logit("sf.PkgPath", sf.PkgPath)
-		logit("sf.Anonymous", sf.Anonymous)
+		sf := structField(typ, i)
+		e.path = append(e.path[:depth], sf.Name)
+		e.redacting, e.included = redacting, included
+		e.logit("sf", sf)
+		e.logit("sf.PkgPath", sf.PkgPath)
+		e.logit("sf.Anonymous", sf.Anonymous)
 
-		// Ignore field if field is unexported
+		// Ignore field if field is unexported, unless it has a getter
 		// sf.PkgPath != "" if lowercase field name
 		// sf.Anonymous == embedded field
+		var sv reflect.Value
 		if sf.PkgPath != "" && !sf.Anonymous { // unexported
-			logit("unexported - continue", true)
+			getter, ok := e.getter(val, sf)
+			if !ok {
+				e.logit("unexported - continue", true)
+				e.traceStep(TraceUnexported, "")
+				continue
+			}
+			e.logit("unexported with getter", true)
+			sv = getter
+		} else {
+			sv = val.Field(i)
+		}
+
+		tag := e.fieldTag(sf)
+		e.logit("url tag", tag)
+
+		// Ignore field if tag name == "-", or if it only describes another
+		// part of a request, such as a path parameter
+		if tag == "-" || companionOnly(sf, tag) {
+			e.logit("tag is unexported due to - - continue", true)
+			e.traceStep(TraceIgnored, "")
 			continue
 		}
+		name, opts := parseTag(tag)
+		e.logit("name", name)
+		e.logit("opts", opts)
+		e.redacting = redacting || e.redacts(sf, name, opts)
+		e.logit("sv", sv)
 
-		sv := val.Field(i)
-		logit("sv", sv)
+		// Anonymous struct fields without a name in their tag are
+		// flattened into the enclosing struct, as are named ones when
+		// EmbeddedFlatten is in effect.
+		if sf.Anonymous && (name == "" || e.embeddedNaming == EmbeddedFlatten) {
+			e.logit("sv.Kind()", sv.Kind())
 
-		tag := sf.Tag.Get("url")
-		logit("url tag", tag)
+			if ev, ok := embeddedStruct(sv); ok {
+				// Skip nil embedded struct pointers
+				if !ev.IsValid() {
+					e.logit("nil embedded struct pointer - continue", true)
+					e.traceStep(TraceNil, "")
+					continue
+				}
+				if e.embeddedOrder == EmbeddedInline {
+					e.logit("Embedded (Anonymous) struct - encode inline and continue", true)
+					e.traceStep(TraceEmbedded, "")
+					if err := e.reflectValue(values, ev, scope); err != nil {
+						return err
+					}
+					continue
+				}
+				// Defer embedded struct processing (save and continue)
+				e.logit("Embedded (Anonymous) struct - save ev for later and continue", true)
+				e.traceStep(TraceEmbedded, "")
+				embedded = append(embedded, i)
+				continue
+			}
 
-		// Ignore field if tag name == "-"
-		if tag == "-" {
-			logit("tag is unexported due to - - continue", true)
+			// Unexported anonymous fields are only followed for structs
+			if sf.PkgPath != "" {
+				e.logit("unexported non-struct embedded field - continue", true)
+				e.traceStep(TraceUnexported, "")
+				continue
+			}
+		}
+
+		// Ignore untagged fields if tags are required
+		if tag == "" && e.requireTag {
+			e.logit("untagged field - continue", true)
+			e.traceStep(TraceUntagged, "")
 			continue
 		}
-		name, opts := parseTag(tag)
-		logit("name", name)
-		logit("opts", opts)
 
 		// If no name specified, use the Field name
 		if name == "" {
-			logit("name == ''", true)
+			name = e.fieldName(sf)
+			e.logit("Set name to field name", name)
+		}
+		tagName := name
+
+		if scope != "" {
+			name = e.scopedName(scope, name)
+			e.logit("updated, scoped name", name)
+		}
 
-			logit("sv.Kind()", sv.Kind())
+		// Leave out the fields not selected by Only and Except
+		if !e.selects(sf, tagName, name) {
+			e.logit("field not selected - continue", true)
+			e.traceStep(TraceExcluded, name)
+			continue
+		}
 
-			// Defer embedded struct processing (save and continue)
-			if sf.Anonymous && sv.Kind() == reflect.Struct {
-				// save embedded struct for later processing
-				logit("Embedded (Anonymous) struct - save sv for later and continue", true)
-				embedded = append(embedded, sv)
+		if opts.Contains("omitempty") && isEmptyValue(sv) {
+			e.logit("omitempty option - continue", true)
+			e.traceStep(TraceOmitted, name)
+			continue
+		}
+
+		if err := e.validate(sf, sv, opts); err != nil {
+			e.traceStep(TraceInvalid, name)
+			if err := e.fieldError(name, &FieldError{Name: name, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// File fields are only encoded into multipart forms
+		if opts.Contains("file") {
+			if e.collectFiles {
+				e.files = append(e.files, filePart{name, sv})
+			}
+			e.logit("file field - continue", true)
+			e.traceStep(TraceFile, name)
+			continue
+		}
+
+		// Field masks with the "comma" option keep their paths as they are
+		if sv.Type() == fieldMaskType && opts.Contains("comma") {
+			e.traceStep(TraceEncoded, name)
+			if err := e.claim(name); err != nil {
+				return err
+			}
+			e.add(values, name, strings.Join(sv.Interface().(FieldMask), ","))
+			continue
+		}
+
+		// Fields with a registered encoder function have a single value
+		if fn := e.fieldEncoder(); fn != nil {
+			e.logit("field encoder function", true)
+			e.traceStep(TraceEncoder, name)
+			if err := e.claim(name); err != nil {
+				return err
+			}
+			s, err := fn(sv)
+			if err != nil {
+				if e.redacting {
+					err = redactError(err)
+				}
+				if err := e.fieldError(name, &FieldError{Name: name, Err: err}); err != nil {
+					return err
+				}
 				continue
 			}
+			e.add(values, name, s)
+			continue
+		}
 
-			name = sf.Name
-			logit("Set name to field name", name)
+		// Time ranges are encoded following the options for times
+		if tr, ok := indirectTimeRange(sv); ok {
+			e.traceStep(TraceEncoded, name)
+			if err := e.timeRangeValue(values, name, tr, opts); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if scope != "" {
-			name = scope + "[" + name + "]"
-			logit("updated, scoped name", name)
+		// Amounts of money are encoded following their options
+		if m, ok := indirectMoney(sv); ok {
+			e.traceStep(TraceEncoded, name)
+			if err := e.moneyValue(values, name, m, opts); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if opts.Contains("omitempty") && isEmptyValue(sv) {
-			logit("omitempty option - continue", true)
+		// Geographic types are encoded following their options
+		if isGeoType(indirectType(sv.Type())) && sv.CanInterface() {
+			e.traceStep(TraceEncoded, name)
+			if err := e.claim(name); err != nil {
+				return err
+			}
+			s, ok, err := e.fieldValue(name, sv, opts)
+			if err != nil {
+				return err
+			}
+			if ok {
+				e.add(values, name, s)
+			}
 			continue
 		}
 
+		// Null held by an interface is encoded as an empty value
+		if isNull(sv) {
+			sv = sv.Elem()
+		}
+
 		// Detect if sv.Type() implements Encoder
-		if sv.Type().Implements(encoderType) {
-			logit("custom encoder", true)
+		if isEncoder(sv) {
+			e.logit("custom encoder", true)
+			e.traceStep(TraceEncoder, name)
+			if err := e.claim(name); err != nil {
+				return err
+			}
 			//  Detect if nil Encoder interface ptr
 			if !reflect.Indirect(sv).IsValid() {
 				// Instantiate a zero value Encoder if ptr is nil
-				logit("sv NotValid", true)
-				logit("sv.Type().Kind()", sv.Type().Kind())
-				logit("sv.Type().Elem()", sv.Type().Elem())
+				e.logit("sv NotValid", true)
+				e.logit("sv.Type().Kind()", sv.Type().Kind())
+				e.logit("sv.Type().Elem()", sv.Type().Elem())
 				sv = reflect.New(sv.Type().Elem())
 			}
 
 			m := sv.Interface().(Encoder)
-			if err := m.EncodeValues(name, &values); err != nil {
+			counts := e.countValues(values)
+			err := m.EncodeValues(name, &values)
+			e.recordAdded(values)
+			e.noteAdded(values, counts)
+			if err != nil {
+				if e.redacting {
+					err = redactError(err)
+				}
+				if err := e.fieldError(name, err); err != nil {
+					return err
+				}
+			}
+			e.logit("use custom encoder - continue", true)
+			continue
+		}
+
+		// Dereference non-nil pointers so that the rules below apply to
+		// the value pointed to, e.g. slice options for *[]string fields.
+		for sv.Kind() == reflect.Ptr {
+			if sv.IsNil() {
+				break
+			}
+			sv = sv.Elem()
+		}
+
+		// Fields with an OpenAPI style are encoded by its rules
+		if style, explode, ok := e.fieldStyle(opts); ok {
+			e.traceStep(TraceEncoded, name)
+			if err := e.styledValue(values, name, sv, style, explode, opts); err != nil {
 				return err
 			}
-			logit("use custom encoder - continue", true)
 			continue
 		}
 
+		if sv.Kind() == reflect.Struct && sv.Type() != timeType {
+			e.traceStep(TraceNested, name)
+			if err := e.reflectValue(values, sv, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sv.Kind() == reflect.Map && e.nestedMaps {
+			e.traceStep(TraceEncoded, name)
+			if err := e.mapValue(values, sv, name, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Expand slices of structs into one scope per element if enabled
+		if (sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array) && e.expandsStructs() && nestedStructType(sv.Type().Elem()) {
+			e.logit("indexed struct slice", true)
+			e.traceStep(TraceNested, name)
+			for i := 0; i < sv.Len(); i++ {
+				ev := sv.Index(i)
+				for ev.Kind() == reflect.Ptr && !ev.IsNil() {
+					ev = ev.Elem()
+				}
+				if ev.Kind() != reflect.Struct {
+					continue
+				}
+				if err := e.reflectValue(values, ev, e.structScope(name, i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		e.traceStep(TraceEncoded, name)
+		if err := e.claim(name); err != nil {
+			return err
+		}
+
 		if sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array {
 			var del byte
 			if opts.Contains("comma") {
@@ -261,86 +764,300 @@ func reflectValue(values url.Values, val reflect.Value, scope string) error {
 				del = ';'
 			} else if opts.Contains("brackets") {
 				name = name + "[]"
+			} else if e.arrayFormat == ArrayComma && !opts.Contains("numbered") {
+				del = ','
 			}
 
 			if del != 0 {
+				if err := e.separatorConflict(sv.Type(), string(del)); err != nil {
+					if err := e.fieldError(name, &FieldError{Name: name, Err: err}); err != nil {
+						return err
+					}
+					continue
+				}
 				s := new(bytes.Buffer)
 				first := true
 				for i := 0; i < sv.Len(); i++ {
+					str, ok, err := e.fieldValue(name, sv.Index(i), opts)
+					if err != nil {
+						return err
+					}
+					if !ok {
+						continue
+					}
 					if first {
 						first = false
 					} else {
 						s.WriteByte(del)
 					}
-					s.WriteString(valueString(sv.Index(i), opts))
+					s.WriteString(str)
 				}
-				values.Add(name, s.String())
+				e.add(values, name, s.String())
 			} else {
 				for i := 0; i < sv.Len(); i++ {
 					k := name
 					if opts.Contains("numbered") {
 						k = fmt.Sprintf("%s%d", name, i)
+					} else if e.arrayFormat != ArrayRepeat && !opts.Contains("brackets") {
+						k = e.elementScope(name, i)
+					}
+					str, ok, err := e.fieldValue(name, sv.Index(i), opts)
+					if err != nil {
+						return err
+					}
+					if ok {
+						e.add(values, k, str)
 					}
-					values.Add(k, valueString(sv.Index(i), opts))
 				}
 			}
 			continue
 		}
 
-		if sv.Type() == timeType {
-			values.Add(name, valueString(sv, opts))
-			continue
+		str, ok, err := e.fieldValue(name, sv, opts)
+		if err != nil {
+			return err
 		}
+		if ok {
+			e.add(values, name, str)
+		}
+	}
 
-		for sv.Kind() == reflect.Ptr {
-			if sv.IsNil() {
-				break
-			}
-			sv = sv.Elem()
+	for _, i := range embedded {
+		if e.stopped {
+			break
+		}
+		sf := structField(typ, i)
+		e.path = append(e.path[:depth], sf.Name)
+		name, opts := parseTag(e.fieldTag(sf))
+		e.redacting = redacting || e.redacts(sf, name, opts)
+		e.included = included
+		f, _ := embeddedStruct(val.Field(i))
+		if err := e.reflectValue(values, f, scope); err != nil {
+			return err
 		}
+	}
+
+	e.path = e.path[:depth]
+	e.redacting, e.included = redacting, included
+	return nil
+}
 
-		if sv.Kind() == reflect.Struct {
-			reflectValue(values, sv, name)
+// mapValue encodes the entries of the map v, in the order of their keys, as
+// if they were the fields of a nested struct named name.  Slices held by the
+// map are encoded as multiple values of the same name.
+func (e *encoder) mapValue(values url.Values, v reflect.Value, name string, opts tagOptions) error {
+	keys := make([]string, 0, v.Len())
+	entries := make(map[string]reflect.Value, v.Len())
+	for _, k := range v.MapKeys() {
+		ks := fmt.Sprint(k.Interface())
+		keys = append(keys, ks)
+		entries[ks] = v.MapIndex(k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ev := entries[k]
+		for (ev.Kind() == reflect.Interface || ev.Kind() == reflect.Ptr) && !ev.IsNil() {
+			ev = ev.Elem()
+		}
+		scoped := e.scopedName(name, k)
+		if ev.Kind() == reflect.Struct && nestedStructType(ev.Type()) {
+			if err := e.reflectValue(values, ev, scoped); err != nil {
+				return err
+			}
 			continue
 		}
+		if err := e.claim(scoped); err != nil {
+			return err
+		}
+		elems := []reflect.Value{ev}
+		if ev.Kind() == reflect.Slice || ev.Kind() == reflect.Array {
+			elems = elems[:0]
+			for i := 0; i < ev.Len(); i++ {
+				elems = append(elems, ev.Index(i))
+			}
+		}
+		for _, elem := range elems {
+			str, ok, err := e.fieldValue(scoped, elem, opts)
+			if err != nil {
+				return err
+			}
+			if ok {
+				e.add(values, scoped, str)
+			}
+		}
+	}
+	return nil
+}
 
-		values.Add(name, valueString(sv, opts))
+// embeddedStruct returns the struct held by the anonymous field v, following
+// pointers.  ok is false if v does not hold a struct, or if v implements
+// Encoder, in which case the field is encoded like any other field.  A nil
+// pointer results in the zero Value with ok set to true.
+func embeddedStruct(v reflect.Value) (s reflect.Value, ok bool) {
+	if isEncoder(v) {
+		return reflect.Value{}, false
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return reflect.Value{}, false
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, true
+		}
+		v = v.Elem()
+	}
+	if isEncoder(v) {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
 
-	for _, f := range embedded {
-		if err := reflectValue(values, f, scope); err != nil {
-			return err
+// nestedStructType reports whether values of type t, following pointers, are
+// encoded as nested structs rather than as single values.
+func nestedStructType(t reflect.Type) bool {
+	if t.Implements(encoderType) {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !t.Implements(encoderType)
+}
+
+// companionTags lists the keys of struct tags describing parts of a request
+// other than its query.
+var companionTags = []string{"path", "header", "cookie"}
+
+// companionOnly reports whether the field sf, whose tag for the configured key
+// is tag, only has companion tags, and so is not part of the query.
+func companionOnly(sf reflect.StructField, tag string) bool {
+	if tag != "" {
+		return false
+	}
+	for _, key := range companionTags {
+		if sf.Tag.Get(key) != "" {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// isEncoder reports whether v implements Encoder and its EncodeValues method
+// can be called.  Values obtained through unexported embedded fields cannot be
+// converted to an interface, so they are never treated as Encoders.
+func isEncoder(v reflect.Value) bool {
+	return v.Type().Implements(encoderType) && v.CanInterface()
+}
+
+// errSkipValue is returned by valueString for values that should be left out
+// of the encoding altogether.
+var errSkipValue = errors.New("skip value")
+
+// fieldValue returns the string representation of the value v of the field
+// named name.  ok is false if the value should be left out, either because of
+// an option or because an error was collected by e.fieldError.
+func (e *encoder) fieldValue(name string, v reflect.Value, opts tagOptions) (s string, ok bool, err error) {
+	s, err = e.valueString(v, opts)
+	if err == errSkipValue {
+		e.logit("value skipped", true)
+		return "", false, nil
+	}
+	if err == nil {
+		err = e.redactValidation(e.checkAllowed(name, s))
+	}
+	if err != nil {
+		if e.redacting {
+			err = redactError(err, fmt.Sprint(v))
+		}
+		return "", false, e.fieldError(name, &FieldError{Name: name, Err: err})
+	}
+	return s, true, nil
 }
 
 // valueString returns the string representation of a value.
-func valueString(v reflect.Value, opts tagOptions) string {
+func (e *encoder) valueString(v reflect.Value, opts tagOptions) (string, error) {
+	if isNull(v) {
+		return "", nil
+	}
+
 	for v.Kind() == reflect.Ptr {
 		if v.IsNil() {
-			return ""
+			return "", nil
 		}
 		v = v.Elem()
 	}
 
+	if isGeoType(v.Type()) && v.CanInterface() {
+		return geoString(v, opts)
+	}
+
 	if v.Kind() == reflect.Bool && opts.Contains("int") {
 		if v.Bool() {
-			return "1"
+			return "1", nil
+		}
+		return "0", nil
+	}
+
+	if v.Kind() == reflect.Bool && e.boolCase != BoolLower && !v.Type().Implements(stringerType) {
+		s := strconv.FormatBool(v.Bool())
+		if e.boolCase == BoolUpper {
+			return strings.ToUpper(s), nil
+		}
+		return strings.ToUpper(s[:1]) + s[1:], nil
+	}
+
+	if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return e.nonFiniteString(f)
+		}
+		if (e.floatFormat != nil || e.decimalSeparator != "") && !v.Type().Implements(stringerType) {
+			return e.formatFloat(v.Float(), v.Type().Bits()), nil
 		}
-		return "0"
 	}
 
 	if v.Type() == timeType {
-		t := v.Interface().(time.Time)
+		t, err := e.timeIn(v.Interface().(time.Time), opts)
+		if err != nil {
+			return "", err
+		}
 		if opts.Contains("unix") {
-			return strconv.FormatInt(t.Unix(), 10)
+			return strconv.FormatInt(t.Unix(), 10), nil
+		}
+		if e.dateOnly || opts.Contains("dateonly") {
+			return t.Format(dateLayout), nil
 		}
-		return t.Format(time.RFC3339)
+		return t.Format(e.timeLayout), nil
+	}
+
+	if v.Type() == durationType && e.durationSeconds {
+		d := time.Duration(v.Int())
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s", nil
+	}
+
+	if v.Kind() == reflect.String && e.normalize != nil && !v.Type().Implements(stringerType) {
+		return e.normalize(v.String()), nil
 	}
 
-	return fmt.Sprint(v.Interface())
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// nonFiniteString returns the encoding of the NaN or infinite float f
+// according to the configured NonFinitePolicy.
+func (e *encoder) nonFiniteString(f float64) (string, error) {
+	switch e.nonFinite {
+	case NonFiniteError:
+		return "", fmt.Errorf("non-finite float value %v", f)
+	case NonFiniteOmit:
+		return "", errSkipValue
+	case NonFiniteReplace:
+		return e.nonFiniteReplacement, nil
+	}
+	return fmt.Sprint(f), nil
 }
 
 // isEmptyValue checks if a value should be considered empty for the purposes
@@ -358,13 +1075,33 @@ func isEmptyValue(v reflect.Value) bool {
 	case reflect.Float32, reflect.Float64:
 		return v.Float() == 0
 	case reflect.Interface, reflect.Ptr:
-		return v.IsNil()
+		if v.IsNil() {
+			return true
+		}
+		// A non-nil pointer is only empty if the value it points to
+		// reports itself as zero, e.g. a pointer to a zero time.Time.
+		return isZeroer(v)
 	}
 
-	if v.Type() == timeType {
-		return v.Interface().(time.Time).IsZero()
-	}
+	return isZeroer(v)
+}
 
+// isZeroer reports whether v implements zeroer and its IsZero method returns
+// true.  IsZero is not called on nil pointers, such as held by interfaces,
+// as it may dereference its receiver.
+func isZeroer(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return false
+	}
+	if z, ok := v.Interface().(zeroer); ok {
+		return z.IsZero()
+	}
 	return false
 }
 
@@ -389,11 +1126,13 @@ func (o tagOptions) Contains(option string) bool {
 	return false
 }
 
-func logit(m string, val interface{}) {
-	//pc, fn, line, _ := runtime.Caller(1)
-	//log.Printf("%s[%s:%d] %v (type %T_ = %+v", runtime.FuncForPC(pc).Name(), fn, line, m, val, val)
-	log.SetFlags(0)
-	_, fn, line, _ := runtime.Caller(1)
-	fn = path.Base(fn)
-	log.Printf("%v - L%d %v (type %T) = %+v", fn, line, m, val, val)
+// Value returns the value of the option given as "key=value", and whether the
+// tagOptions contains it.
+func (o tagOptions) Value(key string) (string, bool) {
+	for _, s := range o {
+		if strings.HasPrefix(s, key+"=") {
+			return s[len(key)+1:], true
+		}
+	}
+	return "", false
 }
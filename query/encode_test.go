@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -102,6 +103,29 @@ func TestValues_types(t *testing.T) {
 				"K1":  {"b"},
 			},
 		},
+		{
+			// pointers to slices and arrays
+			struct {
+				A *[]string
+				B *[]string  `url:",comma"`
+				C *[2]string `url:",brackets"`
+				D *[]string  `url:",numbered"`
+				E *[]string  `url:",comma"`
+			}{
+				A: &[]string{"a", "b"},
+				B: &[]string{"a", "b"},
+				C: &[2]string{"a", "b"},
+				D: &[]string{"a", "b"},
+			},
+			url.Values{
+				"A":   {"a", "b"},
+				"B":   {"a,b"},
+				"C[]": {"a", "b"},
+				"D0":  {"a"},
+				"D1":  {"b"},
+				"E":   {""},
+			},
+		},
 		{
 			// other types
 			struct {
@@ -174,28 +198,99 @@ func TestValues_types(t *testing.T) {
 
 func TestValues_omitEmpty(t *testing.T) {
 	str := ""
+	zero := time.Time{}
+	now := time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC)
 	s := struct {
 		a string
 		A string
-		B string  `url:",omitempty"`
-		C string  `url:"-"`
-		D string  `url:"omitempty"` // actually named omitempty, not an option
-		E *string `url:",omitempty"`
-	}{E: &str}
+		B string     `url:",omitempty"`
+		C string     `url:"-"`
+		D string     `url:"omitempty"` // actually named omitempty, not an option
+		E *string    `url:",omitempty"`
+		F *time.Time `url:",omitempty"`
+		G *time.Time `url:",omitempty"`
+	}{E: &str, F: &zero, G: &now}
 
 	logit("\n\nTestcase", s)
 	v, err := Values(s)
 	if err != nil {
-		t.Errorf("Values(%q) returned error: %v", s, err)
+		t.Errorf("Values(%v) returned error: %v", s, err)
 	}
 
 	want := url.Values{
 		"A":         {""},
 		"omitempty": {""},
 		"E":         {""}, // E is included because the pointer is not empty, even though the string being pointed to is
+		"G":         {"2000-01-01T12:34:56Z"},
 	}
 	if !reflect.DeepEqual(want, v) {
-		t.Errorf("Values(%q) returned %v, want %v", s, v, want)
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+type Decimal struct {
+	Units int64
+	Nanos int32
+}
+
+func (d Decimal) IsZero() bool { return d.Units == 0 && d.Nanos == 0 }
+
+func (d Decimal) String() string { return fmt.Sprintf("%d.%09d", d.Units, d.Nanos) }
+
+func (d Decimal) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, d.String())
+	return nil
+}
+
+type Optional struct {
+	Set   bool
+	Value string
+}
+
+func (o *Optional) IsZero() bool { return !o.Set }
+
+func TestValues_omitEmptyZeroer(t *testing.T) {
+	s := struct {
+		A Decimal   `url:",omitempty"`
+		B Decimal   `url:",omitempty"`
+		C *Optional `url:",omitempty"`
+		D *Optional `url:",omitempty"`
+	}{
+		B: Decimal{Units: 1},
+		C: &Optional{},
+		D: &Optional{Set: true, Value: "v"},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+
+	want := url.Values{
+		"B":        {"1.000000000"},
+		"D[Set]":   {"true"},
+		"D[Value]": {"v"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+func TestValues_omitEmptyTypedNil(t *testing.T) {
+	var nilOptional *Optional
+	s := struct {
+		A interface{} `url:",omitempty"`
+		B zeroer      `url:",omitempty"`
+	}{nilOptional, nilOptional}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+
+	want := url.Values{"A": {"<nil>"}, "B": {"<nil>"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
 	}
 }
 
@@ -221,6 +316,16 @@ type F struct {
 	e
 }
 
+type G struct {
+	*B
+	C string
+}
+
+type H struct {
+	B `url:"b"`
+	C string
+}
+
 func TestValues_embeddedStructs(t *testing.T) {
 	tests := []struct {
 		in   interface{}
@@ -238,6 +343,18 @@ func TestValues_embeddedStructs(t *testing.T) {
 			F{e{B: B{C: "bar"}, C: "foo"}}, // With unexported embed
 			url.Values{"C": {"foo", "bar"}},
 		},
+		{
+			G{B: &B{C: "bar"}, C: "foo"}, // With embedded pointer
+			url.Values{"C": {"foo", "bar"}},
+		},
+		{
+			G{C: "foo"}, // With nil embedded pointer
+			url.Values{"C": {"foo"}},
+		},
+		{
+			H{B: B{C: "bar"}, C: "foo"}, // With named embed
+			url.Values{"b[C]": {"bar"}, "C": {"foo"}},
+		},
 	}
 
 	for i, tt := range tests {
@@ -253,6 +370,64 @@ func TestValues_embeddedStructs(t *testing.T) {
 	}
 }
 
+func TestValues_maps(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want url.Values
+	}{
+		{
+			struct{ M map[string]string }{map[string]string{"b": "2", "a": "1"}},
+			url.Values{"M[a]": {"1"}, "M[b]": {"2"}},
+		},
+		{
+			struct {
+				M map[int][]string `url:"m"`
+			}{map[int][]string{1: {"x", "y"}}},
+			url.Values{"m[1]": {"x", "y"}},
+		},
+		{
+			struct {
+				M map[string]*SubNested `url:"m"`
+			}{map[string]*SubNested{"k": {"v"}}},
+			url.Values{"m[k][value]": {"v"}},
+		},
+		{
+			struct {
+				M map[string]string `url:"m,omitempty"`
+			}{map[string]string{}},
+			url.Values{},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(tt.in, WithNestedMaps())
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, tt.in, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, tt.in, v, tt.want)
+		}
+	}
+
+	// without WithNestedMaps or WithStripe, maps use their default format
+	in := struct{ M map[string]string }{map[string]string{"a": "1"}}
+	v, err := Values(in)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", in, err)
+	}
+	if want := (url.Values{"M": {"map[a:1]"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+	v, err = Values(in, WithStripe())
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", in, err)
+	}
+	if want := (url.Values{"M[a]": {"1"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) with WithStripe returned %v, want %v", in, v, want)
+	}
+}
+
 func TestValues_invalidInput(t *testing.T) {
 	_, err := Values("")
 	if err == nil {
@@ -288,18 +463,81 @@ func TestValues_Marshaler(t *testing.T) {
 	}
 }
 
+type EncodedEmbed struct {
+	Value string
+}
+
+func (m EncodedEmbed) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, "encoded:"+m.Value)
+	return nil
+}
+
+func TestValues_MarshalerEmbedded(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want url.Values
+	}{
+		{
+			struct {
+				EncodedEmbed
+			}{EncodedEmbed{"a"}},
+			url.Values{"EncodedEmbed": {"encoded:a"}},
+		},
+		{
+			struct {
+				EncodedEmbed `url:"embed"`
+			}{EncodedEmbed{"a"}},
+			url.Values{"embed": {"encoded:a"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(tt.in)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, tt.in, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, tt.in, v, tt.want)
+		}
+	}
+}
+
 func TestValues_MarshalerWithNilPointer(t *testing.T) {
 	s := struct {
 		Args *EncodedArgs `url:"arg"`
 	}{}
 	v, err := Values(s)
 	if err != nil {
-		t.Errorf("Values(%q) returned error: %v", s, err)
+		t.Errorf("Values(%v) returned error: %v", s, err)
 	}
 
 	want := url.Values{}
 	if !reflect.DeepEqual(want, v) {
-		t.Errorf("Values(%q) returned %v, want %v", s, v, want)
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+type panickingEncoder struct{}
+
+func (panickingEncoder) EncodeValues(key string, v *url.Values) error {
+	panic("boom")
+}
+
+func TestValues_panic(t *testing.T) {
+	s := struct {
+		A string
+		N struct {
+			B panickingEncoder
+		}
+	}{}
+
+	v, err := Values(s)
+	if v != nil {
+		t.Errorf("Values(%v) returned %v, want nil", s, v)
+	}
+	if err == nil || !strings.Contains(err.Error(), ".N.B: boom") {
+		t.Errorf("Values(%v) returned error %v, want panic in N.B", s, err)
 	}
 }
 
@@ -0,0 +1,103 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Equal reports whether the query strings a and b, with or without their
+// leading "?", hold the same parameters, that is the same multiset of decoded
+// key/value pairs.  The order of parameters and values and the way they are
+// escaped do not matter, so "b=2&a=x+y" equals "a=x%20y&b=2".  Queries that
+// cannot be parsed are only equal to identical strings.
+//
+// Equal is meant for tests asserting that two requests are the same; see
+// DiffQuery to report how they differ.
+func Equal(a, b string) bool {
+	if a == b {
+		return true
+	}
+	va, err := url.ParseQuery(strings.TrimPrefix(a, "?"))
+	if err != nil {
+		return false
+	}
+	vb, err := url.ParseQuery(strings.TrimPrefix(b, "?"))
+	if err != nil {
+		return false
+	}
+	return diffValues(va, vb) == ""
+}
+
+// DiffQuery returns a description of the differences between the parameters of
+// the query strings a and b, compared as done by Equal, or the empty string if
+// there are none.  Each line holds a key/value pair, escaped, preceded by "-"
+// if it is only in a or by "+" if it is only in b:
+//
+//	if diff := query.DiffQuery(got, want); diff != "" {
+//		t.Errorf("query mismatch (-got +want):\n%s", diff)
+//	}
+func DiffQuery(a, b string) string {
+	va, err := url.ParseQuery(strings.TrimPrefix(a, "?"))
+	if err != nil {
+		return "-" + a + " (" + err.Error() + ")\n+" + b + "\n"
+	}
+	vb, err := url.ParseQuery(strings.TrimPrefix(b, "?"))
+	if err != nil {
+		return "-" + a + "\n+" + b + " (" + err.Error() + ")\n"
+	}
+	return diffValues(va, vb)
+}
+
+// diffValues implements DiffQuery for parsed queries.
+func diffValues(a, b url.Values) string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, k := range keys {
+		onlyA, onlyB := multisetDiff(a[k], b[k])
+		for _, v := range onlyA {
+			buf.WriteString("-" + url.QueryEscape(k) + "=" + url.QueryEscape(v) + "\n")
+		}
+		for _, v := range onlyB {
+			buf.WriteString("+" + url.QueryEscape(k) + "=" + url.QueryEscape(v) + "\n")
+		}
+	}
+	return buf.String()
+}
+
+// multisetDiff returns the values of a not in b and those of b not in a,
+// counting repeated values, in sorted order.
+func multisetDiff(a, b []string) (onlyA, onlyB []string) {
+	count := make(map[string]int)
+	for _, v := range a {
+		count[v]++
+	}
+	for _, v := range b {
+		count[v]--
+	}
+	for v, n := range count {
+		for ; n > 0; n-- {
+			onlyA = append(onlyA, v)
+		}
+		for ; n < 0; n++ {
+			onlyB = append(onlyB, v)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
@@ -0,0 +1,46 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"", "?", true},
+		{"b=2&a=x+y", "?a=x%20y&b=2", true},
+		{"a=1&a=2", "a=2&a=1", true},
+		{"a=1&a=1", "a=1", false},
+		{"a=1", "a=1&b=", false},
+		{"a=%zz", "a=%zz", true},
+		{"a=%zz", "a=%ZZ", false},
+	}
+	for i, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("%d. Equal(%q, %q) returned %v, want %v", i, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiffQuery(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"b=2&a=1", "a=1&b=2", ""},
+		{"a=1&a=1&c=x", "a=1&b=y+z", "-a=1\n+b=y+z\n-c=x\n"},
+		{"a=%zz", "a=1", "-a=%zz (invalid URL escape \"%zz\")\n+a=1\n"},
+	}
+	for i, tt := range tests {
+		if got := DiffQuery(tt.a, tt.b); got != tt.want {
+			t.Errorf("%d. DiffQuery(%q, %q) returned %q, want %q", i, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
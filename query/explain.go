@@ -0,0 +1,157 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// A FieldMapping describes how a struct field is encoded by Values, as
+// reported by Explain.
+type FieldMapping struct {
+	// Field is the path of the field in Go, as in "ListOptions.Filter.State".
+	Field string
+
+	// Param is the name of the URL parameter of the field.
+	Param string
+
+	// Options holds the options of the field's tag.
+	Options []string
+
+	// Empty reports whether the value of the field is empty, in the sense
+	// of the "omitempty" option.
+	Empty bool
+
+	// Omitted reports whether the field is left out of the encoding, and
+	// Reason why.
+	Omitted bool
+	Reason  string
+
+	// Values holds the values encoded for Param.  Fields encoded into
+	// several parameters, such as slices of structs and types implementing
+	// Encoder, may produce other parameters as well.
+	Values []string
+}
+
+// Explain describes how each field of v, which must be a struct or a pointer
+// to one, is encoded by Values with opts: the parameter it maps to, the
+// options applied, whether it is empty or omitted, and the values it ends up
+// with.  It is meant for debugging parameters that are unexpectedly missing or
+// misnamed.
+//
+// Fields of nested structs are described individually, in the order Values
+// encodes them.  The values of redacted fields are replaced by Redacted.
+func Explain(v interface{}, opts ...Option) ([]FieldMapping, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, fmt.Errorf("query: Explain() %v", err)
+	}
+	values, err := Values(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.Value{}
+			break
+		}
+		val = val.Elem()
+	}
+
+	var fields []FieldMapping
+	newConfig(opts).explain(&fields, values, t, val, t.Name(), "", false)
+	return fields, nil
+}
+
+// explain appends the mappings of the fields of the struct type t, whose
+// value is val (invalid if missing), named path in Go and scope in the URL.
+// The values of all fields are redacted if redact is set.
+func (c *config) explain(fields *[]FieldMapping, values url.Values, t reflect.Type, val reflect.Value, path, scope string, redact bool) {
+	c.walkFields(t, func(f typeField) {
+		redact := redact || c.redacts(f.sf, f.name, f.opts)
+		m := FieldMapping{
+			Field: path + "." + goPath(t, f.index),
+			Param: c.scopedName(scope, f.name),
+		}
+		if len(f.opts) > 0 {
+			m.Options = f.opts
+		}
+
+		fv, ok := fieldByIndex(val, f.index)
+		_, _, styled := c.fieldStyle(f.opts)
+		if ok && !styled && nestedStructType(f.sf.Type) && !(f.opts.Contains("omitempty") && isEmptyValue(fv)) {
+			if sv := reflect.Indirect(fv); sv.IsValid() {
+				c.explain(fields, values, indirectType(f.sf.Type), sv, m.Field, m.Param, redact)
+				return
+			}
+		}
+
+		ft := indirectType(f.sf.Type)
+		if (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && f.opts.Contains("brackets") {
+			m.Param += "[]"
+		}
+
+		switch {
+		case !val.IsValid():
+			m.Omitted, m.Reason = true, "nil struct pointer"
+		case !ok:
+			m.Omitted, m.Reason = true, "nil embedded struct pointer"
+		default:
+			m.Empty = isEmptyValue(fv)
+			switch {
+			case f.opts.Contains("omitempty") && m.Empty:
+				m.Omitted, m.Reason = true, "empty with omitempty option"
+			case f.opts.Contains("file"):
+				m.Omitted, m.Reason = true, "file field, only encoded in multipart forms"
+			}
+		}
+		if !m.Omitted {
+			m.Values = values[m.Param]
+			if redact && m.Values != nil {
+				m.Values = redactStrings(m.Values)
+			}
+		}
+		*fields = append(*fields, m)
+	})
+}
+
+// goPath returns the names of the fields at the index sequence index of the
+// struct type t, joined by dots.
+func goPath(t reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, x := range index {
+		t = indirectType(t)
+		sf := t.Field(x)
+		names[i] = sf.Name
+		t = sf.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// fieldByIndex returns the field of the struct v at the index sequence index,
+// following pointers to embedded structs.  ok is false if v is invalid or one
+// of those pointers is nil.
+func fieldByIndex(v reflect.Value, index []int) (f reflect.Value, ok bool) {
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,58 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+type explainOptions struct {
+	Q      string   `url:"q"`
+	Page   int      `url:"page,omitempty"`
+	Tags   []string `url:"tags,brackets"`
+	Filter struct {
+		State string `url:"state"`
+	} `url:"filter"`
+	Data []byte `url:"data,file"`
+	*explainEmbedded
+}
+
+type explainEmbedded struct {
+	Debug bool `url:"debug,int"`
+}
+
+func TestExplain(t *testing.T) {
+	opt := explainOptions{Q: "go", Tags: []string{"a", "b"}}
+	opt.Filter.State = "open"
+
+	got, err := Explain(&opt)
+	if err != nil {
+		t.Fatalf("Explain(%v) returned error: %v", opt, err)
+	}
+	want := []FieldMapping{
+		{Field: "explainOptions.Q", Param: "q", Values: []string{"go"}},
+		{Field: "explainOptions.Page", Param: "page", Options: []string{"omitempty"}, Empty: true, Omitted: true, Reason: "empty with omitempty option"},
+		{Field: "explainOptions.Tags", Param: "tags[]", Options: []string{"brackets"}, Values: []string{"a", "b"}},
+		{Field: "explainOptions.Filter.State", Param: "filter[state]", Values: []string{"open"}},
+		{Field: "explainOptions.Data", Param: "data", Options: []string{"file"}, Empty: true, Omitted: true, Reason: "file field, only encoded in multipart forms"},
+		{Field: "explainOptions.explainEmbedded.Debug", Param: "debug", Options: []string{"int"}, Omitted: true, Reason: "nil embedded struct pointer"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Explain(%v) returned\n%+v, want\n%+v", opt, got, want)
+	}
+
+	got, err = Explain((*explainOptions)(nil))
+	if err != nil {
+		t.Fatalf("Explain(nil) returned error: %v", err)
+	}
+	if len(got) != 6 || !got[0].Omitted || got[0].Reason != "nil struct pointer" {
+		t.Errorf("Explain(nil) returned %+v, want all fields omitted", got)
+	}
+
+	if _, err := Explain(""); err == nil {
+		t.Errorf("expected Explain() to return an error on invalid input")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A FieldEncoderFunc returns the string encoding of the value v of a field.
+type FieldEncoderFunc func(v reflect.Value) (string, error)
+
+var (
+	// fieldEncoders maps struct types to the Go paths of their fields with
+	// a FieldEncoderFunc registered by RegisterFieldEncoder.
+	fieldEncodersMu sync.RWMutex
+	fieldEncoders   map[reflect.Type]map[string]FieldEncoderFunc
+)
+
+// RegisterFieldEncoder registers fn to encode the field of the struct type T
+// at field, a Go field name or a path of them separated by dots for fields of
+// nested structs, instead of the default rendering.  This is lighter than
+// implementing Encoder on a wrapper type for a single field:
+//
+//	query.RegisterFieldEncoder[ListOptions]("Since", func(v reflect.Value) (string, error) {
+//		return v.Interface().(time.Time).Format("2006-01-02"), nil
+//	})
+//
+// fn is called with the value of the field, which may be a nil pointer, and
+// its result is the single value of the parameter.  Tag options such as
+// "omitempty" and validation rules apply before fn is called.  Errors are
+// returned as a *FieldError naming the parameter.  The registration applies
+// wherever a value of type T is encoded, including as a nested or embedded
+// struct.  A nil fn removes the registration.  RegisterFieldEncoder is meant
+// to be called from init functions.
+func RegisterFieldEncoder[T any](field string, fn FieldEncoderFunc) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("query: RegisterFieldEncoder() expects struct type. Got %v", t)
+	}
+	path, err := resolvePath(t, field)
+	if err != nil {
+		return fmt.Errorf("query: RegisterFieldEncoder() %v", err)
+	}
+
+	fieldEncodersMu.Lock()
+	defer fieldEncodersMu.Unlock()
+	if fn == nil {
+		delete(fieldEncoders[t], path)
+		if len(fieldEncoders[t]) == 0 {
+			delete(fieldEncoders, t)
+		}
+		return nil
+	}
+	if fieldEncoders == nil {
+		fieldEncoders = make(map[reflect.Type]map[string]FieldEncoderFunc)
+	}
+	if fieldEncoders[t] == nil {
+		fieldEncoders[t] = make(map[string]FieldEncoderFunc)
+	}
+	fieldEncoders[t][path] = fn
+	return nil
+}
+
+// resolvePath returns the full Go path of the field of the struct type t at
+// path, naming the embedded structs holding promoted fields.
+func resolvePath(t reflect.Type, path string) (string, error) {
+	st := t
+	var full []string
+	for _, n := range strings.Split(path, ".") {
+		if st.Kind() != reflect.Struct {
+			return "", fmt.Errorf("%v has no field %s", t, path)
+		}
+		sf, ok := st.FieldByName(n)
+		if !ok || sf.PkgPath != "" {
+			return "", fmt.Errorf("%v has no field %s", t, path)
+		}
+		full = append(full, goPath(st, sf.Index))
+		st = indirectType(sf.Type)
+	}
+	return strings.Join(full, "."), nil
+}
+
+// structFrame records a struct being encoded, whose fields start at depth in
+// the field path of the encoder.
+type structFrame struct {
+	typ   reflect.Type
+	depth int
+}
+
+// fieldEncoder returns the FieldEncoderFunc registered for the field being
+// encoded, relative to the outermost struct being encoded that has one.
+func (e *encoder) fieldEncoder() FieldEncoderFunc {
+	fieldEncodersMu.RLock()
+	defer fieldEncodersMu.RUnlock()
+	if len(fieldEncoders) == 0 {
+		return nil
+	}
+	for _, f := range e.structs {
+		if fn := fieldEncoders[f.typ][strings.Join(e.path[f.depth:], ".")]; fn != nil {
+			return fn
+		}
+	}
+	return nil
+}
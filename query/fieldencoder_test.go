@@ -0,0 +1,97 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type encodedInner struct {
+	Since time.Time `url:"since"`
+	Tags  []string  `url:"tags"`
+}
+
+type encodedPage struct {
+	Size int `url:"size"`
+}
+
+type encodedOuter struct {
+	encodedPage
+	Inner  encodedInner `url:"inner"`
+	Other  encodedInner `url:"other"`
+	Secret string       `url:"secret,redact"`
+}
+
+func TestRegisterFieldEncoder(t *testing.T) {
+	date := func(v reflect.Value) (string, error) {
+		return v.Interface().(time.Time).Format("2006-01-02"), nil
+	}
+	join := func(v reflect.Value) (string, error) {
+		return strings.Join(v.Interface().([]string), "|"), nil
+	}
+	size := func(v reflect.Value) (string, error) {
+		return "s" + strconv.FormatInt(v.Int(), 10), nil
+	}
+	for _, r := range []struct {
+		register func(string, FieldEncoderFunc) error
+		field    string
+		fn       FieldEncoderFunc
+	}{
+		{RegisterFieldEncoder[encodedInner], "Since", date},
+		{RegisterFieldEncoder[encodedOuter], "Inner.Tags", join},
+		{RegisterFieldEncoder[encodedOuter], "Size", size},
+	} {
+		if err := r.register(r.field, r.fn); err != nil {
+			t.Fatalf("RegisterFieldEncoder(%q) returned error: %v", r.field, err)
+		}
+		defer r.register(r.field, nil)
+	}
+
+	when := time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := encodedOuter{
+		encodedPage: encodedPage{Size: 3},
+		Inner:       encodedInner{Since: when, Tags: []string{"a", "b"}},
+		Other:       encodedInner{Since: when, Tags: []string{"a", "b"}},
+	}
+	got, err := EncodeString(v, WithKeyOrder(func(a, b string) bool { return a < b }))
+	want := "inner%5Bsince%5D=2013-01-02&inner%5Btags%5D=a%7Cb&other%5Bsince%5D=2013-01-02&other%5Btags%5D=a&other%5Btags%5D=b&secret=&size=s3"
+	if err != nil || got != want {
+		t.Errorf("EncodeString returned %q, %v, want %q", got, err, want)
+	}
+
+	if got, _ := EncodeString(encodedInner{Since: when}); got != "since=2013-01-02" {
+		t.Errorf("EncodeString of the registered type returned %q", got)
+	}
+}
+
+func TestRegisterFieldEncoder_Errors(t *testing.T) {
+	fail := func(v reflect.Value) (string, error) {
+		return "", errors.New("bad " + v.String())
+	}
+	if err := RegisterFieldEncoder[encodedOuter]("Secret", fail); err != nil {
+		t.Fatalf("RegisterFieldEncoder returned error: %v", err)
+	}
+	defer RegisterFieldEncoder[encodedOuter]("Secret", nil)
+
+	_, err := Values(encodedOuter{Secret: "hunter2"})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Name != "secret" || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Values returned error %v, want a redacted *FieldError for secret", err)
+	}
+
+	if err := RegisterFieldEncoder[int]("A", fail); err == nil {
+		t.Errorf("RegisterFieldEncoder[int] did not return an error")
+	}
+	for _, field := range []string{"Missing", "Inner.Missing", "Secret.Len", "encodedPage"} {
+		if err := RegisterFieldEncoder[encodedOuter](field, fail); err == nil {
+			t.Errorf("RegisterFieldEncoder(%q) did not return an error", field)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+var fieldMaskType = reflect.TypeOf(FieldMask(nil))
+
+// FieldMask selects the fields of a partial response by their dotted paths,
+// such as "b.c".  It is encoded in the partial response syntax of Google APIs,
+// where paths sharing a prefix are grouped in parentheses:
+//
+//	type GetOptions struct {
+//		Fields query.FieldMask `url:"fields,omitempty"`
+//	}
+//
+//	opt := GetOptions{Fields: query.FieldMask{"a", "b.c", "d.e", "d.f"}}
+//	// fields=a,b.c,d(e,f)
+//
+// With the "comma" option, the paths are instead joined by commas as they
+// are, as in "fields=a,b.c,d.e,d.f", which is the form expected for
+// google.protobuf.FieldMask parameters.  Both forms are accepted when
+// decoding.
+type FieldMask []string
+
+// String returns m in the partial response syntax.
+func (m FieldMask) String() string {
+	root := new(maskNode)
+	for _, p := range m {
+		n := root
+		for _, name := range strings.Split(p, ".") {
+			if n.all {
+				break
+			}
+			n = n.child(name)
+		}
+		n.all, n.children = true, nil
+	}
+	return root.list()
+}
+
+// EncodeValues implements Encoder.
+func (m FieldMask) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, m.String())
+	return nil
+}
+
+// DecodeValues implements Decoder.
+func (m *FieldMask) DecodeValues(key string, v url.Values) error {
+	*m = nil
+	for _, s := range v[key] {
+		paths, err := ParseFieldMask(s)
+		if err != nil {
+			return err
+		}
+		*m = append(*m, paths...)
+	}
+	return nil
+}
+
+// ParseFieldMask parses s, in either the partial response syntax or the comma
+// form, and returns the paths it selects.
+func ParseFieldMask(s string) (FieldMask, error) {
+	if s == "" {
+		return nil, nil
+	}
+	p := &maskParser{s: s}
+	m := p.list("")
+	if p.err == nil && p.pos < len(s) {
+		p.fail("unexpected %q", s[p.pos])
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return m, nil
+}
+
+// maskNode is a node of the tree of paths of a FieldMask.
+type maskNode struct {
+	name     string
+	all      bool // whole field selected
+	children []*maskNode
+}
+
+func (n *maskNode) child(name string) *maskNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &maskNode{name: name}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *maskNode) list() string {
+	parts := make([]string, len(n.children))
+	for i, c := range n.children {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (n *maskNode) String() string {
+	switch {
+	case len(n.children) == 1:
+		return n.name + "." + n.children[0].String()
+	case len(n.children) > 1:
+		return n.name + "(" + n.list() + ")"
+	}
+	return n.name
+}
+
+// maskParser parses the partial response syntax:
+//
+//	list = item *( "," item )
+//	item = name [ "." item / "(" list ")" ]
+type maskParser struct {
+	s   string
+	pos int
+	err error
+}
+
+func (p *maskParser) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf("query: invalid field mask %q at offset %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+// list parses a list of items and returns their paths, prefixed by prefix.
+func (p *maskParser) list(prefix string) []string {
+	var paths []string
+	for p.err == nil {
+		paths = append(paths, p.item(prefix)...)
+		if p.pos == len(p.s) || p.s[p.pos] != ',' {
+			break
+		}
+		p.pos++
+	}
+	return paths
+}
+
+func (p *maskParser) item(prefix string) []string {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(".,()", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.fail("missing field name")
+		return nil
+	}
+	path := prefix + p.s[start:p.pos]
+	if p.pos == len(p.s) {
+		return []string{path}
+	}
+	switch p.s[p.pos] {
+	case '.':
+		p.pos++
+		return p.item(path + ".")
+	case '(':
+		p.pos++
+		paths := p.list(path + ".")
+		if p.pos == len(p.s) || p.s[p.pos] != ')' {
+			p.fail("missing ')'")
+			return nil
+		}
+		p.pos++
+		return paths
+	}
+	return []string{path}
+}
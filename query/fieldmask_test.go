@@ -0,0 +1,90 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFieldMask(t *testing.T) {
+	tests := []struct {
+		mask FieldMask
+		want string
+	}{
+		{nil, ""},
+		{FieldMask{"a", "b"}, "a,b"},
+		{FieldMask{"a", "b.c", "d.e", "d.f"}, "a,b.c,d(e,f)"},
+		{FieldMask{"a.b.c", "a.b.d", "a.e"}, "a(b(c,d),e)"},
+		{FieldMask{"a.b", "a", "a.c"}, "a"},
+	}
+	for i, tt := range tests {
+		if got := tt.mask.String(); got != tt.want {
+			t.Errorf("%d. String(%q) returned %q, want %q", i, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestFieldMask_values(t *testing.T) {
+	type getOptions struct {
+		Fields FieldMask `url:"fields,omitempty"`
+		Mask   FieldMask `url:"mask,comma,omitempty"`
+	}
+
+	opt := getOptions{
+		Fields: FieldMask{"a", "b.c", "d.e", "d.f"},
+		Mask:   FieldMask{"a", "d.e", "d.f"},
+	}
+	v, err := Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	want := url.Values{"fields": {"a,b.c,d(e,f)"}, "mask": {"a,d.e,d.f"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+
+	var got getOptions
+	if err := Decode(v, &got); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(opt, got) {
+		t.Errorf("Decode(%v) decoded %v, want %v", v, got, opt)
+	}
+
+	if v, _ := Values(getOptions{}); len(v) != 0 {
+		t.Errorf("Values of empty masks returned %v, want no parameters", v)
+	}
+}
+
+func TestParseFieldMask(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FieldMask
+	}{
+		{"", nil},
+		{"a", FieldMask{"a"}},
+		{"a,b.c,d(e,f)", FieldMask{"a", "b.c", "d.e", "d.f"}},
+		{"a(b(c,d),e)", FieldMask{"a.b.c", "a.b.d", "a.e"}},
+		{"a.b(c,d.e)", FieldMask{"a.b.c", "a.b.d.e"}},
+	}
+	for i, tt := range tests {
+		got, err := ParseFieldMask(tt.in)
+		if err != nil {
+			t.Errorf("%d. ParseFieldMask(%q) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. ParseFieldMask(%q) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"a,", "a(b", "a()", "a)", ".a", "a..b"} {
+		if _, err := ParseFieldMask(in); err == nil {
+			t.Errorf("ParseFieldMask(%q) returned no error", in)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// ValuesOf is the type safe counterpart of Values.  The type T must be a
+// struct or a pointer to one; other types are reported by an error, without
+// looking at v.  Encoding is otherwise done by Values, which walks v by
+// reflection on each call: nothing is cached per type.
+func ValuesOf[T any](v T, opts ...Option) (url.Values, error) {
+	if err := checkStructType(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return nil, fmt.Errorf("query: ValuesOf() %v", err)
+	}
+	return Values(v, opts...)
+}
+
+// A Codec encodes and decodes values of the struct type T with fixed options.
+// It is created by For, which checks T once, so that its methods only fail
+// for bad values.
+type Codec[T any] struct {
+	opts []Option
+}
+
+// For returns a Codec for the type T, which must be a struct or a pointer to
+// one, with the options opts.  The url tags of T are verified with Check and
+// their problems returned, so For is best called from an init function or a
+// package-level variable declaration:
+//
+//	var listCodec = query.Must(query.For[ListOptions]())
+func For[T any](opts ...Option) (*Codec[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if err := checkStructType(t); err != nil {
+		return nil, fmt.Errorf("query: For() %v", err)
+	}
+	if err := Check(reflect.Zero(t).Interface(), opts...); err != nil {
+		return nil, err
+	}
+	return &Codec[T]{opts: append([]Option(nil), opts...)}, nil
+}
+
+// Must returns c, panicking if err is not nil.  It is meant for
+// package-level Codecs.
+func Must[T any](c *Codec[T], err error) *Codec[T] {
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Values encodes v like Values.
+func (c *Codec[T]) Values(v T) (url.Values, error) {
+	return Values(v, c.opts...)
+}
+
+// EncodeString encodes v like EncodeString.
+func (c *Codec[T]) EncodeString(v T) (string, error) {
+	return EncodeString(v, c.opts...)
+}
+
+// Decode decodes values into v like Decode.  If T is a pointer type, a nil
+// *v is set to a new struct first.
+func (c *Codec[T]) Decode(values url.Values, v *T) error {
+	rv := reflect.ValueOf(v).Elem()
+	if rv.Kind() != reflect.Ptr {
+		return Decode(values, v, c.opts...)
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.New(rv.Type().Elem()))
+	}
+	return Decode(values, rv.Interface(), c.opts...)
+}
+
+// checkStructType returns an error unless t is a struct type or a pointer to
+// one.
+func checkStructType(t reflect.Type) error {
+	if indirectType(t).Kind() != reflect.Struct {
+		return fmt.Errorf("expects struct type. Got %v", t)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type genericOptions struct {
+	Q    string `url:"q"`
+	Page int    `url:"page,omitempty"`
+}
+
+func TestValuesOf(t *testing.T) {
+	want := url.Values{"q": {"go"}, "page": {"2"}}
+	got, err := ValuesOf(genericOptions{"go", 2})
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ValuesOf returned %v, %v, want %v", got, err, want)
+	}
+	got, err = ValuesOf(&genericOptions{"go", 2})
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ValuesOf(pointer) returned %v, %v, want %v", got, err, want)
+	}
+	got, err = ValuesOf[*genericOptions](nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("ValuesOf(nil) returned %v, %v, want no values", got, err)
+	}
+
+	for i := 0; i < 2; i++ { // the second time from the cache
+		if _, err := ValuesOf(map[string]string{}); err == nil || !strings.Contains(err.Error(), "expects struct type") {
+			t.Errorf("ValuesOf(map) returned error %v, want a struct type error", err)
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	c, err := For[genericOptions](WithJSONTagFallback())
+	if err != nil {
+		t.Fatalf("For returned error: %v", err)
+	}
+	s, err := c.EncodeString(genericOptions{Q: "go"})
+	if err != nil || s != "q=go" {
+		t.Errorf("EncodeString returned %q, %v, want %q", s, err, "q=go")
+	}
+	var out genericOptions
+	if err := c.Decode(url.Values{"q": {"go"}, "page": {"3"}}, &out); err != nil || out != (genericOptions{"go", 3}) {
+		t.Errorf("Decode returned %v, %v", out, err)
+	}
+
+	pc := Must(For[*genericOptions]())
+	var p *genericOptions
+	if err := pc.Decode(url.Values{"q": {"go"}}, &p); err != nil || p == nil || p.Q != "go" {
+		t.Errorf("Decode into a nil pointer returned %v, %v", p, err)
+	}
+	if v, err := pc.Values(p); err != nil || v.Get("q") != "go" {
+		t.Errorf("Values returned %v, %v", v, err)
+	}
+
+	if _, err := For[int](); err == nil {
+		t.Errorf("expected For[int]() to return an error")
+	}
+	type bad struct {
+		A string `url:"a,bogus"`
+	}
+	if _, err := For[bad](); err == nil {
+		t.Errorf("expected For() to report bad tags")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected Must() to panic on error")
+		}
+	}()
+	Must(For[string]())
+}
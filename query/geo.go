@@ -0,0 +1,214 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	latLngType      = reflect.TypeOf(LatLng{})
+	boundingBoxType = reflect.TypeOf(BoundingBox{})
+)
+
+// LatLng is a geographic point, in degrees of latitude and longitude.  It is
+// encoded as a single value holding the latitude and longitude separated by
+// a comma, as in "52.52,13.405".  The "precision" option sets the number of
+// decimals, as in "precision=4", and the "lnglat" option puts the longitude
+// first, as done by GeoJSON and many tile services:
+//
+//	type SearchOptions struct {
+//		Near query.LatLng      `url:"near,precision=4"`
+//		In   query.BoundingBox `url:"bbox,omitempty,lnglat"`
+//	}
+//
+// Coordinates are never written with an exponent, and are not affected by
+// the options for other float values.
+//
+// Decode reads LatLng and BoundingBox fields back following the same
+// options.  Nil pointers are encoded as empty values, like other nil
+// pointers, and decoded back as nil.
+type LatLng struct {
+	Lat, Lng float64
+}
+
+// IsZero reports whether p is at latitude and longitude 0, so that the
+// "omitempty" option leaves it out.
+func (p LatLng) IsZero() bool {
+	return p == LatLng{}
+}
+
+// String returns p as "lat,lng".
+func (p LatLng) String() string {
+	return formatCoords(-1, p.Lat, p.Lng)
+}
+
+// EncodeValues implements Encoder.
+func (p LatLng) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, p.String())
+	return nil
+}
+
+// DecodeValues implements Decoder.  It accepts values as "lat,lng".  Fields
+// of type LatLng are decoded following their options instead.
+func (p *LatLng) DecodeValues(key string, v url.Values) error {
+	if _, ok := v[key]; !ok {
+		return nil
+	}
+	g, err := parseGeo(latLngType, v.Get(key), nil)
+	if err != nil {
+		return err
+	}
+	*p = g.(LatLng)
+	return nil
+}
+
+// BoundingBox is a geographic area between two corners.  It is encoded as a
+// single value holding the coordinates of its south-west corner followed by
+// those of its north-east corner, separated by commas, as in
+// "52.3,13.0,52.7,13.8".  The options of LatLng apply, the "lnglat" option
+// giving the "west,south,east,north" order of GeoJSON bounding boxes.
+type BoundingBox struct {
+	SouthWest, NorthEast LatLng
+}
+
+// IsZero reports whether both corners of b are zero, so that the
+// "omitempty" option leaves it out.
+func (b BoundingBox) IsZero() bool {
+	return b == BoundingBox{}
+}
+
+// String returns b as "south,west,north,east".
+func (b BoundingBox) String() string {
+	return formatCoords(-1, b.SouthWest.Lat, b.SouthWest.Lng, b.NorthEast.Lat, b.NorthEast.Lng)
+}
+
+// EncodeValues implements Encoder.
+func (b BoundingBox) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, b.String())
+	return nil
+}
+
+// DecodeValues implements Decoder.  It accepts values as
+// "south,west,north,east".  Fields of type BoundingBox are decoded following
+// their options instead.
+func (b *BoundingBox) DecodeValues(key string, v url.Values) error {
+	if _, ok := v[key]; !ok {
+		return nil
+	}
+	g, err := parseGeo(boundingBoxType, v.Get(key), nil)
+	if err != nil {
+		return err
+	}
+	*b = g.(BoundingBox)
+	return nil
+}
+
+// isGeoType reports whether t is one of the geographic types.
+func isGeoType(t reflect.Type) bool {
+	return t == latLngType || t == boundingBoxType
+}
+
+// geoString returns the encoding of v, a LatLng or a BoundingBox, following
+// the "precision" and "lnglat" options of opts.
+func geoString(v reflect.Value, opts tagOptions) (string, error) {
+	prec := -1
+	if p, ok := opts.Value("precision"); ok {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid precision %q", p)
+		}
+		prec = n
+	}
+
+	var points []LatLng
+	if v.Type() == latLngType {
+		points = []LatLng{v.Interface().(LatLng)}
+	} else {
+		b := v.Interface().(BoundingBox)
+		points = []LatLng{b.SouthWest, b.NorthEast}
+	}
+	var coords []float64
+	for _, p := range points {
+		if opts.Contains("lnglat") {
+			coords = append(coords, p.Lng, p.Lat)
+		} else {
+			coords = append(coords, p.Lat, p.Lng)
+		}
+	}
+	return formatCoords(prec, coords...), nil
+}
+
+// parseGeo parses s, the encoding of a LatLng or a BoundingBox of type t,
+// following the "lnglat" option of opts.
+func parseGeo(t reflect.Type, s string, opts tagOptions) (interface{}, error) {
+	n := 2
+	if t == boundingBoxType {
+		n = 4
+	}
+	c, err := parseCoords(s, n)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]LatLng, n/2)
+	for i := range points {
+		if opts.Contains("lnglat") {
+			points[i] = LatLng{c[2*i+1], c[2*i]}
+		} else {
+			points[i] = LatLng{c[2*i], c[2*i+1]}
+		}
+	}
+	if t == latLngType {
+		return points[0], nil
+	}
+	return BoundingBox{points[0], points[1]}, nil
+}
+
+// decodeGeo returns a func decoding the LatLng or BoundingBox of type t of
+// the field named name with the options opts, for decoder.decodeTyped.
+func (d *decoder) decodeGeo(t reflect.Type) func(string, tagOptions) (interface{}, bool, error) {
+	return func(name string, opts tagOptions) (interface{}, bool, error) {
+		vs, ok := d.values[name]
+		if !ok {
+			return nil, false, nil
+		}
+		if len(vs) == 0 || vs[0] == "" {
+			return nil, true, nil
+		}
+		g, err := parseGeo(t, vs[0], opts)
+		return g, true, err
+	}
+}
+
+// formatCoords returns coords separated by commas, with prec decimals, or as
+// many as needed if prec is negative.
+func formatCoords(prec int, coords ...float64) string {
+	s := make([]string, len(coords))
+	for i, c := range coords {
+		s[i] = strconv.FormatFloat(c, 'f', prec, 64)
+	}
+	return strings.Join(s, ",")
+}
+
+// parseCoords parses n coordinates separated by commas.
+func parseCoords(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d coordinates, got %q", n, s)
+	}
+	coords := make([]float64, n)
+	for i, p := range parts {
+		c, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		coords[i] = c
+	}
+	return coords, nil
+}
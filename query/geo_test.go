@@ -0,0 +1,113 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestGeo(t *testing.T) {
+	berlin := LatLng{52.52, 13.405}
+	box := BoundingBox{LatLng{52.3, 13.0000001}, LatLng{52.7, 13.8}}
+	type search struct {
+		Near  LatLng       `url:"near,precision=2"`
+		Stops []LatLng     `url:"stop,omitempty"`
+		In    *BoundingBox `url:"bbox,omitempty,lnglat"`
+		Box   BoundingBox  `url:"box,omitempty"`
+	}
+	tests := []struct {
+		in   search
+		want url.Values
+	}{
+		{search{}, url.Values{"near": {"0.00,0.00"}}},
+		{
+			search{Near: berlin, Stops: []LatLng{berlin, {-1, 0.5}}, In: &box, Box: box},
+			url.Values{
+				"near": {"52.52,13.40"},
+				"stop": {"52.52,13.405", "-1,0.5"},
+				"bbox": {"13.0000001,52.3,13.8,52.7"},
+				"box":  {"52.3,13.0000001,52.7,13.8"},
+			},
+		},
+	}
+	for i, tt := range tests {
+		got, err := Values(tt.in, WithDecimalSeparator(","))
+		if err != nil {
+			t.Errorf("%d. Values(%+v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%+v) returned %v, want %v", i, tt.in, got, tt.want)
+		}
+	}
+
+	if err := Check(search{}); err != nil {
+		t.Errorf("Check returned error: %v", err)
+	}
+	bad := struct {
+		A float64 `url:"a,precision=2"`
+		B LatLng  `url:"b,precision=x"`
+	}{}
+	if err := Check(bad); err == nil {
+		t.Errorf("Check did not report the bad precision options")
+	}
+}
+
+func TestGeo_Decode(t *testing.T) {
+	var out struct {
+		Near LatLng      `url:"near"`
+		Box  BoundingBox `url:"box"`
+	}
+	in := url.Values{"near": {"52.52, 13.405"}, "box": {"1,2,3,4"}}
+	if err := Decode(in, &out); err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if out.Near != (LatLng{52.52, 13.405}) || out.Box != (BoundingBox{LatLng{1, 2}, LatLng{3, 4}}) {
+		t.Errorf("Decode returned %+v", out)
+	}
+
+	for _, s := range []string{"1", "1,x", "1,2,3"} {
+		var p LatLng
+		if err := p.DecodeValues("p", url.Values{"p": {s}}); err == nil {
+			t.Errorf("DecodeValues(%q) did not return an error", s)
+		}
+	}
+	if got := out.Box.String(); got != "1,2,3,4" {
+		t.Errorf("String returned %q", got)
+	}
+}
+
+func TestGeo_roundTrip(t *testing.T) {
+	type search struct {
+		Near  LatLng       `url:"near,lnglat"`
+		At    *LatLng      `url:"at"`
+		In    BoundingBox  `url:"in,omitempty,lnglat,precision=2"`
+		Along []LatLng     `url:"along,lnglat"`
+		Area  *BoundingBox `url:"area,omitempty"`
+	}
+	tests := []search{
+		{},
+		{Near: LatLng{1, 2}},
+		{Near: LatLng{52.52, 13.405}, At: &LatLng{-1, -2}, In: BoundingBox{LatLng{1.25, 2.5}, LatLng{3, 4}}},
+		{Along: []LatLng{{1, 2}, {3, 4}}, Area: &BoundingBox{LatLng{1, 2}, LatLng{3, 4}}},
+	}
+	for i, in := range tests {
+		values, err := Values(in)
+		if err != nil {
+			t.Errorf("%d. Values(%+v) returned error: %v", i, in, err)
+			continue
+		}
+		var out search
+		if err := Decode(values, &out); err != nil {
+			t.Errorf("%d. Decode(%v) returned error: %v", i, values, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("%d. Decode(%v) decoded %+v, want %+v", i, values, out, in)
+		}
+	}
+}
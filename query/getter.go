@@ -0,0 +1,56 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithGetters makes Values encode unexported fields that have a matching
+// exported getter method, named after the field with its first letter in
+// upper case, as Page for page.  The getter must take no arguments and
+// return a single value, which is encoded following the tag of the field as
+// if it were the field's value.  This lets immutable option types keep their
+// fields private:
+//
+//	type ListOptions struct {
+//		page int `url:"page,omitempty"`
+//	}
+//
+//	func (o ListOptions) Page() int { return o.page }
+//
+// Getters with a pointer receiver are called on a copy of the struct if it
+// is not addressable.  Unexported fields without a getter are still left
+// out.
+func WithGetters() Option {
+	return func(c *config) {
+		c.getters = true
+	}
+}
+
+// getter returns the result of calling the getter of the unexported field sf
+// of the struct val, and whether the field has a getter.
+func (e *encoder) getter(val reflect.Value, sf reflect.StructField) (reflect.Value, bool) {
+	if !e.getters || !val.CanInterface() {
+		return reflect.Value{}, false
+	}
+	r, n := utf8.DecodeRuneInString(sf.Name)
+	name := string(unicode.ToUpper(r)) + sf.Name[n:]
+
+	var m reflect.Value
+	if val.CanAddr() {
+		m = val.Addr().MethodByName(name)
+	} else if m = val.MethodByName(name); !m.IsValid() {
+		p := reflect.New(val.Type())
+		p.Elem().Set(val)
+		m = p.MethodByName(name)
+	}
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+	return m.Call(nil)[0], true
+}
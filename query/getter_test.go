@@ -0,0 +1,50 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+type getterOptions struct {
+	page   int      `url:"page,omitempty"`
+	labels []string `url:"label,comma"`
+	sort   string
+	secret string `url:"secret"`
+	Q      string `url:"q"`
+}
+
+func (o getterOptions) Page() int { return o.page }
+
+func (o *getterOptions) Labels() []string { return o.labels }
+
+func (o getterOptions) Sort() string { return o.sort }
+
+// Secret is not a getter, as it takes an argument.
+func (o getterOptions) Secret(reveal bool) string { return o.secret }
+
+func TestWithGetters(t *testing.T) {
+	v := getterOptions{page: 2, labels: []string{"a", "b"}, sort: "asc", secret: "s", Q: "go"}
+	tests := []struct {
+		in   interface{}
+		opts []Option
+		want string
+	}{
+		{v, nil, "q=go"},
+		{v, []Option{WithGetters()}, "page=2&label=a%2Cb&sort=asc&q=go"},
+		{&v, []Option{WithGetters()}, "page=2&label=a%2Cb&sort=asc&q=go"},
+		{getterOptions{}, []Option{WithGetters()}, "label=&sort=&q="},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
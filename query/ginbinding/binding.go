@@ -0,0 +1,61 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ginbinding provides Gin bindings backed by the query package, so
+// that the structs used to encode client requests can also bind incoming
+// requests on the server, using the same url tags.
+//
+// The bindings implement the binding.Binding interface of
+// github.com/gin-gonic/gin/binding:
+//
+//	var opt Options
+//	if err := c.ShouldBindWith(&opt, ginbinding.Query); err != nil {
+//		c.AbortWithError(http.StatusBadRequest, err)
+//		return
+//	}
+package ginbinding
+
+import (
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+)
+
+var (
+	// Query binds the URL query of a request.
+	Query = Binding{}
+
+	// Form binds the form values of a request, which include both the
+	// URL query and, for POST, PUT and PATCH requests, an
+	// application/x-www-form-urlencoded body.
+	Form = Binding{form: true}
+)
+
+// Binding binds requests using query.Decode.
+type Binding struct {
+	// Options are passed to query.Decode.
+	Options []query.Option
+
+	form bool
+}
+
+// Name returns the name of the binding.
+func (b Binding) Name() string {
+	if b.form {
+		return "query-form"
+	}
+	return "query"
+}
+
+// Bind decodes the parameters of req into obj, which must be a pointer to a
+// struct.
+func (b Binding) Bind(req *http.Request, obj interface{}) error {
+	if !b.form {
+		return query.Decode(req.URL.Query(), obj, b.Options...)
+	}
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	return query.Decode(req.Form, obj, b.Options...)
+}
@@ -0,0 +1,67 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ginbinding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-querystring/query"
+)
+
+type options struct {
+	Query string   `url:"q"`
+	Page  int      `url:"page"`
+	Tags  []string `url:"tag,comma"`
+}
+
+func TestBinding(t *testing.T) {
+	tests := []struct {
+		binding Binding
+		req     *http.Request
+		want    options
+	}{
+		{
+			Query,
+			httptest.NewRequest("GET", "/?q=foo&page=2&tag=a,b", nil),
+			options{"foo", 2, []string{"a", "b"}},
+		},
+		{
+			Form,
+			formRequest("/?q=foo", "page=3&tag=c"),
+			options{"foo", 3, []string{"c"}},
+		},
+		{
+			Query,
+			formRequest("/?q=foo", "page=3"),
+			options{Query: "foo"},
+		},
+	}
+
+	for i, tt := range tests {
+		var got options
+		if err := tt.binding.Bind(tt.req, &got); err != nil {
+			t.Errorf("%d. %s.Bind(%v) returned error: %v", i, tt.binding.Name(), tt.req.URL, err)
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%d. %s.Bind(%v) bound %+v, want %+v", i, tt.binding.Name(), tt.req.URL, got, tt.want)
+		}
+	}
+
+	var got options
+	err := Query.Bind(httptest.NewRequest("GET", "/?page=x", nil), &got)
+	if _, ok := err.(*query.FieldError); !ok {
+		t.Errorf("Bind() returned error %v, want *query.FieldError", err)
+	}
+}
+
+func formRequest(target, body string) *http.Request {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
@@ -0,0 +1,45 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Headers returns the HTTP header encoding of v.  It follows the rules of
+// Values, except that names and options are read from "header" tags, and
+// only fields with a header tag are encoded.  Header names are canonicalized
+// with http.CanonicalHeaderKey.  For example:
+//
+//	type Options struct {
+//		Query      string    `url:"q"`
+//		RequestID  string    `header:"X-Request-Id,omitempty"`
+//		IfModified time.Time `header:"If-Modified-Since,omitempty"`
+//	}
+//
+// Since fields with a header tag but no url tag are skipped by Values, the
+// same struct can describe both the query and the headers of a request.
+func Headers(v interface{}, opts ...Option) (http.Header, error) {
+	values, err := companionValues("header", v, opts)
+	if err != nil {
+		return nil, err
+	}
+	h := make(http.Header, len(values))
+	for k, vs := range values {
+		k = http.CanonicalHeaderKey(k)
+		h[k] = append(h[k], vs...)
+	}
+	return h, nil
+}
+
+// companionValues encodes the fields of v tagged with the companion tag key.
+func companionValues(key string, v interface{}, opts []Option) (url.Values, error) {
+	e := &encoder{config: newConfig(opts)}
+	e.tagKey = key
+	e.requireTag = true
+	e.jsonFallback = false
+	return e.encode(v)
+}
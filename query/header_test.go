@@ -0,0 +1,53 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type headerOptions struct {
+	Query     string    `url:"q"`
+	RequestID string    `header:"x-request-id,omitempty"`
+	Since     time.Time `header:"If-Modified-Since,omitempty"`
+	Accept    []string  `header:"Accept"`
+	Debug     bool      `header:"X-Debug,int" url:"debug"`
+	Page      int
+}
+
+func TestHeaders(t *testing.T) {
+	opt := headerOptions{
+		Query:  "foo",
+		Since:  time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC),
+		Accept: []string{"text/html", "application/json"},
+		Debug:  true,
+		Page:   2,
+	}
+
+	h, err := Headers(opt)
+	if err != nil {
+		t.Fatalf("Headers(%v) returned error: %v", opt, err)
+	}
+	want := http.Header{
+		"If-Modified-Since": {"2000-01-01T12:34:56Z"},
+		"Accept":            {"text/html", "application/json"},
+		"X-Debug":           {"1"},
+	}
+	if !reflect.DeepEqual(want, h) {
+		t.Errorf("Headers(%v) returned %v, want %v", opt, h, want)
+	}
+
+	v, err := Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	if want := (url.Values{"q": {"foo"}, "debug": {"true"}, "Page": {"2"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+}
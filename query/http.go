@@ -0,0 +1,200 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SetRequestQuery encodes v using Values and installs the result as the query
+// of req.URL.  If merge is false, any existing query is replaced.  If merge is
+// true, the parameters of v are merged into the existing query: parameters
+// produced by v replace existing parameters of the same name, and all other
+// existing parameters are kept.
+func SetRequestQuery(req *http.Request, v interface{}, merge bool, opts ...Option) error {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return err
+	}
+	if merge {
+		values = mergeValues(req.URL.Query(), values)
+	}
+	req.URL.RawQuery = values.Encode()
+	return nil
+}
+
+// mergeValues sets each key of src in dst, replacing any values dst already
+// holds for that key, and returns dst.
+func mergeValues(dst, src url.Values) url.Values {
+	for k, vs := range src {
+		dst[k] = vs
+	}
+	return dst
+}
+
+// NewFormReader encodes v using Values and returns it as an
+// application/x-www-form-urlencoded request body, along with the matching
+// content type.  The form encoding is identical to that of a URL query, so the
+// same structs can describe both:
+//
+//	body, contentType, err := query.NewFormReader(opt)
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := http.Post(url, contentType, body)
+func NewFormReader(v interface{}, opts ...Option) (io.Reader, string, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return strings.NewReader(values.Encode()), "application/x-www-form-urlencoded", nil
+}
+
+// Transport is an http.RoundTripper that adds default query parameters,
+// encoded from option structs, to every request it sends.  It is useful for
+// parameters shared by all calls to an API, such as API keys, locales or
+// tenant identifiers.
+//
+// Parameters already present in a request's URL are left untouched, and
+// earlier entries of Defaults take precedence over later ones.
+type Transport struct {
+	// Base is the RoundTripper used to send requests.  If nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Defaults holds the option structs whose parameters are added.  They
+	// are encoded with Values for every request, so changes made through
+	// pointers are picked up by later requests.
+	Defaults []interface{}
+
+	// Options are passed to Values when encoding Defaults.
+	Options []Option
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	added := false
+	for _, d := range t.Defaults {
+		values, err := Values(d, t.Options...)
+		if err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+		for k, vs := range values {
+			if _, ok := q[k]; !ok {
+				q[k] = vs
+				added = true
+			}
+		}
+	}
+
+	// A RoundTripper must not modify the request, so work on a copy.
+	if added {
+		req = req.Clone(req.Context())
+		req.URL.RawQuery = q.Encode()
+	}
+	return t.base().RoundTrip(req)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// Get encodes opts into the query of rawurl with BuildURL, sends a GET
+// request with the given context using client, and decodes the JSON response
+// body into result.  If client is nil, http.DefaultClient is used.  If result
+// is nil, the response body is discarded.
+//
+// A response with a status code outside the 2xx range is reported as a
+// *ResponseError.
+func Get(ctx context.Context, client *http.Client, rawurl string, opts, result interface{}, options ...Option) error {
+	u, err := BuildURL(rawurl, opts, options...)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &ResponseError{Response: resp, Body: body}
+	}
+	if result == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// maxErrorBody is the maximum number of bytes of a response body kept in a
+// ResponseError.
+const maxErrorBody = 64 << 10
+
+// ResponseError reports an unsuccessful response to a request made by Get.
+type ResponseError struct {
+	Response *http.Response // response, whose body has been closed
+	Body     []byte         // start of the response body
+}
+
+// Error reports the status of the response and the URL requested, without
+// its query, which may hold values redacted elsewhere, nor any password.
+func (e *ResponseError) Error() string {
+	u := *e.Response.Request.URL
+	u.RawQuery, u.ForceQuery, u.Fragment, u.RawFragment = "", false, "", ""
+	return fmt.Sprintf("query: GET %s: %v", u.Redacted(), e.Response.Status)
+}
+
+// bindKey is the context key under which Bind stores the decoded T.
+type bindKey[T any] struct{}
+
+// Bind returns middleware that decodes the query of each incoming request
+// into a new T using Decode, and passes the request on to next with the
+// result stored in its context, from where it can be retrieved with
+// FromContext.  T must be a struct type.
+//
+// If the query cannot be decoded, Bind responds with status 400 Bad Request
+// listing the error of every failing parameter, and next is not called.
+func Bind[T any](next http.Handler, opts ...Option) http.Handler {
+	opts = append([]Option{WithCollectErrors()}, opts...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v T
+		if err := Decode(r.URL.Query(), &v, opts...); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ctx := context.WithValue(r.Context(), bindKey[T]{}, v)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// FromContext returns the T stored in ctx by Bind, and whether one was found.
+func FromContext[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(bindKey[T]{}).(T)
+	return v, ok
+}
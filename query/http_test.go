@@ -0,0 +1,239 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetRequestQuery(t *testing.T) {
+	opt := struct {
+		Query string `url:"q"`
+		Page  int    `url:"page"`
+	}{"foo", 2}
+
+	tests := []struct {
+		url   string
+		merge bool
+		want  string
+	}{
+		{"http://example.com/search", false, "page=2&q=foo"},
+		{"http://example.com/search?page=1&sort=asc", false, "page=2&q=foo"},
+		{"http://example.com/search?page=1&sort=asc", true, "page=2&q=foo&sort=asc"},
+	}
+
+	for i, tt := range tests {
+		req, err := http.NewRequest("GET", tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetRequestQuery(req, opt, tt.merge); err != nil {
+			t.Errorf("%d. SetRequestQuery(%q, %v, %v) returned error: %v", i, tt.url, opt, tt.merge, err)
+		}
+		if got := req.URL.RawQuery; got != tt.want {
+			t.Errorf("%d. SetRequestQuery(%q, %v, %v) set query %q, want %q", i, tt.url, opt, tt.merge, got, tt.want)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/?a=b", nil)
+	if err := SetRequestQuery(req, "", true); err == nil {
+		t.Errorf("expected SetRequestQuery() to return an error on invalid input")
+	}
+	if got := req.URL.RawQuery; got != "a=b" {
+		t.Errorf("SetRequestQuery() changed query to %q on error", got)
+	}
+}
+
+func TestNewFormReader(t *testing.T) {
+	opt := struct {
+		Name string   `url:"name"`
+		Tags []string `url:"tag"`
+	}{"a b", []string{"x", "y"}}
+
+	body, contentType, err := NewFormReader(opt)
+	if err != nil {
+		t.Fatalf("NewFormReader(%v) returned error: %v", opt, err)
+	}
+	if want := "application/x-www-form-urlencoded"; contentType != want {
+		t.Errorf("NewFormReader(%v) returned content type %q, want %q", opt, contentType, want)
+	}
+
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", contentType)
+	var got struct {
+		Name string   `url:"name"`
+		Tags []string `url:"tag"`
+	}
+	if err := req.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Decode(req.PostForm, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opt, got) {
+		t.Errorf("NewFormReader(%v) body decoded to %v", opt, got)
+	}
+
+	if _, _, err := NewFormReader(""); err == nil {
+		t.Errorf("expected NewFormReader() to return an error on invalid input")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.RawQuery)
+	}))
+	defer server.Close()
+
+	auth := &struct {
+		Key string `url:"api_key"`
+	}{"secret"}
+	locale := struct {
+		Locale string `url:"locale"`
+		Key    string `url:"api_key"`
+	}{"en", "other"}
+	client := &http.Client{Transport: &Transport{Defaults: []interface{}{auth, locale}}}
+
+	get := func(rawurl string) string {
+		req, err := http.NewRequest("GET", rawurl, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		orig := req.URL.RawQuery
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request for %q returned error: %v", rawurl, err)
+		}
+		defer resp.Body.Close()
+		if req.URL.RawQuery != orig {
+			t.Errorf("request query was modified to %q", req.URL.RawQuery)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "api_key=secret&locale=en"},
+		{"/?q=foo", "api_key=secret&locale=en&q=foo"},
+		{"/?locale=de", "api_key=secret&locale=de"},
+	}
+
+	for i, tt := range tests {
+		if got := get(server.URL + tt.path); got != tt.want {
+			t.Errorf("%d. request for %q sent query %q, want %q", i, tt.path, got, tt.want)
+		}
+	}
+
+	auth.Key = "changed"
+	if got, want := get(server.URL), "api_key=changed&locale=en"; got != want {
+		t.Errorf("request sent query %q, want %q", got, want)
+	}
+
+	client.Transport = &Transport{Defaults: []interface{}{""}}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("expected request to fail with invalid defaults")
+	}
+}
+
+func TestGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/items" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"query":"`+r.URL.RawQuery+`"}`)
+	}))
+	defer server.Close()
+
+	opt := struct {
+		Query string `url:"q"`
+		Page  int    `url:"page"`
+	}{"foo", 2}
+
+	var result struct {
+		Query string `json:"query"`
+	}
+	if err := Get(context.Background(), server.Client(), server.URL+"/items?sort=asc", opt, &result); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if want := "page=2&q=foo&sort=asc"; result.Query != want {
+		t.Errorf("Get() sent query %q, want %q", result.Query, want)
+	}
+
+	if err := Get(context.Background(), nil, server.URL+"/items", opt, nil); err != nil {
+		t.Errorf("Get() with nil result returned error: %v", err)
+	}
+
+	err := Get(context.Background(), nil, server.URL+"/missing", opt, &result)
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("Get() returned error %v, want *ResponseError", err)
+	}
+	if respErr.Response.StatusCode != http.StatusNotFound || string(respErr.Body) != "not found\n" {
+		t.Errorf("Get() returned %v with body %q", respErr, respErr.Body)
+	}
+	if want := "query: GET " + server.URL + "/missing: 404 Not Found"; respErr.Error() != want {
+		t.Errorf("Get() returned error %q, want %q", respErr.Error(), want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Get(ctx, nil, server.URL+"/items", opt, &result); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() with canceled context returned error %v", err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	type options struct {
+		Query string `url:"q"`
+		Page  int    `url:"page"`
+		Debug bool   `url:"debug"`
+	}
+
+	handler := Bind[options](http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		opt, ok := FromContext[options](r.Context())
+		if !ok {
+			t.Errorf("FromContext() found no options")
+		}
+		fmt.Fprintf(w, "%+v", opt)
+	}))
+
+	tests := []struct {
+		query string
+		code  int
+		want  string
+	}{
+		{"q=foo&page=2", http.StatusOK, "{Query:foo Page:2 Debug:false}"},
+		{"", http.StatusOK, "{Query: Page:0 Debug:false}"},
+		{"page=x&debug=maybe", http.StatusBadRequest, `query: parameter "page"`},
+	}
+
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/?"+tt.query, nil))
+		if w.Code != tt.code {
+			t.Errorf("%d. query %q returned status %d, want %d", i, tt.query, w.Code, tt.code)
+		}
+		if body := w.Body.String(); !strings.HasPrefix(body, tt.want) {
+			t.Errorf("%d. query %q returned %q, want %q", i, tt.query, body, tt.want)
+		}
+	}
+
+	if _, ok := FromContext[options](context.Background()); ok {
+		t.Errorf("FromContext() found options in empty context")
+	}
+}
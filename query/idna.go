@@ -0,0 +1,45 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// asciiHost converts the host of u, if it has non-ASCII characters, to its
+// ASCII form following the IDNA lookup profile of UTS #46, as browsers and
+// resolvers do: labels are mapped, normalized to NFC, validated and encoded
+// in punycode with the "xn--" prefix, as in "xn--bcher-kva.example" for
+// "bücher.example".  Left as is, such hosts would be percent-encoded by
+// url.URL.String, which no resolver understands.  Hosts that are not valid
+// internationalized domain names are reported as errors.
+func asciiHost(u *url.URL) error {
+	host, port := u.Hostname(), u.Port()
+	if isASCII(host) {
+		return nil
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return fmt.Errorf("query: invalid host %q: %v", host, err)
+	}
+	u.Host = ascii
+	if port != "" {
+		u.Host += ":" + port
+	}
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestASCIIHost(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"example.com", "example.com"},
+		{"bücher.example", "xn--bcher-kva.example"},
+		{"Bücher.example:8443", "xn--bcher-kva.example:8443"},
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		{"правительство.рф", "xn--80aealotwbjpid2k.xn--p1ai"},
+		{"ドメイン名例.jp", "xn--eckwd4c7cu47r2wf.jp"},
+		// sample from RFC 3492, section 7.1 (L)
+		{"3年b組金八先生.jp", "xn--3b-ww4c5e180e575a65lsy2b.jp"},
+		// ideographic full stop
+		{"bücher。example", "xn--bcher-kva.example"},
+		// "e" followed by a combining acute accent, normalized to NFC
+		{"éxample.com", "xn--xample-9ua.com"},
+	}
+	for i, tt := range tests {
+		u := &url.URL{Scheme: "https", Host: tt.in}
+		if err := asciiHost(u); err != nil {
+			t.Errorf("%d. asciiHost(%q) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if u.Host != tt.want {
+			t.Errorf("%d. asciiHost(%q) returned %q, want %q", i, tt.in, u.Host, tt.want)
+		}
+	}
+
+	for _, host := range []string{"-bücher.example", "a‍b.bücher.example", "xn--ü.example"} {
+		u := &url.URL{Scheme: "https", Host: host}
+		if err := asciiHost(u); err == nil {
+			t.Errorf("asciiHost(%q) returned %q, want error", host, u.Host)
+		}
+	}
+}
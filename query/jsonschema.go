@@ -0,0 +1,69 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-querystring/query/openapi"
+)
+
+// jsonSchemaDialect is the JSON Schema draft JSONSchema documents follow.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema document describing the query parameters
+// encoded from the struct type of v, which may be a struct or a (possibly nil)
+// pointer to one.  The schema describes an object with one property per
+// parameter name, as produced by Values with the same options, which is the
+// form request validators at API gateways check incoming queries against.
+//
+// Parameter values are described by the types they decode to, as done by
+// OpenAPIParams, so validators are expected to coerce the strings of the query
+// before validating them.  Nested structs are flattened into the names of
+// their parameters, such as "filter[score]".  Parameters of fields with the
+// "required" option are listed as required.
+func JSONSchema(v interface{}, opts ...Option) ([]byte, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, fmt.Errorf("query: JSONSchema() %v", err)
+	}
+
+	c := newConfig(opts)
+	s := &openapi.Schema{Type: "object", Properties: make(map[string]*openapi.Schema)}
+	c.addProperties(s, t, "", make(map[reflect.Type]bool))
+
+	return json.Marshal(struct {
+		Dialect string `json:"$schema"`
+		*openapi.Schema
+	}{jsonSchemaDialect, s})
+}
+
+// addProperties adds to s the schemas of the parameters encoded from the
+// fields of the struct type t, nested in scope.  visiting holds the struct
+// types being described, to stop at recursive types.
+func (c *config) addProperties(s *openapi.Schema, t reflect.Type, scope string, visiting map[reflect.Type]bool) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	c.walkFields(t, func(f typeField) {
+		name := c.scopedName(scope, f.name)
+		if nestedStructType(f.sf.Type) {
+			c.addProperties(s, indirectType(f.sf.Type), name, visiting)
+			return
+		}
+		if ft := indirectType(f.sf.Type); (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && f.opts.Contains("brackets") {
+			name += "[]"
+		}
+		s.Properties[name] = c.schemaFor(f.sf.Type, f.opts, f.sf.Tag.Get("enum"), visiting)
+		if f.opts.Contains("required") {
+			s.Required = append(s.Required, name)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type Filter struct {
+		Score float64 `url:"score,required"`
+		Next  *Filter `url:"next"`
+	}
+	type Options struct {
+		Query  string   `url:"q,required"`
+		Sort   string   `url:"sort" enum:"asc,desc"`
+		Tags   []string `url:"tags,brackets"`
+		Filter Filter   `url:"filter"`
+		ID     int      `path:"id"`
+	}
+
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{
+			nil,
+			`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+				`"filter[score]":{"type":"number","format":"double"},` +
+				`"q":{"type":"string"},` +
+				`"sort":{"type":"string","enum":["asc","desc"]},` +
+				`"tags[]":{"type":"array","items":{"type":"string"}}},` +
+				`"required":["q","filter[score]"]}`,
+		},
+		{
+			[]Option{WithGorillaSchema()},
+			`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+				`"Filter.Score":{"type":"number","format":"double"},` +
+				`"Query":{"type":"string"},` +
+				`"Sort":{"type":"string","enum":["asc","desc"]},` +
+				`"Tags":{"type":"array","items":{"type":"string"}}}}`,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := JSONSchema(Options{}, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. JSONSchema() returned error: %v", i, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%d. JSONSchema() returned\n%s\nwant\n%s", i, got, tt.want)
+		}
+	}
+}
+
+func TestJSONSchema_invalidInput(t *testing.T) {
+	if _, err := JSONSchema(nil); err == nil {
+		t.Errorf("expected JSONSchema() to return an error on invalid input")
+	}
+}
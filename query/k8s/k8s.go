@@ -0,0 +1,233 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package k8s provides label and field selectors, and the option struct of
+// list requests, for Kubernetes style APIs, to be encoded by the query
+// package:
+//
+//	opt := k8s.ListOptions{
+//		LabelSelector: k8s.Selector{
+//			k8s.Eq("app", "web"),
+//			k8s.In("tier", "frontend", "cache"),
+//			k8s.NotExists("canary"),
+//		},
+//		FieldSelector: k8s.Selector{k8s.Ne("status.phase", "Running")},
+//		Limit:         50,
+//	}
+//	v, _ := query.Values(opt)
+//	// labelSelector: app=web,tier in (frontend,cache),!canary
+//	// fieldSelector: status.phase!=Running, limit: 50
+package k8s
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ListOptions holds the query parameters of list and watch requests.  Zero
+// fields are left out.
+type ListOptions struct {
+	LabelSelector        Selector `url:"labelSelector,omitempty"`
+	FieldSelector        Selector `url:"fieldSelector,omitempty"`
+	Watch                bool     `url:"watch,omitempty"`
+	AllowWatchBookmarks  bool     `url:"allowWatchBookmarks,omitempty"`
+	ResourceVersion      string   `url:"resourceVersion,omitempty"`
+	ResourceVersionMatch string   `url:"resourceVersionMatch,omitempty"` // "Exact" or "NotOlderThan"
+	TimeoutSeconds       *int64   `url:"timeoutSeconds,omitempty"`
+	Limit                int64    `url:"limit,omitempty"`
+	Continue             string   `url:"continue,omitempty"`
+}
+
+// Operators of requirements.  Field selectors only support Equals and
+// NotEquals.
+const (
+	Equals       = "="
+	NotEquals    = "!="
+	InSet        = "in"
+	NotInSet     = "notin"
+	Exists       = "exists"
+	DoesNotExist = "!"
+)
+
+// A Requirement is a condition on the value of a label or field.
+type Requirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// Eq requires key to have the value value.
+func Eq(key, value string) Requirement {
+	return Requirement{key, Equals, []string{value}}
+}
+
+// Ne requires key not to have the value value.  Labels that are not set
+// match too.
+func Ne(key, value string) Requirement {
+	return Requirement{key, NotEquals, []string{value}}
+}
+
+// In requires key to have one of values.
+func In(key string, values ...string) Requirement {
+	return Requirement{key, InSet, values}
+}
+
+// NotIn requires key to have none of values.  Labels that are not set match
+// too.
+func NotIn(key string, values ...string) Requirement {
+	return Requirement{key, NotInSet, values}
+}
+
+// Has requires the label key to be set.
+func Has(key string) Requirement {
+	return Requirement{key, Exists, nil}
+}
+
+// NotExists requires the label key not to be set.
+func NotExists(key string) Requirement {
+	return Requirement{key, DoesNotExist, nil}
+}
+
+// String returns r in selector syntax.  The characters '\', ',' and '=' are
+// escaped with a backslash in values, as field selectors expect.
+func (r Requirement) String() string {
+	values := make([]string, len(r.Values))
+	for i, v := range r.Values {
+		values[i] = escaper.Replace(v)
+	}
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case InSet, NotInSet:
+		return r.Key + " " + r.Operator + " (" + strings.Join(values, ",") + ")"
+	}
+	return r.Key + r.Operator + strings.Join(values, ",")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=`, `\=`)
+
+// A Selector selects objects matching all of its requirements.
+type Selector []Requirement
+
+// String returns s in selector syntax, as in "app=web,tier in (a,b)".
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// EncodeValues implements query.Encoder.
+func (s Selector) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, s.String())
+	return nil
+}
+
+// DecodeValues implements query.Decoder.
+func (s *Selector) DecodeValues(key string, v url.Values) error {
+	sel, err := Parse(v.Get(key))
+	if err != nil {
+		return err
+	}
+	*s = sel
+	return nil
+}
+
+// Parse parses a label or field selector.  The "==" operator is accepted as
+// a synonym of "=".
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range splitRequirements(s) {
+		r, err := parseRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("k8s: invalid selector %q: %v", s, err)
+		}
+		sel = append(sel, r)
+	}
+	return sel, nil
+}
+
+// splitRequirements splits s at the commas that are neither escaped nor
+// within parentheses.
+func splitRequirements(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseRequirement(s string) (Requirement, error) {
+	if s == "" {
+		return Requirement{}, fmt.Errorf("empty requirement")
+	}
+	if strings.HasPrefix(s, "!") {
+		return NotExists(strings.TrimSpace(s[1:])), nil
+	}
+	for _, op := range []string{" notin ", " in "} {
+		if i := strings.Index(s, op); i >= 0 {
+			list := strings.TrimSpace(s[i+len(op):])
+			if !strings.HasPrefix(list, "(") || !strings.HasSuffix(list, ")") {
+				return Requirement{}, fmt.Errorf("missing parentheses in %q", s)
+			}
+			var values []string
+			for _, v := range splitRequirements(list[1 : len(list)-1]) {
+				values = append(values, unescape(strings.TrimSpace(v)))
+			}
+			return Requirement{strings.TrimSpace(s[:i]), strings.TrimSpace(op), values}, nil
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '!', '=':
+			key, op, value := s[:i], Equals, s[i+1:]
+			if s[i] == '!' {
+				if !strings.HasPrefix(value, "=") {
+					return Requirement{}, fmt.Errorf("unknown operator in %q", s)
+				}
+				op = NotEquals
+			}
+			value = strings.TrimPrefix(value, "=")
+			return Requirement{strings.TrimSpace(key), op, []string{unescape(strings.TrimSpace(value))}}, nil
+		}
+	}
+	return Has(s), nil
+}
+
+// unescape reverses the escaping done by Requirement.String.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package k8s
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		sel  Selector
+		want string
+	}{
+		{nil, ""},
+		{Selector{Eq("app", "web")}, "app=web"},
+		{Selector{Ne("status.phase", "Running"), Has("tier")}, "status.phase!=Running,tier"},
+		{Selector{In("tier", "frontend", "cache"), NotIn("env", "dev")}, "tier in (frontend,cache),env notin (dev)"},
+		{Selector{NotExists("canary")}, "!canary"},
+		{Selector{Eq("metadata.name", `a,b=c\d`)}, `metadata.name=a\,b\=c\\d`},
+	}
+	for i, tt := range tests {
+		if got := tt.sel.String(); got != tt.want {
+			t.Errorf("%d. String() returned %q, want %q", i, got, tt.want)
+		}
+		if tt.sel == nil {
+			continue
+		}
+		got, err := Parse(tt.want)
+		if err != nil {
+			t.Errorf("%d. Parse(%q) returned error: %v", i, tt.want, err)
+		} else if !reflect.DeepEqual(got, tt.sel) {
+			t.Errorf("%d. Parse(%q) returned %v, want %v", i, tt.want, got, tt.sel)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	got, err := Parse("app == web, tier in ( a , b )")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	want := Selector{Eq("app", "web"), In("tier", "a", "b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse returned %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"a,", "a in b", "a!b"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) returned no error", s)
+		}
+	}
+}
+
+func TestListOptions(t *testing.T) {
+	opt := ListOptions{
+		LabelSelector: Selector{Eq("app", "web"), In("tier", "frontend", "cache")},
+		FieldSelector: Selector{Ne("status.phase", "Running")},
+		Limit:         50,
+	}
+	v, err := query.Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	want := url.Values{
+		"labelSelector": {"app=web,tier in (frontend,cache)"},
+		"fieldSelector": {"status.phase!=Running"},
+		"limit":         {"50"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+
+	var got ListOptions
+	if err := query.Decode(v, &got); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(opt, got) {
+		t.Errorf("Decode(%v) decoded %v, want %v", v, got, opt)
+	}
+}
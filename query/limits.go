@@ -0,0 +1,152 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// WithMaxLength makes Values, and the other encoding functions, return a
+// *LengthError if the query string encoded from a struct is longer than n
+// bytes, as written by EncodeString without a leading "?".  Servers and
+// proxies commonly reject or truncate URLs longer than 2048 or 8192 bytes, so
+// n should leave room for the rest of the URL.  A limit of 0, the default,
+// means no limit.
+func WithMaxLength(n int) Option {
+	return func(c *config) {
+		c.maxLength = n
+	}
+}
+
+// WithMaxParams makes Values, and the other encoding functions, return an
+// error if more than n parameter values are encoded from a struct, counting
+// each value of a repeated parameter.  Some gateways and firewalls drop
+// requests with too many parameters without explanation.  A limit of 0, the
+// default, means no limit.
+func WithMaxParams(n int) Option {
+	return func(c *config) {
+		c.maxParams = n
+	}
+}
+
+// WithMaxRepeats makes Values, and the other encoding functions, return a
+// *FieldError naming the parameter if more than n values are encoded for a
+// single parameter name.  A limit of 0, the default, means no limit.
+func WithMaxRepeats(n int) Option {
+	return func(c *config) {
+		c.maxRepeats = n
+	}
+}
+
+// WithReservedKeys makes Values, and the other encoding functions, return a
+// *FieldError if a field produces one of the parameter names keys, such as
+// "signature" or "timestamp" when those are added by a request signing step.
+// The error names the field at fault.  Custom Encoders are checked as well.
+func WithReservedKeys(keys ...string) Option {
+	return func(c *config) {
+		if c.reserved == nil {
+			c.reserved = make(map[string]bool)
+		}
+		for _, k := range keys {
+			c.reserved[k] = true
+		}
+	}
+}
+
+// checkReserved returns an error for the first reserved parameter of values.
+func (e *encoder) checkReserved(values url.Values) error {
+	e.recordAdded(values)
+	for _, k := range e.keys {
+		if e.reserved[k] && len(values[k]) > 0 {
+			err := fmt.Errorf("reserved parameter produced by field %s", e.keyFields[k])
+			return &FieldError{Name: k, Err: err}
+		}
+	}
+	return nil
+}
+
+// checkCounts returns an error if values holds more values than e.maxParams
+// or more values for a single parameter than e.maxRepeats.
+func (e *encoder) checkCounts(values url.Values) error {
+	if n := countPairs(values); e.maxParams > 0 && n > e.maxParams {
+		return fmt.Errorf("query: encoded query has %d parameters, more than the maximum of %d", n, e.maxParams)
+	}
+	if e.maxRepeats > 0 {
+		e.recordAdded(values)
+		for _, k := range e.keys {
+			if n := len(values[k]); n > e.maxRepeats {
+				return &FieldError{Name: k, Err: fmt.Errorf("%d values, more than the maximum of %d", n, e.maxRepeats)}
+			}
+		}
+	}
+	return nil
+}
+
+// A LengthError reports a query string longer than the limit set with
+// WithMaxLength.  It names the field contributing the most bytes, which is
+// usually the one to shorten or move to a request body.
+type LengthError struct {
+	Length, Max int
+
+	// Field is the path of the Go field contributing the most bytes, as in
+	// "Filter.IDs", and FieldLength the number of bytes of its
+	// parameters, including their names and separators.
+	Field       string
+	FieldLength int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("query: encoded query is %d bytes, more than the maximum of %d; field %s contributes %d bytes",
+		e.Length, e.Max, e.Field, e.FieldLength)
+}
+
+// checkLength returns a *LengthError if the query string of values is longer
+// than e.maxLength.
+func (e *encoder) checkLength(values url.Values) error {
+	escape := e.escape
+	if escape == nil {
+		escape = url.QueryEscape
+	}
+
+	length := 0
+	lengths := make(map[string]int)
+	for i, p := range e.pairs(values) {
+		n := len(escape(p.Key))
+		if p.Value != "" || !e.bareEmpty {
+			n += 1 + len(escape(p.Value))
+		}
+		if i > 0 {
+			n++ // separator
+		}
+		length += n
+		lengths[e.keyFields[p.Key]] += n
+	}
+	if length <= e.maxLength {
+		return nil
+	}
+
+	// Report the longest field, the first in name order on ties, so that
+	// errors are deterministic.
+	fields := make([]string, 0, len(lengths))
+	for f := range lengths {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	err := &LengthError{Length: length, Max: e.maxLength}
+	for _, f := range fields {
+		if lengths[f] > err.FieldLength {
+			err.Field, err.FieldLength = f, lengths[f]
+		}
+	}
+	return err
+}
+
+// constrainsQuery reports whether c checks, changes or reorders the encoded
+// query as a whole, once all its fields are encoded.
+func (c *config) constrainsQuery() bool {
+	return c.maxLength > 0 || c.maxParams > 0 || c.maxRepeats > 0 || len(c.reserved) > 0 || c.keyLess != nil || c.dedupe || len(c.overrides) > 0
+}
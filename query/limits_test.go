@@ -0,0 +1,102 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValues_maxLength(t *testing.T) {
+	type Filter struct {
+		IDs []string `url:"id"`
+	}
+	type Options struct {
+		Q      string `url:"q"`
+		Filter Filter `url:"f"`
+	}
+	in := Options{Q: "a b", Filter: Filter{IDs: []string{"123", "456"}}}
+
+	// q=a+b&f%5Bid%5D=123&f%5Bid%5D=456
+	if s, err := EncodeString(in); err != nil || len(s) != 33 {
+		t.Fatalf("EncodeString(%v) returned %q, %v, want 33 bytes", in, s, err)
+	}
+
+	if _, err := Values(in, WithMaxLength(33)); err != nil {
+		t.Errorf("Values(%v) with a max length of 33 returned error: %v", in, err)
+	}
+
+	_, err := Values(in, WithMaxLength(32))
+	want := &LengthError{Length: 33, Max: 32, Field: "Filter.IDs", FieldLength: 28}
+	var got *LengthError
+	if !errors.As(err, &got) || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Values(%v) with a max length of 32 returned error %v, want %v", in, err, want)
+	}
+	if !strings.Contains(err.Error(), "field Filter.IDs contributes 28 bytes") {
+		t.Errorf("LengthError.Error() returned %q, want the field named", err)
+	}
+}
+
+func TestValues_maxParams(t *testing.T) {
+	in := struct {
+		Q    string   `url:"q"`
+		Tags []string `url:"tag"`
+		IDs  []int    `url:"id"`
+	}{"go", []string{"a", "b", "c"}, []int{1, 2}}
+
+	tests := []struct {
+		opts []Option
+		want string // substring of the expected error, or "" for none
+	}{
+		{nil, ""},
+		{[]Option{WithMaxParams(6)}, ""},
+		{[]Option{WithMaxParams(5)}, "encoded query has 6 parameters, more than the maximum of 5"},
+		{[]Option{WithMaxRepeats(3)}, ""},
+		{[]Option{WithMaxRepeats(2)}, `parameter "tag": 3 values, more than the maximum of 2`},
+		{[]Option{WithMaxRepeats(1)}, `parameter "tag": 3 values`},
+	}
+
+	for i, tt := range tests {
+		_, err := Values(in, tt.opts...)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%d. Values(%v) returned error: %v", i, in, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%d. Values(%v) returned error %v, want %q", i, in, err, tt.want)
+		}
+	}
+}
+
+func TestValues_reservedKeys(t *testing.T) {
+	type Options struct {
+		Q    string      `url:"q"`
+		Sig  string      `url:"signature,omitempty"`
+		Args EncodedArgs `url:"args"`
+	}
+	opt := WithReservedKeys("signature", "timestamp", "args.1")
+
+	tests := []struct {
+		in   Options
+		want string // the expected error, or "" for none
+	}{
+		{Options{Q: "go"}, ""},
+		{Options{Q: "go", Args: EncodedArgs{"a"}}, ""},
+		{Options{Q: "go", Sig: "x"}, `query: parameter "signature": reserved parameter produced by field Sig`},
+		{Options{Args: EncodedArgs{"a", "b"}}, `query: parameter "args.1": reserved parameter produced by field Args`},
+	}
+
+	for i, tt := range tests {
+		_, err := Values(tt.in, opt)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%d. Values(%v) returned error %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
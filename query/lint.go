@@ -0,0 +1,94 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Warning is a questionable use of url tags reported by Lint.
+type Warning struct {
+	Field   string // path of the field in Go, as in "ListOptions.Filter"
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Field + ": " + w.Message
+}
+
+// Lint reports questionable uses of url tags in the struct type of v, which
+// may be a struct or a (possibly nil) pointer to one.  Unlike the problems
+// reported by Check, they do not prevent encoding, but often produce queries
+// other than intended:
+//
+//   - fields of embedded structs shadowed by a field of the same name in the
+//     embedding struct, which Values still encodes, unlike encoding/json
+//   - the "omitempty" option on bools, which makes false impossible to send;
+//     a *bool can send it
+//   - the "omitempty" option on fields with the "required" option
+//   - delimiter options, such as "comma", on fields that are not slices or
+//     arrays, which have no effect
+//
+// Lint is meant to be called from tests, so that client libraries can fail
+// their CI on such smells.  It returns nil if there is nothing to report.
+func Lint(v interface{}, opts ...Option) []Warning {
+	t, err := structType(v)
+	if err != nil {
+		return []Warning{{Field: fmt.Sprint(reflect.TypeOf(v)), Message: err.Error()}}
+	}
+	var warnings []Warning
+	newConfig(opts).lint(&warnings, t, t.Name(), make(map[reflect.Type]bool))
+	return warnings
+}
+
+// lint appends the warnings about the fields of the struct type t, named path
+// in Go, to warnings.
+func (c *config) lint(warnings *[]Warning, t reflect.Type, path string, visiting map[reflect.Type]bool) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	warn := func(field, format string, args ...interface{}) {
+		*warnings = append(*warnings, Warning{field, fmt.Sprintf(format, args...)})
+	}
+
+	// own holds the names of the fields declared by t itself
+	own := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); !sf.Anonymous {
+			own[sf.Name] = true
+		}
+	}
+
+	c.walkFields(t, func(f typeField) {
+		field := path + "." + goPath(t, f.index)
+		if len(f.index) > 1 && own[f.sf.Name] {
+			warn(field, "shadowed by field %s.%s, but encoded as well", path, f.sf.Name)
+		}
+
+		ft := indirectType(f.sf.Type)
+		omitempty := f.opts.Contains("omitempty")
+		if omitempty && f.sf.Type.Kind() == reflect.Bool {
+			warn(field, "option omitempty on a bool never sends false; use a *bool to send it")
+		}
+		if omitempty && f.opts.Contains("required") {
+			warn(field, "option omitempty leaves out a required parameter when empty")
+		}
+		if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array && !f.sf.Type.Implements(encoderType) {
+			for _, o := range delimiterOptions {
+				if f.opts.Contains(o) {
+					warn(field, "option %s has no effect on %v", o, f.sf.Type)
+				}
+			}
+		}
+
+		if nestedStructType(f.sf.Type) {
+			c.lint(warnings, ft, field, visiting)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+type lintEmbedded struct {
+	Page int    `url:"p"`
+	Sort string `url:"sort"`
+}
+
+type lintOptions struct {
+	lintEmbedded
+	Page   int    `url:"page"`
+	All    bool   `url:"all,omitempty"`
+	Debug  *bool  `url:"debug,omitempty"`
+	Q      string `url:"q,required,omitempty"`
+	Tag    string `url:"tag,comma"`
+	Tags   []int  `url:"tags,comma"`
+	Nested struct {
+		Flag bool `url:"flag,omitempty"`
+	} `url:"nested"`
+}
+
+func TestLint(t *testing.T) {
+	got := Lint(lintOptions{})
+	want := []Warning{
+		{"lintOptions.All", "option omitempty on a bool never sends false; use a *bool to send it"},
+		{"lintOptions.Q", "option omitempty leaves out a required parameter when empty"},
+		{"lintOptions.Tag", "option comma has no effect on string"},
+		{"lintOptions.Nested.Flag", "option omitempty on a bool never sends false; use a *bool to send it"},
+		{"lintOptions.lintEmbedded.Page", "shadowed by field lintOptions.Page, but encoded as well"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Lint returned\n%v, want\n%v", got, want)
+	}
+
+	if got := Lint((*recursive)(nil)); got != nil {
+		t.Errorf("Lint returned %v, want nil", got)
+	}
+	if got := Lint(""); len(got) != 1 {
+		t.Errorf("Lint of invalid input returned %v, want one warning", got)
+	}
+}
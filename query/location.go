@@ -0,0 +1,69 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"sync"
+	"time"
+)
+
+// WithTimeLocation makes Values convert time.Time values to loc before
+// formatting them, as many APIs require UTC timestamps regardless of the
+// location of the times held by callers.  The "utc" and "tz" tag options
+// take precedence over it for their fields.  Unix times are unaffected.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(c *config) {
+		c.timeLocation = loc
+	}
+}
+
+// dateLayout is the layout of time.Time values encoded as dates.
+const dateLayout = "2006-01-02"
+
+// WithDateOnly makes Values format time.Time values as dates, as in
+// "2006-01-02", as required by many reporting and analytics APIs that
+// reject full timestamps.  The date is taken in the location selected by
+// WithTimeLocation and the "utc" and "tz" tag options.  The "dateonly" tag
+// option does the same for a single field.  Unix times are unaffected.
+func WithDateOnly() Option {
+	return func(c *config) {
+		c.dateOnly = true
+	}
+}
+
+// locations caches the locations of tz tag options.
+var locations sync.Map // map[string]*time.Location
+
+// loadLocation is like time.LoadLocation, caching its results.
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// timeIn returns t in the location selected by the "utc" and "tz" options of
+// opts, or by WithTimeLocation.
+func (c *config) timeIn(t time.Time, opts tagOptions) (time.Time, error) {
+	if opts.Contains("utc") {
+		return t.UTC(), nil
+	}
+	if name, ok := opts.Value("tz"); ok {
+		loc, err := loadLocation(name)
+		if err != nil {
+			return t, err
+		}
+		return t.In(loc), nil
+	}
+	if c.timeLocation != nil {
+		return t.In(c.timeLocation), nil
+	}
+	return t, nil
+}
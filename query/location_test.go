@@ -0,0 +1,108 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeLocation(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	when := time.Date(2013, 1, 2, 3, 4, 5, 0, paris)
+	type times struct {
+		A time.Time   `url:"a"`
+		B time.Time   `url:"b,utc"`
+		C []time.Time `url:"c,tz=Asia/Tokyo"`
+		D time.Time   `url:"d,unix"`
+	}
+	in := times{when, when, []time.Time{when}, when}
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{
+			nil,
+			"a=2013-01-02T03%3A04%3A05%2B01%3A00&b=2013-01-02T02%3A04%3A05Z&c=2013-01-02T11%3A04%3A05%2B09%3A00&d=1357092245",
+		},
+		{
+			[]Option{WithTimeLocation(time.UTC)},
+			"a=2013-01-02T02%3A04%3A05Z&b=2013-01-02T02%3A04%3A05Z&c=2013-01-02T11%3A04%3A05%2B09%3A00&d=1357092245",
+		},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString returned error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString returned %q, want %q", i, got, tt.want)
+		}
+	}
+
+	_, err := Values(struct {
+		A time.Time `url:"a,tz=Nowhere/Special"`
+	}{when})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Name != "a" {
+		t.Errorf("Values with an unknown location returned error %v, want a *FieldError for a", err)
+	}
+
+	_, err = Values(struct {
+		A time.Time `url:"a,unix,tz=Nowhere/Bogus"`
+	}{when})
+	if !errors.As(err, &fe) || fe.Name != "a" {
+		t.Errorf("Values with a unix time and an unknown location returned error %v, want a *FieldError for a", err)
+	}
+}
+
+func TestDateOnly(t *testing.T) {
+	when := time.Date(2013, 1, 2, 23, 4, 5, 0, time.UTC)
+	type report struct {
+		From time.Time  `url:"from,dateonly"`
+		To   time.Time  `url:"to,dateonly,tz=Asia/Tokyo"`
+		At   *time.Time `url:"at,omitempty"`
+	}
+	tests := []struct {
+		in   report
+		opts []Option
+		want string
+	}{
+		{report{From: when, To: when}, nil, "from=2013-01-02&to=2013-01-03"},
+		{report{From: when, To: when, At: &when}, nil, "from=2013-01-02&to=2013-01-03&at=2013-01-02T23%3A04%3A05Z"},
+		{report{From: when, To: when, At: &when}, []Option{WithDateOnly()}, "from=2013-01-02&to=2013-01-03&at=2013-01-02"},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString returned error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString returned %q, want %q", i, got, tt.want)
+		}
+	}
+
+	var out report
+	if err := Decode(map[string][]string{"from": {"2013-01-02"}}, &out); err != nil || !out.From.Equal(time.Date(2013, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Decode returned %v, %v", out.From, err)
+	}
+
+	// WithDateOnly applies to decoding as well
+	in := report{From: when, To: when, At: &when}
+	values, err := Values(in, WithDateOnly())
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	out = report{}
+	if err := Decode(values, &out, WithDateOnly()); err != nil {
+		t.Fatalf("Decode(%v, WithDateOnly()) returned error: %v", values, err)
+	}
+	if want := time.Date(2013, 1, 2, 0, 0, 0, 0, time.UTC); out.At == nil || !out.At.Equal(want) {
+		t.Errorf("Decode(%v, WithDateOnly()) decoded at as %v, want %v", values, out.At, want)
+	}
+}
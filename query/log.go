@@ -0,0 +1,81 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"path"
+	"runtime"
+	"sync/atomic"
+)
+
+// A Logger receives debug messages describing each step of the encoding of
+// structs, such as the fields visited and why they are skipped.  It is meant
+// for debugging unexpected encodings; messages are not meant to be parsed.
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+// LoggerFunc adapts a function such as log.Printf to the Logger interface:
+//
+//	query.SetLogger(query.LoggerFunc(log.Printf))
+type LoggerFunc func(format string, args ...interface{})
+
+// Logf calls f(format, args...).
+func (f LoggerFunc) Logf(format string, args ...interface{}) {
+	f(format, args...)
+}
+
+// packageLogger holds the Logger set by SetLogger, wrapped in a loggerBox so
+// that the atomic.Value always stores the same type.
+var packageLogger atomic.Value
+
+type loggerBox struct{ Logger }
+
+// SetLogger sets the Logger receiving the debug messages of all encodings
+// without a WithLogger option.  A nil Logger, the default, discards them.  It
+// is safe to call SetLogger concurrently with encodings.
+func SetLogger(l Logger) {
+	packageLogger.Store(loggerBox{l})
+}
+
+// WithLogger sends the debug messages of the encoding to l rather than to
+// the Logger set by SetLogger.
+func WithLogger(l Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// currentLogger returns the Logger set by SetLogger, or nil.
+func currentLogger() Logger {
+	b, _ := packageLogger.Load().(loggerBox)
+	return b.Logger
+}
+
+// logit sends a debug message about val, labeled m, to the Logger set by
+// SetLogger.
+func logit(m string, val interface{}) {
+	if l := currentLogger(); l != nil {
+		logTo(l, m, val)
+	}
+}
+
+// logit sends a debug message about val, labeled m, to the Logger of e.
+func (e *encoder) logit(m string, val interface{}) {
+	l := e.logger
+	if l == nil {
+		l = currentLogger()
+	}
+	if l != nil {
+		logTo(l, m, e.redactLog(val))
+	}
+}
+
+// logTo writes the message of logit to l, naming the line of the caller of
+// logit.
+func logTo(l Logger, m string, val interface{}) {
+	_, fn, line, _ := runtime.Caller(2)
+	l.Logf("%v - L%d %v (type %T) = %+v", path.Base(fn), line, m, val, val)
+}
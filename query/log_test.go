@@ -0,0 +1,56 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	in := struct {
+		A string `url:"a"`
+		B string `url:"-"`
+	}{"x", "y"}
+
+	// nothing is logged by default
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	if _, err := Values(in); err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Values(%v) logged %q, want nothing", in, buf.String())
+	}
+
+	var msgs []string
+	record := LoggerFunc(func(format string, args ...interface{}) {
+		msgs = append(msgs, fmt.Sprintf(format, args...))
+	})
+	if _, err := Values(in, WithLogger(record)); err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	if len(msgs) == 0 || !strings.HasPrefix(msgs[0], "encode.go - L") {
+		t.Errorf("WithLogger received %q, want messages naming encode.go", msgs)
+	}
+	if !strings.Contains(strings.Join(msgs, "\n"), "tag is unexported due to - - continue") {
+		t.Errorf("WithLogger received %q, want the skipped field reported", msgs)
+	}
+
+	msgs = nil
+	SetLogger(record)
+	defer SetLogger(nil)
+	if _, err := Values(in); err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	if len(msgs) == 0 {
+		t.Errorf("SetLogger received no messages")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiValuePolicy selects how Map collapses parameters with several values
+// into a single one.
+type MultiValuePolicy int
+
+const (
+	// MultiValueError makes Map return a *FieldError naming the first
+	// parameter with several values.  This is the default.
+	MultiValueError MultiValuePolicy = iota
+
+	// MultiValueFirst keeps the first value of each parameter.
+	MultiValueFirst
+
+	// MultiValueLast keeps the last value of each parameter.
+	MultiValueLast
+
+	// MultiValueJoin joins the values of each parameter with commas.
+	MultiValueJoin
+)
+
+// WithMultiValues sets the policy Map applies to parameters with several
+// values.
+func WithMultiValues(policy MultiValuePolicy) Option {
+	return func(c *config) {
+		c.multiValues = policy
+	}
+}
+
+// Map encodes v like Values, but returns a map holding a single value for
+// each parameter, as taken by many SDK transports and request signing
+// libraries.  Parameters with several values, such as those of slices, are
+// collapsed following the policy set with WithMultiValues, which by default
+// is to return an error.  Slices encoded as a single value, with an option
+// such as "comma", are unaffected.
+func Map(v interface{}, opts ...Option) (map[string]string, error) {
+	e := &encoder{config: newConfig(opts)}
+	values, err := e.encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(values))
+	for _, k := range e.orderedKeys(values) {
+		vs := values[k]
+		switch {
+		case len(vs) == 0:
+			continue
+		case len(vs) == 1:
+			m[k] = vs[0]
+			continue
+		}
+		switch e.multiValues {
+		case MultiValueFirst:
+			m[k] = vs[0]
+		case MultiValueLast:
+			m[k] = vs[len(vs)-1]
+		case MultiValueJoin:
+			m[k] = strings.Join(vs, ",")
+		default:
+			return nil, &FieldError{Name: k, Err: fmt.Errorf("%d values for a single-valued map", len(vs))}
+		}
+	}
+	return m, nil
+}
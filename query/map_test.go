@@ -0,0 +1,55 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	type Options struct {
+		Q      string   `url:"q"`
+		Tags   []string `url:"tag"`
+		Fields []string `url:"fields,comma"`
+	}
+	in := Options{"go", []string{"a", "b"}, []string{"id", "name"}}
+
+	tests := []struct {
+		opts []Option
+		want map[string]string // nil if an error is expected
+	}{
+		{[]Option{WithMultiValues(MultiValueFirst)}, map[string]string{"q": "go", "tag": "a", "fields": "id,name"}},
+		{[]Option{WithMultiValues(MultiValueLast)}, map[string]string{"q": "go", "tag": "b", "fields": "id,name"}},
+		{[]Option{WithMultiValues(MultiValueJoin)}, map[string]string{"q": "go", "tag": "a,b", "fields": "id,name"}},
+		{nil, nil},
+	}
+
+	for i, tt := range tests {
+		got, err := Map(in, tt.opts...)
+		if tt.want == nil {
+			if err == nil || err.Error() != `query: parameter "tag": 2 values for a single-valued map` {
+				t.Errorf("%d. Map(%v) returned error %v, want one naming tag", i, in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d. Map(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Map(%v) returned %v, want %v", i, in, got, tt.want)
+		}
+	}
+
+	single := Options{Q: "go", Tags: []string{"a"}}
+	want := map[string]string{"q": "go", "tag": "a", "fields": ""}
+	if got, err := Map(single); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Map(%v) returned %v, %v, want %v", single, got, err, want)
+	}
+	if _, err := Map(""); err == nil {
+		t.Errorf("expected Map() to return an error on invalid input")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MatrixParams encodes v using Values and returns the parameters in matrix
+// form, ";key=value" for each value, to be appended to a path segment as
+// expected by JAX-RS @MatrixParam and similar:
+//
+//	m, err := query.MatrixParams(opt) // ";color=red;size=10"
+//	path := "/items" + m
+//
+// Keys are sorted, and repeated for each of their values.  Keys and values are
+// escaped for use in a path segment, including any ";", "=" and "/".
+func MatrixParams(v interface{}, opts ...Option) (string, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, k := range keys {
+		for _, v := range values[k] {
+			buf.WriteByte(';')
+			buf.WriteString(escapeMatrix(k))
+			buf.WriteByte('=')
+			buf.WriteString(escapeMatrix(v))
+		}
+	}
+	return buf.String(), nil
+}
+
+// escapeMatrix escapes s for use as the key or value of a matrix parameter.
+func escapeMatrix(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "=", "%3D")
+}
@@ -0,0 +1,48 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestMatrixParams(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, ""},
+		{
+			struct {
+				Size  int    `url:"size"`
+				Color string `url:"color"`
+			}{10, "red"},
+			";color=red;size=10",
+		},
+		{
+			struct {
+				Tags []string `url:"tag"`
+			}{[]string{"a", "b"}},
+			";tag=a;tag=b",
+		},
+		{
+			struct {
+				Q string `url:"q;x"`
+			}{"a b/c;d=e,f"},
+			";q%3Bx=a%20b%2Fc%3Bd%3De%2Cf",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := MatrixParams(tt.in)
+		if err != nil {
+			t.Errorf("%d. MatrixParams(%v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. MatrixParams(%v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ConflictPolicy selects how Merge combines a parameter produced by more than
+// one of its structs.
+type ConflictPolicy int
+
+const (
+	// ConflictError makes Merge return an error naming the parameter and
+	// the structs producing it.  This is the default.
+	ConflictError ConflictPolicy = iota
+
+	// ConflictAppend keeps the values of all structs, in argument order.
+	ConflictAppend
+
+	// ConflictFirst keeps the values of the first struct producing the
+	// parameter.
+	ConflictFirst
+
+	// ConflictLast keeps the values of the last struct producing the
+	// parameter, so later structs override earlier ones.
+	ConflictLast
+)
+
+// WithConflicts sets the policy Merge applies to parameters produced by more
+// than one struct.
+func WithConflicts(policy ConflictPolicy) Option {
+	return func(c *config) {
+		c.conflicts = policy
+	}
+}
+
+// Merge encodes each of vs like Values and combines the results into one set
+// of parameters, so that shared structs for pagination, authentication or
+// filtering can be composed without embedding them:
+//
+//	v, err := query.Merge(page, auth, filter, query.WithConflicts(query.ConflictLast))
+//
+// Arguments of type Option are not encoded; they apply to the encoding of
+// all the other arguments and to the merge itself, except for the overrides
+// of WithOverrides, which apply to the merged parameters.  Nil arguments are
+// skipped.  Parameters produced by more than one struct are combined
+// following the policy set with WithConflicts, which by default is to
+// return an error.
+func Merge(vs ...interface{}) (url.Values, error) {
+	var opts []Option
+	for _, v := range vs {
+		if opt, ok := v.(Option); ok {
+			opts = append(opts, opt)
+		}
+	}
+	c := newConfig(opts)
+	// Overrides apply to the merged parameters rather than to each struct
+	opts = append(opts, func(c *config) { c.overrides = nil })
+
+	merged := make(url.Values)
+	// from maps each parameter to the index of the argument producing it
+	from := make(map[string]int)
+	for i, v := range vs {
+		if _, ok := v.(Option); ok || v == nil {
+			continue
+		}
+		values, err := Values(v, opts...)
+		if err != nil {
+			return nil, err
+		}
+		for k, vals := range values {
+			j, ok := from[k]
+			if !ok {
+				merged[k] = vals
+				from[k] = i
+				continue
+			}
+			switch c.conflicts {
+			case ConflictAppend:
+				merged[k] = append(merged[k], vals...)
+			case ConflictFirst:
+			case ConflictLast:
+				merged[k] = vals
+			default:
+				return nil, fmt.Errorf("query: Merge() parameter %q is produced by arguments %d and %d", k, j, i)
+			}
+		}
+	}
+	applyOverrides(merged, c.overrides)
+	return merged, nil
+}
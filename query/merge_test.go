@@ -0,0 +1,73 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	type page struct {
+		Page    int `url:"page"`
+		PerPage int `url:"per_page,omitempty"`
+	}
+	type filter struct {
+		State string `url:"state"`
+		Page  int    `url:"page,omitempty"`
+	}
+	tests := []struct {
+		vs   []interface{}
+		want url.Values
+	}{
+		{nil, url.Values{}},
+		{
+			[]interface{}{page{Page: 1}, nil, &filter{State: "open"}},
+			url.Values{"page": {"1"}, "state": {"open"}},
+		},
+		{
+			[]interface{}{page{Page: 1}, filter{Page: 2}, WithConflicts(ConflictAppend)},
+			url.Values{"page": {"1", "2"}, "state": {""}},
+		},
+		{
+			[]interface{}{WithConflicts(ConflictFirst), page{Page: 1}, filter{Page: 2}},
+			url.Values{"page": {"1"}, "state": {""}},
+		},
+		{
+			[]interface{}{page{Page: 1}, filter{Page: 2}, WithConflicts(ConflictLast)},
+			url.Values{"page": {"2"}, "state": {""}},
+		},
+		{
+			[]interface{}{page{Page: 1, PerPage: 5}, WithArrayFormat(ArrayBrackets)},
+			url.Values{"page": {"1"}, "per_page": {"5"}},
+		},
+	}
+	for i, tt := range tests {
+		got, err := Merge(tt.vs...)
+		if err != nil {
+			t.Errorf("%d. Merge(%v) returned error: %v", i, tt.vs, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Merge(%v) returned %v, want %v", i, tt.vs, got, tt.want)
+		}
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	type a struct {
+		A string `url:"a"`
+	}
+	tests := [][]interface{}{
+		{a{}, a{}},
+		{a{}, "b"},
+	}
+	for i, vs := range tests {
+		if _, err := Merge(vs...); err == nil {
+			t.Errorf("%d. Merge(%v) did not return an error", i, vs)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// Defaults returns the default values of the URL parameters of the struct
+// type of v, which may be a struct or a (possibly nil) pointer to one, as
+// given by the "default" option of their fields.  For example, a field with
+// the tag `url:"per_page,default=30"` has the default "per_page=30".  Fields
+// of nested structs are scoped as done by Values.
+//
+// The defaults are meant to be passed to Minify.
+func Defaults(v interface{}, opts ...Option) (url.Values, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, fmt.Errorf("query: Defaults() %v", err)
+	}
+	values := make(url.Values)
+	newConfig(opts).addDefaults(values, t, "", make(map[reflect.Type]bool))
+	return values, nil
+}
+
+// addDefaults adds the defaults of the fields of the struct type t, scoped
+// under scope, to values.
+func (c *config) addDefaults(values url.Values, t reflect.Type, scope string, visiting map[reflect.Type]bool) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	c.walkFields(t, func(f typeField) {
+		name := c.scopedName(scope, f.name)
+		if _, _, styled := c.fieldStyle(f.opts); !styled && nestedStructType(f.sf.Type) {
+			c.addDefaults(values, indirectType(f.sf.Type), name, visiting)
+			return
+		}
+		if d, ok := f.opts.Value("default"); ok {
+			values.Set(name, d)
+		}
+	})
+}
+
+// Minify returns a copy of values without the parameters equal to their
+// defaults, that is whose values are exactly those of the same parameter in
+// defaults, producing the shortest equivalent query for servers applying the
+// same defaults.  The defaults are usually obtained from Defaults, or from
+// Values applied to an instance holding the default options:
+//
+//	defaults, _ := query.Values(DefaultListOptions)
+//	v = query.Minify(v, defaults)
+func Minify(values, defaults url.Values) url.Values {
+	min := make(url.Values, len(values))
+	for k, vs := range values {
+		if d, ok := defaults[k]; ok && reflect.DeepEqual(vs, d) {
+			continue
+		}
+		min[k] = vs
+	}
+	return min
+}
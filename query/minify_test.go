@@ -0,0 +1,66 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type minifyOptions struct {
+	Page    int    `url:"page,default=1"`
+	PerPage int    `url:"per_page,default=30"`
+	Sort    string `url:"sort,default=created"`
+	Q       string `url:"q"`
+	Filter  struct {
+		State string `url:"state,default=open"`
+	} `url:"filter"`
+	Next *minifyOptions `url:"-"`
+}
+
+func TestDefaults(t *testing.T) {
+	got, err := Defaults((*minifyOptions)(nil))
+	if err != nil {
+		t.Fatalf("Defaults returned error: %v", err)
+	}
+	want := url.Values{
+		"page":          {"1"},
+		"per_page":      {"30"},
+		"sort":          {"created"},
+		"filter[state]": {"open"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Defaults returned %v, want %v", got, want)
+	}
+
+	if _, err := Defaults(""); err == nil {
+		t.Errorf("expected Defaults() to return an error on invalid input")
+	}
+}
+
+func TestMinify(t *testing.T) {
+	opt := minifyOptions{Page: 2, PerPage: 30, Sort: "created", Q: "go"}
+	opt.Filter.State = "open"
+
+	v, err := Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	defaults, _ := Defaults(opt)
+	got := Minify(v, defaults)
+	want := url.Values{"page": {"2"}, "q": {"go"}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Minify(%v) returned %v, want %v", v, got, want)
+	}
+
+	// defaults from an instance, where repeated values must all match
+	v = url.Values{"a": {"1", "2"}, "b": {"1"}, "c": {"x"}}
+	defaults = url.Values{"a": {"1"}, "b": {"1"}, "c": {"x"}}
+	got = Minify(v, defaults)
+	if want := (url.Values{"a": {"1", "2"}}); !reflect.DeepEqual(want, got) {
+		t.Errorf("Minify(%v) returned %v, want %v", v, got, want)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var moneyType = reflect.TypeOf(Money{})
+
+// Money is an amount of money in a currency.  The amount is held in minor
+// units of the currency, such as cents, so that it is never subject to
+// rounding errors.  Currency is an ISO 4217 code, as in "EUR".
+//
+// A Money field is encoded as the amount in decimal form, as in "12.34",
+// under the name of the field, and as the currency scoped under that name,
+// as in "price[currency]".  The "minor" option encodes the amount in minor
+// units instead, as in "1234", and the "currency" option names the currency
+// parameter, as in "currency=currency".  The currency is left out if empty:
+//
+//	type ChargeOptions struct {
+//		Amount query.Money `url:"amount,minor,currency=currency"`
+//	}
+//
+//	// amount=1234&currency=EUR
+//
+// Decode reads Money fields back following the same options.  A nil *Money
+// is encoded as an empty value, like other nil pointers.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// currencyDigits lists the currencies whose minor units are not hundredths,
+// with their number of decimals.
+var currencyDigits = map[string]int{
+	"BHD": 3, "BIF": 0, "CLF": 4, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3,
+	"ISK": 0, "JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3,
+	"OMR": 3, "PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "UYW": 4,
+	"VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// IsZero reports whether m has no amount and no currency, so that the
+// "omitempty" option leaves it out.
+func (m Money) IsZero() bool {
+	return m == Money{}
+}
+
+// digits returns the number of decimals of the minor units of m's currency.
+func (m Money) digits() int {
+	if d, ok := currencyDigits[strings.ToUpper(m.Currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// Decimal returns the amount of m in decimal form, with the number of
+// decimals of its currency, as in "12.34" or "-0.05".
+func (m Money) Decimal() string {
+	d := m.digits()
+	a := m.Amount
+	sign := ""
+	if a < 0 {
+		sign = "-"
+	}
+	s := strconv.FormatUint(absInt64(a), 10)
+	if d == 0 {
+		return sign + s
+	}
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+	return sign + s[:len(s)-d] + "." + s[len(s)-d:]
+}
+
+func absInt64(a int64) uint64 {
+	if a < 0 {
+		return uint64(-(a + 1)) + 1
+	}
+	return uint64(a)
+}
+
+// String returns m as its decimal amount followed by its currency, as in
+// "12.34 EUR".
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// EncodeValues implements Encoder.  The amount is encoded in decimal form
+// under key, and the currency under key followed by "[currency]".  Fields of
+// type Money are encoded following their options instead.
+func (m Money) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, m.Decimal())
+	if m.Currency != "" {
+		v.Add(key+"[currency]", m.Currency)
+	}
+	return nil
+}
+
+// DecodeValues implements Decoder.  It accepts the amount in decimal form
+// under key, and the currency under key followed by "[currency]".  Fields of
+// type Money are decoded following their options instead.
+func (m *Money) DecodeValues(key string, v url.Values) error {
+	*m = Money{Currency: v.Get(key + "[currency]")}
+	s := v.Get(key)
+	if s == "" {
+		return nil
+	}
+	amount, err := parseDecimal(s, m.digits())
+	if err != nil {
+		return err
+	}
+	m.Amount = amount
+	return nil
+}
+
+// parseDecimal parses the decimal amount s into minor units with digits
+// decimals.
+func parseDecimal(s string, digits int) (int64, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > digits {
+		return 0, fmt.Errorf("amount %q has more than %d decimals", s, digits)
+	}
+	n, err := strconv.ParseInt(whole+frac+strings.Repeat("0", digits-len(frac)), 10, 64)
+	if err != nil || strings.HasPrefix(frac, "-") || strings.HasPrefix(frac, "+") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return n, nil
+}
+
+// moneyValue encodes the Money m of the field named name with the options
+// opts.  A nil m is encoded as an empty value, as other nil pointers are.
+func (e *encoder) moneyValue(values url.Values, name string, m *Money, opts tagOptions) error {
+	if err := e.claim(name); err != nil {
+		return err
+	}
+	if m == nil {
+		e.add(values, name, "")
+		return nil
+	}
+	amount := m.Decimal()
+	if opts.Contains("minor") {
+		amount = strconv.FormatInt(m.Amount, 10)
+	}
+	e.add(values, name, amount)
+
+	if m.Currency == "" {
+		return nil
+	}
+	k := e.currencyName(name, opts)
+	if err := e.claim(k); err != nil {
+		return err
+	}
+	e.add(values, k, m.Currency)
+	return nil
+}
+
+// currencyName returns the name of the currency parameter of the Money field
+// named name with the options opts.
+func (c *config) currencyName(name string, opts tagOptions) string {
+	if k, ok := opts.Value("currency"); ok {
+		return k
+	}
+	return c.scopedName(name, "currency")
+}
+
+// decodeMoney decodes the Money of the field named name with the options
+// opts, the counterpart of encoder.moneyValue.  It reports whether any of its
+// parameters is present, and returns nil if they are all empty.
+func (d *decoder) decodeMoney(name string, opts tagOptions) (interface{}, bool, error) {
+	k := d.currencyName(name, opts)
+	_, hasAmount := d.values[name]
+	_, hasCurrency := d.values[k]
+	if !hasAmount && !hasCurrency {
+		return nil, false, nil
+	}
+	m := Money{Currency: d.values.Get(k)}
+	s := d.values.Get(name)
+	if s == "" && m.Currency == "" {
+		return nil, true, nil
+	}
+	if s == "" {
+		return m, true, nil
+	}
+	var err error
+	if opts.Contains("minor") {
+		m.Amount, err = strconv.ParseInt(s, 10, 64)
+	} else {
+		m.Amount, err = parseDecimal(s, m.digits())
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return m, true, nil
+}
+
+// indirectMoney returns the Money held by v, following pointers, and whether
+// v is a Money or a pointer to one.  The Money is nil for nil pointers.
+func indirectMoney(v reflect.Value) (*Money, bool) {
+	if indirectType(v.Type()) != moneyType || !v.CanInterface() {
+		return nil, false
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, true
+		}
+		v = v.Elem()
+	}
+	m := v.Interface().(Money)
+	return &m, true
+}
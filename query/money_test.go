@@ -0,0 +1,147 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMoney_Decimal(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{Money{}, "0.00"},
+		{Money{1234, "EUR"}, "12.34"},
+		{Money{-5, "usd"}, "-0.05"},
+		{Money{1234, "JPY"}, "1234"},
+		{Money{1234, "KWD"}, "1.234"},
+		{Money{7, "KWD"}, "0.007"},
+		{Money{math.MinInt64, "EUR"}, "-92233720368547758.08"},
+	}
+	for i, tt := range tests {
+		if got := tt.in.Decimal(); got != tt.want {
+			t.Errorf("%d. Decimal(%v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+	if got, want := (Money{1234, "EUR"}).String(), "12.34 EUR"; got != want {
+		t.Errorf("String returned %q, want %q", got, want)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	type charge struct {
+		Amount Money  `url:"amount,minor,currency=currency"`
+		Fee    Money  `url:"fee,omitempty"`
+		Tip    *Money `url:"tip"`
+	}
+	tests := []struct {
+		in   charge
+		opts []Option
+		want url.Values
+	}{
+		{charge{}, nil, url.Values{"amount": {"0"}, "tip": {""}}},
+		{
+			charge{Money{1234, "EUR"}, Money{-50, "EUR"}, &Money{100, "JPY"}},
+			nil,
+			url.Values{
+				"amount": {"1234"}, "currency": {"EUR"},
+				"fee": {"-0.50"}, "fee[currency]": {"EUR"},
+				"tip": {"100"}, "tip[currency]": {"JPY"},
+			},
+		},
+		{
+			charge{Fee: Money{5, "EUR"}},
+			[]Option{WithGRPCGateway()},
+			url.Values{"amount": {"0"}, "fee": {"0.05"}, "fee.currency": {"EUR"}, "tip": {""}},
+		},
+	}
+	for i, tt := range tests {
+		got, err := Values(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%+v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%+v) returned %v, want %v", i, tt.in, got, tt.want)
+		}
+	}
+
+	if err := Check(charge{}); err != nil {
+		t.Errorf("Check returned error: %v", err)
+	}
+	bad := struct {
+		A int64 `url:"a,minor,currency=c"`
+	}{}
+	if err := Check(bad); err == nil {
+		t.Errorf("Check did not report the options on an int64")
+	}
+}
+
+func TestMoney_Decode(t *testing.T) {
+	tests := []struct {
+		in   url.Values
+		want Money
+		err  bool
+	}{
+		{url.Values{}, Money{}, false},
+		{url.Values{"m": {"12.34"}, "m[currency]": {"EUR"}}, Money{1234, "EUR"}, false},
+		{url.Values{"m": {"-0.5"}}, Money{-50, ""}, false},
+		{url.Values{"m": {"12"}, "m[currency]": {"JPY"}}, Money{12, "JPY"}, false},
+		{url.Values{"m": {"1.234"}}, Money{}, true},
+		{url.Values{"m": {"1.-2"}}, Money{}, true},
+		{url.Values{"m": {"x"}}, Money{}, true},
+	}
+	for i, tt := range tests {
+		var got Money
+		err := got.DecodeValues("m", tt.in)
+		if (err != nil) != tt.err || !tt.err && got != tt.want {
+			t.Errorf("%d. DecodeValues(%v) returned %v, %v, want %v", i, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestMoney_roundTrip(t *testing.T) {
+	type charge struct {
+		Amount Money  `url:"amount,minor,currency=currency"`
+		Fee    Money  `url:"fee,omitempty"`
+		Tip    *Money `url:"tip"`
+		Refund *Money `url:"refund,omitempty,minor"`
+	}
+	tests := []struct {
+		in   charge
+		opts []Option
+	}{
+		{charge{}, nil},
+		{charge{Amount: Money{1234, "EUR"}}, nil},
+		{charge{Amount: Money{-7, "KWD"}, Fee: Money{50, "EUR"}, Tip: &Money{100, "JPY"}}, nil},
+		{charge{Amount: Money{1234, ""}, Tip: &Money{}, Refund: &Money{5, "USD"}}, nil},
+		{charge{Amount: Money{1, "EUR"}, Fee: Money{5, "EUR"}}, []Option{WithGRPCGateway()}},
+	}
+	for i, tt := range tests {
+		values, err := Values(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%+v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		var out charge
+		if err := Decode(values, &out, tt.opts...); err != nil {
+			t.Errorf("%d. Decode(%v) returned error: %v", i, values, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tt.in) {
+			t.Errorf("%d. Decode(%v) decoded %+v, want %+v", i, values, out, tt.in)
+		}
+	}
+
+	var out charge
+	values := url.Values{"amount": {"1.5"}}
+	if err := Decode(values, &out); err == nil {
+		t.Errorf("Decode(%v) did not report a decimal amount with the minor option", values)
+	}
+}
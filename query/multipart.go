@@ -0,0 +1,120 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"sort"
+)
+
+var readerType = reflect.TypeOf(new(io.Reader)).Elem()
+
+// filePart is a field with the "file" option, collected while encoding a
+// multipart form.
+type filePart struct {
+	name string
+	v    reflect.Value
+}
+
+// WriteMultipart encodes v into w as multipart/form-data parts, using the same
+// rules as Values.  Each URL value becomes a form field part, written in
+// order of parameter name.
+//
+// Fields with the "file" option become file parts instead, written after the
+// form fields in the order the fields are declared.  Such fields must be an
+// io.Reader or a []byte.  The file name of the part is the base of the name
+// returned by the reader's Name() string method if it has one, as is the case
+// for *os.File, and the parameter name otherwise.  For example:
+//
+//	type Upload struct {
+//		Title  string    `url:"title"`
+//		Avatar io.Reader `url:"avatar,file,omitempty"`
+//	}
+//
+// WriteMultipart does not close w, so more parts may be added by the caller.
+func WriteMultipart(w *multipart.Writer, v interface{}, opts ...Option) error {
+	e := &encoder{config: newConfig(opts), collectFiles: true}
+	values, err := e.encode(v)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, s := range values[k] {
+			if err := w.WriteField(k, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range e.files {
+		if err := writeFilePart(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewMultipartReader encodes v using WriteMultipart and returns the
+// multipart/form-data request body, along with the matching content type.
+// The whole body is held in memory.
+func NewMultipartReader(v interface{}, opts ...Option) (io.Reader, string, error) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	if err := WriteMultipart(w, v, opts...); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body, w.FormDataContentType(), nil
+}
+
+// writeFilePart writes the file field f to w.
+func writeFilePart(w *multipart.Writer, f filePart) error {
+	v := f.v
+	for v.Kind() == reflect.Ptr && !v.Type().Implements(readerType) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var r io.Reader
+	switch {
+	case v.Type().Implements(readerType):
+		if v.IsNil() {
+			return nil
+		}
+		r = v.Interface().(io.Reader)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		r = bytes.NewReader(v.Bytes())
+	default:
+		return &FieldError{Name: f.name, Err: fmt.Errorf(`option "file" requires an io.Reader or []byte, not %v`, v.Type())}
+	}
+
+	filename := f.name
+	if n, ok := r.(interface{ Name() string }); ok {
+		filename = path.Base(n.Name())
+	}
+	part, err := w.CreateFormFile(f.name, filename)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return &FieldError{Name: f.name, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewMultipartReader(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "avatar.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("png data")
+	f.Seek(0, io.SeekStart)
+	defer f.Close()
+
+	upload := struct {
+		Title  string    `url:"title"`
+		Tags   []string  `url:"tag"`
+		Avatar *os.File  `url:"avatar,file"`
+		Notes  []byte    `url:"notes,file"`
+		Extra  io.Reader `url:"extra,file,omitempty"`
+	}{
+		Title:  "hello",
+		Tags:   []string{"a", "b"},
+		Avatar: f,
+		Notes:  []byte("some notes"),
+	}
+
+	// Values skips file fields
+	v, err := Values(upload)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", upload, err)
+	}
+	if want := (url.Values{"title": {"hello"}, "tag": {"a", "b"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", upload, v, want)
+	}
+
+	body, contentType, err := NewMultipartReader(upload)
+	if err != nil {
+		t.Fatalf("NewMultipartReader(%v) returned error: %v", upload, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("NewMultipartReader(%v) returned content type %q", upload, contentType)
+	}
+
+	type part struct {
+		Name, FileName, Content string
+	}
+	var got []part
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p)
+		got = append(got, part{p.FormName(), p.FileName(), string(b)})
+	}
+
+	want := []part{
+		{"tag", "", "a"},
+		{"tag", "", "b"},
+		{"title", "", "hello"},
+		{"avatar", "avatar.png", "png data"},
+		{"notes", "notes", "some notes"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("NewMultipartReader(%v) wrote parts %+v, want %+v", upload, got, want)
+	}
+}
+
+func TestWriteMultipart_invalidFile(t *testing.T) {
+	s := struct {
+		A int `url:"a,file"`
+	}{1}
+	w := multipart.NewWriter(io.Discard)
+	err := WriteMultipart(w, s)
+	if err == nil || !strings.Contains(err.Error(), `option "file" requires`) {
+		t.Errorf("WriteMultipart(%v) returned error %v", s, err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// Null is a value encoded as its parameter with an empty value, for saying
+// "send this parameter with no value" rather than leaving it out.  It may be
+// held by interface{} fields and slice elements, even with the "omitempty"
+// option, and by Override maps:
+//
+//	type Filter struct {
+//		Assignee interface{} `url:"assignee,omitempty"`
+//	}
+//
+//	query.Values(Filter{Assignee: query.Null}) // assignee=
+//
+// Combined with WithBareEmptyValues, the parameter is written without "=".
+var Null Encoder = null{}
+
+type null struct{}
+
+var nullType = reflect.TypeOf(null{})
+
+func (null) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, "")
+	return nil
+}
+
+func (null) String() string {
+	return ""
+}
+
+// isNull reports whether v is an interface holding Null.
+func isNull(v reflect.Value) bool {
+	return v.Kind() == reflect.Interface && !v.IsNil() && v.Elem().Type() == nullType
+}
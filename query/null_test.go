@@ -0,0 +1,38 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestNull(t *testing.T) {
+	type filter struct {
+		Assignee interface{}   `url:"assignee,omitempty"`
+		Labels   []interface{} `url:"label,omitempty"`
+		State    string        `url:"state,omitempty"`
+	}
+	tests := []struct {
+		in   filter
+		opts []Option
+		want string
+	}{
+		{filter{}, nil, ""},
+		{filter{Assignee: Null}, nil, "assignee="},
+		{filter{Assignee: Null}, []Option{WithBareEmptyValues()}, "assignee"},
+		{filter{Labels: []interface{}{"a", Null}}, nil, "label=a&label="},
+		{filter{}, []Option{WithOverrides(Override{"state": Null})}, "state="},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%+v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%+v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
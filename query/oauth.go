@@ -0,0 +1,76 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"strings"
+)
+
+// OAuthParams returns the normalized request parameters of an OAuth 1.0a
+// request, as defined by RFC 5849 section 3.4.1.3.2, for the parameters
+// encoded from v and the protocol parameters in oauth, such as
+// "oauth_consumer_key" and "oauth_nonce".  Any "oauth_signature" parameter is
+// excluded.  The options are the ones accepted by Values.
+//
+// The parameters are percent-encoded per RFC 5849 section 3.6, sorted by name
+// and then by value, and joined with "=" and "&".
+func OAuthParams(v interface{}, oauth url.Values, opts ...Option) (string, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return "", err
+	}
+	return oauthParams(values, oauth), nil
+}
+
+// OAuthBaseString returns the signature base string of an OAuth 1.0a request,
+// as defined by RFC 5849 section 3.4.1, for a request with the given method
+// and URL whose parameters are encoded from v, with the protocol parameters in
+// oauth.  Query parameters already present in rawurl are included in the
+// normalized parameters along with those of v and oauth.
+func OAuthBaseString(method, rawurl string, v interface{}, oauth url.Values, opts ...Option) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	values, err := Values(v, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToUpper(method) + "&" +
+		escapeRFC3986(oauthBaseURI(u)) + "&" +
+		escapeRFC3986(oauthParams(u.Query(), values, oauth)), nil
+}
+
+// oauthParams returns the normalized parameter string of all the parameters
+// of sources, except "oauth_signature".
+func oauthParams(sources ...url.Values) string {
+	all := make(url.Values)
+	for _, values := range sources {
+		for k, vs := range values {
+			if k != "oauth_signature" {
+				all[k] = append(all[k], vs...)
+			}
+		}
+	}
+	return canonicalQuery(all)
+}
+
+// oauthBaseURI returns the base string URI of u, as defined by RFC 5849
+// section 3.4.1.2: the scheme and host are lowercased, default ports are
+// removed, and the query and fragment are dropped.
+func oauthBaseURI(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80" || scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+// oauthBody and oauthProtocol hold the parameters of the example request of
+// RFC 5849 section 3.4.1.1.
+var (
+	oauthBody = struct {
+		C2 string `url:"c2"`
+		A3 string `url:"a3"`
+	}{"", "2 q"}
+
+	oauthProtocol = url.Values{
+		"oauth_consumer_key":     {"9djdj82h48djs9d2"},
+		"oauth_token":            {"kkk9d7dh3k39sjv7"},
+		"oauth_signature_method": {"HMAC-SHA1"},
+		"oauth_timestamp":        {"137131201"},
+		"oauth_nonce":            {"7d8f3e4a"},
+		"oauth_signature":        {"bYT5CMsGcbgUdFHObYMEfcx6bsw="},
+	}
+)
+
+func TestOAuthParams(t *testing.T) {
+	got, err := OAuthParams(struct {
+		B5 string   `url:"b5"`
+		A3 []string `url:"a3"`
+		C  string   `url:"c@"`
+		A2 string   `url:"a2"`
+		C2 string   `url:"c2"`
+	}{"=%3D", []string{"a", "2 q"}, "", "r b", ""}, oauthProtocol)
+	if err != nil {
+		t.Fatalf("OAuthParams() returned error: %v", err)
+	}
+	want := "a2=r%20b&a3=2%20q&a3=a&b5=%3D%253D&c%40=&c2=&oauth_consumer_key=9djdj82h48djs9d2" +
+		"&oauth_nonce=7d8f3e4a&oauth_signature_method=HMAC-SHA1&oauth_timestamp=137131201" +
+		"&oauth_token=kkk9d7dh3k39sjv7"
+	if got != want {
+		t.Errorf("OAuthParams() returned %q, want %q", got, want)
+	}
+}
+
+func TestOAuthBaseString(t *testing.T) {
+	tests := []struct {
+		method, url string
+		want        string
+	}{
+		{
+			"POST", "http://EXAMPLE.COM:80/request?b5=%3D%253D&a3=a&c%40=&a2=r%20b",
+			"POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q%26a3%3Da" +
+				"%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_key%3D9djdj82h48djs9d2" +
+				"%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1" +
+				"%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7",
+		},
+		{
+			"get", "https://example.com:8443",
+			"GET&https%3A%2F%2Fexample.com%3A8443%2F&a3%3D2%2520q%26c2%3D%26oauth_consumer_key%3D9djdj82h48djs9d2" +
+				"%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1" +
+				"%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := OAuthBaseString(tt.method, tt.url, oauthBody, oauthProtocol)
+		if err != nil {
+			t.Errorf("%d. OAuthBaseString(%q, %q) returned error: %v", i, tt.method, tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. OAuthBaseString(%q, %q) returned\n%s\nwant\n%s", i, tt.method, tt.url, got, tt.want)
+		}
+	}
+}
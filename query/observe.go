@@ -0,0 +1,93 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// An Observer is notified of each encoding and decoding, so that services can
+// monitor how long building queries takes, how large they are and how often
+// it fails.  Observe is called synchronously by the encoding or decoding
+// function, after it completes, and so should return quickly.  See the
+// otelquery package for an Observer recording OpenTelemetry metrics.
+type Observer interface {
+	Observe(Stats)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(Stats)
+
+// Observe calls f(s).
+func (f ObserverFunc) Observe(s Stats) {
+	f(s)
+}
+
+// An Op is the kind of operation described by Stats.
+type Op string
+
+const (
+	OpEncode Op = "encode" // Values and the other encoding functions
+	OpDecode Op = "decode" // Decode and DecodeString
+)
+
+// Stats describes a single encoding or decoding.
+type Stats struct {
+	Op Op
+
+	// Type is the type of the value encoded, or decoded into.
+	Type reflect.Type
+
+	Duration time.Duration
+
+	// Params is the number of parameter values encoded or decoded from,
+	// counting each value of a repeated parameter.
+	Params int
+
+	// Err is the error returned, if any.
+	Err error
+}
+
+// packageObserver holds the Observer set by SetObserver, wrapped in an
+// observerBox so that the atomic.Value always stores the same type.
+var packageObserver atomic.Value
+
+type observerBox struct{ Observer }
+
+// SetObserver sets the Observer notified of all encodings and decodings
+// without a WithObserver option.  A nil Observer, the default, disables
+// observation.  It is safe to call SetObserver concurrently with encodings.
+func SetObserver(o Observer) {
+	packageObserver.Store(observerBox{o})
+}
+
+// WithObserver notifies o of the encoding or decoding rather than the
+// Observer set by SetObserver.
+func WithObserver(o Observer) Option {
+	return func(c *config) {
+		c.observer = o
+	}
+}
+
+// currentObserver returns the Observer in effect for c, or nil.
+func (c *config) currentObserver() Observer {
+	if c.observer != nil {
+		return c.observer
+	}
+	b, _ := packageObserver.Load().(observerBox)
+	return b.Observer
+}
+
+// countPairs returns the number of values in values.
+func countPairs(values url.Values) int {
+	n := 0
+	for _, vs := range values {
+		n += len(vs)
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestObserver(t *testing.T) {
+	type Options struct {
+		Q    string   `url:"q"`
+		Tags []string `url:"tag"`
+		N    int      `url:"n"`
+	}
+	in := Options{Q: "go", Tags: []string{"a", "b"}}
+
+	var got []Stats
+	o := WithObserver(ObserverFunc(func(s Stats) { got = append(got, s) }))
+
+	if _, err := Values(in, o); err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	var out Options
+	if err := Decode(url.Values{"n": {"x"}}, &out, o); err == nil {
+		t.Fatalf("Decode returned no error for an invalid int")
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Observer notified %d times, want 2", len(got))
+	}
+	if s := got[0]; s.Op != OpEncode || s.Type != reflect.TypeOf(in) || s.Params != 4 || s.Err != nil || s.Duration < 0 {
+		t.Errorf("Values reported %+v, want an encoding of %T with 4 params", s, in)
+	}
+	if s := got[1]; s.Op != OpDecode || s.Type != reflect.TypeOf(&out) || s.Params != 1 || s.Err == nil {
+		t.Errorf("Decode reported %+v, want a failed decoding of %T from 1 param", s, &out)
+	}
+
+	got = nil
+	SetObserver(ObserverFunc(func(s Stats) { got = append(got, s) }))
+	defer SetObserver(nil)
+	if _, err := EncodeString(in); err != nil {
+		t.Fatalf("EncodeString(%v) returned error: %v", in, err)
+	}
+	if len(got) != 1 || got[0].Op != OpEncode {
+		t.Errorf("SetObserver notified %+v, want one encoding", got)
+	}
+}
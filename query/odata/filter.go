@@ -0,0 +1,169 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package odata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// An Expr is a boolean $filter expression.  Its String method returns it in
+// OData syntax.
+type Expr interface {
+	String() string
+	precedence() int
+}
+
+// Operator precedences, from lowest to highest, used to parenthesize operands.
+const (
+	precOr = iota + 1
+	precAnd
+	precNot
+	precPrimary
+)
+
+// Raw returns a filter expression written in OData syntax, for expressions
+// the builders of this package cannot express.
+func Raw(expr string) Expr {
+	return raw(expr)
+}
+
+type raw string
+
+func (r raw) String() string  { return string(r) }
+func (r raw) precedence() int { return precOr }
+
+type comparison struct {
+	property, op string
+	value        interface{}
+}
+
+func (c comparison) String() string {
+	return c.property + " " + c.op + " " + Literal(c.value)
+}
+
+func (c comparison) precedence() int { return precPrimary }
+
+// Eq is true when property equals value.
+func Eq(property string, value interface{}) Expr { return comparison{property, "eq", value} }
+
+// Ne is true when property does not equal value.
+func Ne(property string, value interface{}) Expr { return comparison{property, "ne", value} }
+
+// Gt is true when property is greater than value.
+func Gt(property string, value interface{}) Expr { return comparison{property, "gt", value} }
+
+// Ge is true when property is greater than or equal to value.
+func Ge(property string, value interface{}) Expr { return comparison{property, "ge", value} }
+
+// Lt is true when property is less than value.
+func Lt(property string, value interface{}) Expr { return comparison{property, "lt", value} }
+
+// Le is true when property is less than or equal to value.
+func Le(property string, value interface{}) Expr { return comparison{property, "le", value} }
+
+type call struct {
+	fn       string
+	property string
+	value    string
+}
+
+func (c call) String() string {
+	return c.fn + "(" + c.property + "," + Literal(c.value) + ")"
+}
+
+func (c call) precedence() int { return precPrimary }
+
+// Contains is true when the string property contains s.
+func Contains(property, s string) Expr { return call{"contains", property, s} }
+
+// StartsWith is true when the string property starts with s.
+func StartsWith(property, s string) Expr { return call{"startswith", property, s} }
+
+// EndsWith is true when the string property ends with s.
+func EndsWith(property, s string) Expr { return call{"endswith", property, s} }
+
+type in struct {
+	property string
+	values   []interface{}
+}
+
+func (e in) String() string {
+	lits := make([]string, len(e.values))
+	for i, v := range e.values {
+		lits[i] = Literal(v)
+	}
+	return e.property + " in (" + strings.Join(lits, ",") + ")"
+}
+
+func (e in) precedence() int { return precPrimary }
+
+// In is true when property equals one of values.
+func In(property string, values ...interface{}) Expr { return in{property, values} }
+
+type logical struct {
+	op    string
+	prec  int
+	exprs []Expr
+}
+
+func (l logical) String() string {
+	parts := make([]string, len(l.exprs))
+	for i, e := range l.exprs {
+		parts[i] = operand(e, l.prec)
+	}
+	return strings.Join(parts, " "+l.op+" ")
+}
+
+func (l logical) precedence() int {
+	if len(l.exprs) == 1 {
+		return l.exprs[0].precedence()
+	}
+	return l.prec
+}
+
+// And is true when all exprs are.
+func And(exprs ...Expr) Expr { return logical{"and", precAnd, exprs} }
+
+// Or is true when any of exprs is.
+func Or(exprs ...Expr) Expr { return logical{"or", precOr, exprs} }
+
+type not struct {
+	expr Expr
+}
+
+func (n not) String() string  { return "not " + operand(n.expr, precNot) }
+func (n not) precedence() int { return precNot }
+
+// Not is true when expr is false.
+func Not(expr Expr) Expr { return not{expr} }
+
+// operand returns e as an operand of an operator of precedence prec,
+// parenthesized if needed.
+func operand(e Expr, prec int) string {
+	if e.precedence() < prec {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// Literal returns v as an OData literal.  Strings are quoted with single
+// quotes, doubling any quote they contain; times are written as
+// DateTimeOffset values in RFC 3339 format; nil is written as null.  Other
+// values use their default format.
+func Literal(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return Literal(v.String())
+	}
+	return fmt.Sprint(v)
+}
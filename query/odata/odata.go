@@ -0,0 +1,107 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package odata builds OData system query options, such as $filter and
+// $orderby, whose syntax cannot be expressed with url tags.  Options
+// implements query.Encoder, so it can be embedded in the option structs
+// encoded by the query package:
+//
+//	type ListOptions struct {
+//		OData odata.Options
+//		Region string `url:"region,omitempty"`
+//	}
+//
+//	opt := ListOptions{OData: odata.Options{
+//		Filter:  odata.And(odata.Eq("Status", "open"), odata.Gt("Price", 10)),
+//		OrderBy: []odata.Order{odata.Desc("Created")},
+//		Top:     20,
+//	}}
+//	v, _ := query.Values(opt)
+//	// $filter=Status eq 'open' and Price gt 10&$orderby=Created desc&$top=20&region=...
+package odata
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Options holds OData system query options.  Zero fields are left out.
+type Options struct {
+	Filter  Expr     // $filter
+	OrderBy []Order  // $orderby
+	Select  []string // $select
+	Expand  []string // $expand
+	Search  string   // $search
+	Top     int      // $top
+	Skip    int      // $skip
+	Count   bool     // $count
+}
+
+// Values returns the system query options of o.
+func (o Options) Values() url.Values {
+	v := make(url.Values)
+	if o.Filter != nil {
+		v.Set("$filter", o.Filter.String())
+	}
+	if len(o.OrderBy) > 0 {
+		orders := make([]string, len(o.OrderBy))
+		for i, order := range o.OrderBy {
+			orders[i] = order.String()
+		}
+		v.Set("$orderby", strings.Join(orders, ","))
+	}
+	if len(o.Select) > 0 {
+		v.Set("$select", strings.Join(o.Select, ","))
+	}
+	if len(o.Expand) > 0 {
+		v.Set("$expand", strings.Join(o.Expand, ","))
+	}
+	if o.Search != "" {
+		v.Set("$search", o.Search)
+	}
+	if o.Top > 0 {
+		v.Set("$top", strconv.Itoa(o.Top))
+	}
+	if o.Skip > 0 {
+		v.Set("$skip", strconv.Itoa(o.Skip))
+	}
+	if o.Count {
+		v.Set("$count", "true")
+	}
+	return v
+}
+
+// EncodeValues implements query.Encoder.  System query options are not
+// scoped, so key is ignored.
+func (o Options) EncodeValues(key string, v *url.Values) error {
+	for k, vs := range o.Values() {
+		(*v)[k] = append((*v)[k], vs...)
+	}
+	return nil
+}
+
+// An Order is an item of $orderby.
+type Order struct {
+	Property string
+	Desc     bool
+}
+
+// Asc orders by property in ascending order.
+func Asc(property string) Order {
+	return Order{Property: property}
+}
+
+// Desc orders by property in descending order.
+func Desc(property string) Order {
+	return Order{Property: property, Desc: true}
+}
+
+// String returns o in $orderby syntax, as in "Name desc".
+func (o Order) String() string {
+	if o.Desc {
+		return o.Property + " desc"
+	}
+	return o.Property
+}
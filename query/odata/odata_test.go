@@ -0,0 +1,77 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package odata
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		expr Expr
+		want string
+	}{
+		{Eq("Name", "O'Brien"), "Name eq 'O''Brien'"},
+		{Gt("Price", 10.5), "Price gt 10.5"},
+		{Ne("Manager", nil), "Manager ne null"},
+		{Le("Created", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), "Created le 2020-01-02T03:04:05Z"},
+		{Eq("Active", true), "Active eq true"},
+		{And(Eq("A", 1), Or(Eq("B", 2), Eq("C", 3))), "A eq 1 and (B eq 2 or C eq 3)"},
+		{Or(And(Eq("A", 1), Eq("B", 2)), Eq("C", 3)), "A eq 1 and B eq 2 or C eq 3"},
+		{Not(Or(Contains("Name", "x"), StartsWith("Name", "y"))), "not (contains(Name,'x') or startswith(Name,'y'))"},
+		{And(EndsWith("Email", "@example.com"), Not(In("Status", "a", "b"))), "endswith(Email,'@example.com') and not Status in ('a','b')"},
+		{And(Raw("Price add 1 gt 2"), Eq("A", 1)), "(Price add 1 gt 2) and A eq 1"},
+	}
+
+	for i, tt := range tests {
+		if got := tt.expr.String(); got != tt.want {
+			t.Errorf("%d. String() returned %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	type ListOptions struct {
+		OData  Options
+		Region string `url:"region,omitempty"`
+	}
+	opt := ListOptions{
+		OData: Options{
+			Filter:  Eq("Status", "open"),
+			OrderBy: []Order{Desc("Created"), Asc("Name")},
+			Select:  []string{"Name", "Price"},
+			Top:     20,
+			Skip:    40,
+			Count:   true,
+		},
+		Region: "eu",
+	}
+
+	v, err := query.Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	want := url.Values{
+		"$filter":  {"Status eq 'open'"},
+		"$orderby": {"Created desc,Name"},
+		"$select":  {"Name,Price"},
+		"$top":     {"20"},
+		"$skip":    {"40"},
+		"$count":   {"true"},
+		"region":   {"eu"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+
+	if v := (Options{}).Values(); len(v) != 0 {
+		t.Errorf("Values() of zero Options returned %v, want none", v)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-querystring/query/openapi"
+)
+
+// OpenAPIParams returns OpenAPI 3 parameter definitions for the parameters
+// encoded from the struct type of v, which may be a struct or a (possibly nil)
+// pointer to one, so that API specifications can be generated from the same
+// structs used by clients.
+//
+// Parameters are returned for the path fields (see ExpandPath), which are
+// always required, followed by the query fields encoded by Values, and the
+// header and cookie fields (see Headers and Cookies).  Each field is
+// described as follows:
+//
+// The schema type and format follow from the Go type of the field and its
+// "int" and "unix" options.  Fields with the "required" option are marked
+// required.  An "enum" tag lists the allowed values, separated by commas.
+//
+// Slices and arrays use the "form" style, exploded unless the "comma" option
+// is given.  The "space" option selects the "spaceDelimited" style.  Those
+// with the "semicolon" option, for which OpenAPI has no style, are described
+// as a single string parameter.  Arrays with the "numbered" option are
+// described as one parameter per element, named "name0", "name1" and so on;
+// the parameters of such slices cannot be listed in advance and are reported
+// as an error.
+//
+// Nested structs are described as a single parameter of the "deepObject"
+// style, with an object schema.  So are maps with WithNestedMaps or
+// WithStripe, whose schema has additionalProperties; otherwise maps are
+// described as strings.  Fields with a "style" option, or all fields
+// when WithOpenAPIStyle is given, are described with that style instead.
+func OpenAPIParams(v interface{}, opts ...Option) ([]openapi.Parameter, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, fmt.Errorf("query: OpenAPIParams() %v", err)
+	}
+
+	var params []openapi.Parameter
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		c := newConfig(opts)
+		if in != "query" {
+			c.tagKey = in
+			c.requireTag = true
+			c.jsonFallback = false
+		}
+		c.walkFields(t, func(f typeField) {
+			p := openapi.Parameter{
+				Name:     f.name,
+				In:       in,
+				Required: in == "path" || f.opts.Contains("required"),
+				Schema:   c.schemaFor(f.sf.Type, f.opts, f.sf.Tag.Get("enum"), nil),
+			}
+			ft := indirectType(f.sf.Type)
+			style, explode, styled := c.fieldStyle(f.opts)
+			switch {
+			case styled && in == "query":
+				p.Style, p.Explode = style, boolPtr(explode)
+			case nestedStructType(f.sf.Type), ft.Kind() == reflect.Map && c.nestedMaps:
+				p.Style, p.Explode = "deepObject", boolPtr(true)
+			case ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array:
+				switch {
+				case f.opts.Contains("numbered"):
+					if ft.Kind() == reflect.Slice {
+						if err == nil {
+							err = fmt.Errorf("query: OpenAPIParams() cannot describe the numbered parameters of slice field %s", f.sf.Name)
+						}
+						return
+					}
+					p.Schema = c.schemaFor(ft.Elem(), f.opts, f.sf.Tag.Get("enum"), nil)
+					for i := 0; i < ft.Len(); i++ {
+						pi := p
+						pi.Name = fmt.Sprintf("%s%d", p.Name, i)
+						params = append(params, pi)
+					}
+					return
+				case f.opts.Contains("semicolon"):
+					p.Schema = &openapi.Schema{Type: "string"}
+				case f.opts.Contains("comma"):
+					p.Style, p.Explode = "form", boolPtr(false)
+				case f.opts.Contains("space"):
+					p.Style, p.Explode = "spaceDelimited", boolPtr(false)
+				case f.opts.Contains("brackets"):
+					p.Name += "[]"
+					p.Style, p.Explode = "form", boolPtr(true)
+				default:
+					p.Style, p.Explode = "form", boolPtr(true)
+				}
+			}
+			params = append(params, p)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// schemaFor returns the schema of values of type t encoded with opts.  enum
+// lists the allowed values separated by commas, if not empty.  visiting holds
+// the struct types being described, whose schemas are left open when they
+// recur.
+func (c *config) schemaFor(t reflect.Type, opts tagOptions, enum string, visiting map[reflect.Type]bool) *openapi.Schema {
+	s := new(openapi.Schema)
+	if t.Implements(encoderType) {
+		// Custom encodings could have any shape
+		return s
+	}
+	t = indirectType(t)
+
+	switch {
+	case t == timeType && opts.Contains("unix"):
+		s.Type, s.Format = "integer", "int64"
+	case t == timeType && (c.dateOnly || opts.Contains("dateonly")):
+		s.Type, s.Format = "string", "date"
+	case t == timeType:
+		s.Type, s.Format = "string", "date-time"
+	case t.Kind() == reflect.Map && c.nestedMaps:
+		s.Type = "object"
+		s.AdditionalProperties = c.schemaFor(t.Elem(), opts, enum, visiting)
+		return s
+	case nestedStructType(t):
+		s.Type = "object"
+		if visiting[t] {
+			return s
+		}
+		if visiting == nil {
+			visiting = make(map[reflect.Type]bool)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s.Properties = make(map[string]*openapi.Schema)
+		c.walkFields(t, func(f typeField) {
+			s.Properties[f.name] = c.schemaFor(f.sf.Type, f.opts, f.sf.Tag.Get("enum"), visiting)
+			if f.opts.Contains("required") {
+				s.Required = append(s.Required, f.name)
+			}
+		})
+	default:
+		switch t.Kind() {
+		case reflect.Bool:
+			if opts.Contains("int") {
+				s.Type, s.Enum = "integer", []interface{}{0, 1}
+			} else {
+				s.Type = "boolean"
+			}
+		case reflect.Int8, reflect.Int16, reflect.Int32:
+			s.Type, s.Format = "integer", "int32"
+		case reflect.Int, reflect.Int64:
+			s.Type, s.Format = "integer", "int64"
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			zero := 0.0
+			s.Type, s.Minimum = "integer", &zero
+		case reflect.Float32:
+			s.Type, s.Format = "number", "float"
+		case reflect.Float64:
+			s.Type, s.Format = "number", "double"
+		case reflect.Slice, reflect.Array:
+			s.Type = "array"
+			s.Items = c.schemaFor(t.Elem(), opts, enum, visiting)
+			return s
+		default:
+			s.Type = "string"
+		}
+	}
+
+	if enum != "" {
+		s.Enum = nil
+		for _, e := range strings.Split(enum, ",") {
+			s.Enum = append(s.Enum, enumValue(s.Type, e))
+		}
+	}
+	return s
+}
+
+// enumValue converts the enum value e to the JSON type typ if possible.
+func enumValue(typ, e string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(e, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(e, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(e); err == nil {
+			return b
+		}
+	}
+	return e
+}
+
+// typeField describes a struct field as encoded by Values, for functions
+// describing types rather than encoding values.
+type typeField struct {
+	name  string // URL parameter name
+	sf    reflect.StructField
+	opts  tagOptions
+	index []int // index sequence of the field in the walked type
+}
+
+// walkFields calls fn for each field of the struct type t that Values would
+// encode, in the order Values encodes them and following its naming rules.
+// Embedded structs are flattened; nested structs are passed to fn as a whole.
+func (c *config) walkFields(t reflect.Type, fn func(typeField)) {
+	c.walkFieldsIndex(t, nil, fn)
+}
+
+// walkFieldsIndex implements walkFields for the struct type t found at the
+// index sequence prefix of the walked type.
+func (c *config) walkFieldsIndex(t reflect.Type, prefix []int, fn func(typeField)) {
+	// embedded holds the indexes of embedded struct fields
+	var embedded []int
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := structField(t, i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+
+		tag := c.fieldTag(sf)
+		if tag == "-" || companionOnly(sf, tag) {
+			continue
+		}
+		name, opts := parseTag(tag)
+
+		if sf.Anonymous && (name == "" || c.embeddedNaming == EmbeddedFlatten) {
+			if et, ok := embeddedStructType(sf.Type); ok {
+				if c.embeddedOrder == EmbeddedInline {
+					c.walkFieldsIndex(et, fieldIndex(prefix, i), fn)
+				} else {
+					embedded = append(embedded, i)
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+		}
+
+		if tag == "" && c.requireTag {
+			continue
+		}
+		if name == "" {
+			name = c.fieldName(sf)
+		}
+		fn(typeField{name: name, sf: sf, opts: opts, index: fieldIndex(prefix, i)})
+	}
+
+	for _, i := range embedded {
+		et, _ := embeddedStructType(t.Field(i).Type)
+		c.walkFieldsIndex(et, fieldIndex(prefix, i), fn)
+	}
+}
+
+// fieldIndex returns the index sequence prefix followed by i, in a new slice.
+func fieldIndex(prefix []int, i int) []int {
+	return append(prefix[:len(prefix):len(prefix)], i)
+}
+
+// indirectType returns the type t points to, following pointers.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// structType returns the struct type of v, which may be a struct or a
+// pointer to one.
+func structType(v interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	if t != nil {
+		t = indirectType(t)
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expects struct input. Got %v", t)
+	}
+	return t, nil
+}
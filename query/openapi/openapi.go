@@ -0,0 +1,34 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openapi defines the subset of the OpenAPI 3 specification objects
+// produced by query.OpenAPIParams.  The types marshal to JSON (and, through
+// any JSON-compatible encoder, YAML) as specified by OpenAPI 3.0.
+package openapi
+
+// Parameter is an OpenAPI Parameter Object.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "query", "path", "header" or "cookie"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Style       string  `json:"style,omitempty"`
+	Explode     *bool   `json:"explode,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// Schema is an OpenAPI Schema Object, limited to the keywords needed to
+// describe parameters.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Enum       []interface{}      `json:"enum,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+
+	// AdditionalProperties is the schema of the entries of maps.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
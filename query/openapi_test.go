@@ -0,0 +1,169 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type openAPIOptions struct {
+	ID      int           `path:"id"`
+	Query   string        `url:"q,required"`
+	Sort    string        `url:"sort" enum:"asc,desc"`
+	Limit   uint8         `url:"limit" enum:"10,50,100"`
+	Tags    []string      `url:"tags,comma"`
+	IDs     []int32       `url:"ids"`
+	Since   time.Time     `url:"since,unix"`
+	Until   *time.Time    `url:"until"`
+	Debug   bool          `url:"debug,int"`
+	Filter  openAPIFilter `url:"filter"`
+	Token   string        `header:"Authorization"`
+	Session string        `cookie:"session"`
+	ignored string
+}
+
+type openAPIFilter struct {
+	Score float64        `url:"score,required"`
+	Next  *openAPIFilter `url:"next"`
+}
+
+func TestOpenAPIParams(t *testing.T) {
+	params, err := OpenAPIParams(new(openAPIOptions))
+	if err != nil {
+		t.Fatalf("OpenAPIParams() returned error: %v", err)
+	}
+	got, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	want := `[` +
+		`{"name":"id","in":"path","required":true,"schema":{"type":"integer","format":"int64"}},` +
+		`{"name":"q","in":"query","required":true,"schema":{"type":"string"}},` +
+		`{"name":"sort","in":"query","schema":{"type":"string","enum":["asc","desc"]}},` +
+		`{"name":"limit","in":"query","schema":{"type":"integer","enum":[10,50,100],"minimum":0}},` +
+		`{"name":"tags","in":"query","style":"form","explode":false,"schema":{"type":"array","items":{"type":"string"}}},` +
+		`{"name":"ids","in":"query","style":"form","explode":true,"schema":{"type":"array","items":{"type":"integer","format":"int32"}}},` +
+		`{"name":"since","in":"query","schema":{"type":"integer","format":"int64"}},` +
+		`{"name":"until","in":"query","schema":{"type":"string","format":"date-time"}},` +
+		`{"name":"debug","in":"query","schema":{"type":"integer","enum":[0,1]}},` +
+		`{"name":"filter","in":"query","style":"deepObject","explode":true,"schema":{"type":"object","properties":{` +
+		`"next":{"type":"object"},` +
+		`"score":{"type":"number","format":"double"}},"required":["score"]}},` +
+		`{"name":"Authorization","in":"header","schema":{"type":"string"}},` +
+		`{"name":"session","in":"cookie","schema":{"type":"string"}}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("OpenAPIParams() returned\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOpenAPIParams_invalidInput(t *testing.T) {
+	if _, err := OpenAPIParams(""); err == nil {
+		t.Errorf("expected OpenAPIParams() to return an error on invalid input")
+	}
+}
+
+func TestOpenAPIParams_styles(t *testing.T) {
+	in := struct {
+		IDs    []int         `url:"ids,style=pipeDelimited"`
+		Filter openAPIFilter `url:"filter,style=form"`
+		Tags   []string      `url:"tags"`
+	}{}
+	params, err := OpenAPIParams(in, WithOpenAPIStyle("spaceDelimited", false))
+	if err != nil {
+		t.Fatalf("OpenAPIParams() returned error: %v", err)
+	}
+
+	want := []struct {
+		style   string
+		explode bool
+	}{{"pipeDelimited", false}, {"form", true}, {"spaceDelimited", false}}
+	if len(params) != len(want) {
+		t.Fatalf("OpenAPIParams() returned %d parameters, want %d", len(params), len(want))
+	}
+	for i, p := range params {
+		if p.Style != want[i].style || p.Explode == nil || *p.Explode != want[i].explode {
+			t.Errorf("OpenAPIParams() returned %s style %q, explode %v, want %q, %v", p.Name, p.Style, p.Explode, want[i].style, want[i].explode)
+		}
+	}
+}
+
+func TestOpenAPIParams_dateOnly(t *testing.T) {
+	in := struct {
+		From time.Time  `url:"from,dateonly"`
+		To   *time.Time `url:"to"`
+	}{}
+	for _, tt := range []struct {
+		opts []Option
+		want []string
+	}{
+		{nil, []string{"date", "date-time"}},
+		{[]Option{WithDateOnly()}, []string{"date", "date"}},
+	} {
+		params, err := OpenAPIParams(in, tt.opts...)
+		if err != nil {
+			t.Fatalf("OpenAPIParams() returned error: %v", err)
+		}
+		for i, p := range params {
+			if p.Schema.Format != tt.want[i] {
+				t.Errorf("OpenAPIParams(%d options) described %s with format %q, want %q", len(tt.opts), p.Name, p.Schema.Format, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestOpenAPIParams_shapes(t *testing.T) {
+	in := struct {
+		Pos  [2]int            `url:"pos,numbered"`
+		Tags []string          `url:"tags,semicolon"`
+		Meta map[string]string `url:"meta"`
+	}{}
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{
+			nil,
+			`[` +
+				`{"name":"pos0","in":"query","schema":{"type":"integer","format":"int64"}},` +
+				`{"name":"pos1","in":"query","schema":{"type":"integer","format":"int64"}},` +
+				`{"name":"tags","in":"query","schema":{"type":"string"}},` +
+				`{"name":"meta","in":"query","schema":{"type":"string"}}` +
+				`]`,
+		},
+		{
+			[]Option{WithNestedMaps()},
+			`[` +
+				`{"name":"pos0","in":"query","schema":{"type":"integer","format":"int64"}},` +
+				`{"name":"pos1","in":"query","schema":{"type":"integer","format":"int64"}},` +
+				`{"name":"tags","in":"query","schema":{"type":"string"}},` +
+				`{"name":"meta","in":"query","style":"deepObject","explode":true,"schema":{"type":"object","additionalProperties":{"type":"string"}}}` +
+				`]`,
+		},
+	}
+	for i, tt := range tests {
+		params, err := OpenAPIParams(in, tt.opts...)
+		if err != nil {
+			t.Fatalf("%d. OpenAPIParams() returned error: %v", i, err)
+		}
+		got, err := json.Marshal(params)
+		if err != nil {
+			t.Fatalf("%d. json.Marshal returned error: %v", i, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%d. OpenAPIParams() returned\n%s\nwant\n%s", i, got, tt.want)
+		}
+	}
+
+	numbered := struct {
+		IDs []int `url:"id,numbered"`
+	}{}
+	if _, err := OpenAPIParams(numbered); err == nil {
+		t.Errorf("expected OpenAPIParams() to return an error for numbered slices")
+	}
+}
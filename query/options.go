@@ -0,0 +1,763 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An Option configures how Values encodes a struct, and likewise how Decode
+// decodes one.  Options are applied in the order they are given, so later
+// options override earlier ones.
+type Option func(*config)
+
+// config holds the settings that may be changed by passing Options to Values.
+// The config returned by newConfig for no options encodes using the rules
+// described in the Values documentation.
+type config struct {
+	// tagKey is the key of the struct tags holding names and options.
+	tagKey string
+
+	// jsonFallback reads field names from json tags for fields without a
+	// tag for tagKey.
+	jsonFallback bool
+
+	// requireTag skips fields without a tag for tagKey, other than
+	// anonymous struct fields.
+	requireTag bool
+
+	// nestOpen and nestClose surround the names of nested fields, which
+	// follow the name of the enclosing field, as in "parent[child]".
+	nestOpen, nestClose string
+
+	// indexStructs expands slices and arrays of structs so that the fields
+	// of each element are scoped under the element's index, as in
+	// "parent[0][child]".
+	indexStructs bool
+
+	// arrayFormat is how slices and arrays without a delimiter option are
+	// encoded.
+	arrayFormat ArrayFormat
+
+	// bracketIndexes scopes the elements of slices and arrays under their
+	// index in brackets, as in "parent[0]", whatever nestOpen and
+	// nestClose are.  As done by qs, elements of slices of structs are then
+	// scoped under the name of the slice itself with ArrayRepeat.
+	bracketIndexes bool
+
+	// style and explode are the OpenAPI serialization style and explode
+	// setting of fields without a "style" option.  No style means the rules
+	// of Values apply.
+	style   string
+	explode bool
+
+	// indexStructElems scopes the elements of slices of structs under their
+	// index whatever arrayFormat is, as jQuery.param does.
+	indexStructElems bool
+
+	// escape escapes keys and values in the output of EncodeString, unless
+	// rawKeys is set, in which case keys are written as is.  A nil escape
+	// means url.QueryEscape.
+	escape  func(string) string
+	rawKeys bool
+
+	// semicolons separates the parameters written by EncodeString with ";"
+	// instead of "&", and lets DecodeString accept both.
+	semicolons bool
+
+	// bareEmpty writes empty values as their key alone, without "=".
+	bareEmpty bool
+
+	// dedupe drops repeated identical values of a parameter.
+	dedupe bool
+
+	embeddedOrder   EmbeddedOrder
+	embeddedNaming  EmbeddedNaming
+	collectErrors   bool
+	checkCollisions bool
+
+	nonFinite            NonFinitePolicy
+	nonFiniteReplacement string
+
+	// floatFormat formats finite float values, if not nil, and
+	// decimalSeparator replaces their decimal point, if not empty.
+	floatFormat      func(f float64, bitSize int) string
+	decimalSeparator string
+
+	// boolCase is the capitalization of bool values.
+	boolCase BoolCase
+
+	// normalize transforms string values before they are encoded, if not
+	// nil.
+	normalize func(string) string
+
+	// camelNames names fields without a name in their tag after the field
+	// name in lowerCamelCase, as in "pageSize", rather than as is.
+	camelNames bool
+
+	// timeLayout is the layout of time.Time values without the "unix"
+	// option.
+	timeLayout string
+
+	// timeLocation is the location time.Time values are converted to
+	// before formatting, if not nil.
+	timeLocation *time.Location
+
+	// dateOnly formats time.Time values as dates, without their time.
+	dateOnly bool
+
+	// durationSeconds formats time.Duration values as decimal seconds, as in
+	// "1.5s", rather than using their String method.
+	durationSeconds bool
+
+	// logger receives the debug messages of the encoder.  A nil logger
+	// means the one set by SetLogger.
+	logger Logger
+
+	// trace records the steps of the encoding, if not nil.
+	trace *Trace
+
+	// observer is notified of each encoding and decoding.  A nil observer
+	// means the one set by SetObserver.
+	observer Observer
+
+	// redactPatterns match the names of fields whose values are hidden
+	// from diagnostics, in addition to those with the "redact" option.
+	redactPatterns []string
+
+	// maxLength is the maximum length of encoded query strings, if not 0.
+	maxLength int
+
+	// maxParams and maxRepeats are the maximum number of parameter values
+	// encoded in all and for a single parameter name, if not 0.
+	maxParams, maxRepeats int
+
+	// reserved holds the parameter names that must not be encoded.
+	reserved map[string]bool
+
+	// only and except select the fields encoded by name, if not nil.
+	only, except map[string]bool
+
+	// nestedMaps encodes maps like nested structs, one parameter per entry.
+	nestedMaps bool
+
+	// paths selects the fields encoded by Go path, if not nil, as done by
+	// Tracked.
+	paths map[string]bool
+
+	// allowed maps parameter names to the values allowed for them.
+	allowed map[string][]string
+
+	// validator validates values before they are encoded, if not nil.
+	validator func(v interface{}) error
+
+	// keyLess orders the parameters of Pairs and EncodeString, if not nil.
+	keyLess func(a, b string) bool
+
+	// multiValues is how Map collapses parameters with several values.
+	multiValues MultiValuePolicy
+
+	// conflicts is how Merge combines parameters produced by several
+	// structs.
+	conflicts ConflictPolicy
+
+	// overrides replace or remove encoded parameters, if not nil.
+	overrides Override
+
+	// getters encodes unexported fields through their exported getter
+	// methods, when they have one.
+	getters bool
+}
+
+// newConfig returns a config with opts applied.
+func newConfig(opts []Option) *config {
+	c := &config{
+		tagKey:     "url",
+		nestOpen:   "[",
+		nestClose:  "]",
+		timeLayout: time.RFC3339,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// scopedName returns the parameter name of the field name nested in scope.
+func (c *config) scopedName(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + c.nestOpen + name + c.nestClose
+}
+
+// fieldTag returns the tag of the field sf for tagKey.  With jsonFallback,
+// fields without one get the name of their json tag, without its options.
+func (c *config) fieldTag(sf reflect.StructField) string {
+	tag, ok := sf.Tag.Lookup(c.tagKey)
+	if ok || !c.jsonFallback {
+		return tag
+	}
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	return name
+}
+
+// fieldName returns the parameter name of the field sf when its tag does not
+// name it.
+func (c *config) fieldName(sf reflect.StructField) string {
+	if c.camelNames {
+		return lowerCamel(sf.Name)
+	}
+	return sf.Name
+}
+
+// lowerCamel returns the Go identifier name in lowerCamelCase, lowering its
+// leading initialism if it has one, as in "id" for "ID" and "urlPath" for
+// "URLPath".
+func lowerCamel(name string) string {
+	n := 0
+	for n < len(name) && 'A' <= name[n] && name[n] <= 'Z' {
+		n++
+	}
+	if n > 1 && n < len(name) && 'a' <= name[n] && name[n] <= 'z' {
+		// keep the start of the next word
+		n--
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
+
+// expandsStructs reports whether slices and arrays of structs are expanded
+// into the fields of each element, scoped as given by structScope.
+func (c *config) expandsStructs() bool {
+	return c.indexStructs || c.arrayFormat != ArrayRepeat
+}
+
+// elementScope returns the scope of the element at index i of the slice or
+// array named name.
+func (c *config) elementScope(name string, i int) string {
+	index := strconv.Itoa(i)
+	if c.arrayFormat == ArrayBrackets {
+		index = ""
+	}
+	if c.bracketIndexes {
+		if c.arrayFormat == ArrayRepeat {
+			return name
+		}
+		return name + "[" + index + "]"
+	}
+	return c.scopedName(name, index)
+}
+
+// structScope returns the scope of the fields of the struct at index i of the
+// slice or array named name.
+func (c *config) structScope(name string, i int) string {
+	if c.indexStructElems {
+		return c.scopedName(name, strconv.Itoa(i))
+	}
+	return c.elementScope(name, i)
+}
+
+// EmbeddedOrder controls where the fields of anonymous struct fields are
+// encoded relative to the other fields of the struct embedding them.  The
+// order matters when several fields encode to the same URL parameter name,
+// since their values are added in the order the fields are encoded.
+type EmbeddedOrder int
+
+const (
+	// EmbeddedLast encodes the fields of embedded structs after all other
+	// fields of the embedding struct, breadth-first.  This is the default.
+	EmbeddedLast EmbeddedOrder = iota
+
+	// EmbeddedInline encodes the fields of embedded structs in place of the
+	// anonymous field, depth-first, which matches the field order used by
+	// encoding/json.
+	EmbeddedInline
+)
+
+// WithEmbeddedOrder sets the order in which the fields of embedded structs are
+// encoded.
+func WithEmbeddedOrder(order EmbeddedOrder) Option {
+	return func(c *config) {
+		c.embeddedOrder = order
+	}
+}
+
+// EmbeddedNaming controls how anonymous struct fields that are given a name
+// in their url tag are encoded.  Anonymous struct fields without a name are
+// always flattened into the embedding struct.
+type EmbeddedNaming int
+
+const (
+	// EmbeddedScoped treats a named anonymous struct field like any other
+	// nested struct, scoping its fields under the tag name, e.g.
+	// "name[field]".  This is the default.
+	EmbeddedScoped EmbeddedNaming = iota
+
+	// EmbeddedFlatten ignores the tag name of anonymous struct fields and
+	// flattens their fields into the embedding struct.
+	EmbeddedFlatten
+)
+
+// WithEmbeddedNaming sets how anonymous struct fields with a name in their
+// url tag are encoded.
+func WithEmbeddedNaming(naming EmbeddedNaming) Option {
+	return func(c *config) {
+		c.embeddedNaming = naming
+	}
+}
+
+// WithCollectErrors makes Values continue past fields that fail to encode.
+// The error returned then joins a *FieldError for every failing field, so
+// they can all be fixed at once.  By default Values stops at the first error.
+func WithCollectErrors() Option {
+	return func(c *config) {
+		c.collectErrors = true
+	}
+}
+
+// WithCollisionCheck makes Values return an error when two different fields
+// encode to the same URL parameter name, whether they are declared side by
+// side, in embedded structs, or at different nesting levels.  Such
+// collisions are nearly always a mistake in a url tag.
+func WithCollisionCheck() Option {
+	return func(c *config) {
+		c.checkCollisions = true
+	}
+}
+
+// NonFinitePolicy selects how NaN and infinite float values are encoded.
+// Most HTTP APIs reject such values, so it is often better to fail early or
+// leave them out.
+type NonFinitePolicy int
+
+const (
+	// NonFiniteAllow encodes NaN and infinite values as "NaN", "+Inf" and
+	// "-Inf".  This is the default.
+	NonFiniteAllow NonFinitePolicy = iota
+
+	// NonFiniteError makes Values return an error.
+	NonFiniteError
+
+	// NonFiniteOmit leaves the value out.  A field holding a single value
+	// is omitted, while the value is skipped within a slice or array.
+	NonFiniteOmit
+
+	// NonFiniteReplace encodes the value as the string set with
+	// WithNonFiniteReplacement.
+	NonFiniteReplace
+)
+
+// WithNonFiniteFloats sets the policy for encoding NaN and infinite float
+// values.
+func WithNonFiniteFloats(policy NonFinitePolicy) Option {
+	return func(c *config) {
+		c.nonFinite = policy
+	}
+}
+
+// WithNonFiniteReplacement encodes NaN and infinite float values as s.  It
+// implies the NonFiniteReplace policy.
+func WithNonFiniteReplacement(s string) Option {
+	return func(c *config) {
+		c.nonFinite = NonFiniteReplace
+		c.nonFiniteReplacement = s
+	}
+}
+
+// WithFloatFormat formats float values with fn rather than in Go's default
+// format, as for services expecting a fixed number of decimals.  bitSize is
+// 32 for float32 values and 64 for float64 ones, as for
+// strconv.FormatFloat.  NaN and infinite values are still encoded following
+// the NonFinitePolicy, and floats whose type has a String method by it.
+func WithFloatFormat(fn func(f float64, bitSize int) string) Option {
+	return func(c *config) {
+		c.floatFormat = fn
+	}
+}
+
+// WithDecimalSeparator formats float values with sep as the decimal
+// separator, as in "1,5" for the decimal comma expected by some regional
+// services.  It applies to the default format as well as to those set with
+// WithFloatFormat and WithFixedPointFloats.  Decode accepts sep in float
+// values as well.  A slice or array of floats whose values are joined by a
+// delimiter found in sep, such as with the "comma" option and a decimal
+// comma, is reported as an error, as its values could not be told apart.
+func WithDecimalSeparator(sep string) Option {
+	return func(c *config) {
+		c.decimalSeparator = sep
+	}
+}
+
+// WithFixedPointFloats formats float values without an exponent, as in
+// "0.0000001" rather than "1e-07", which many parsers reject.  Values are
+// rounded to at most maxPrecision decimals, without trailing zeros.  A
+// negative maxPrecision keeps all the decimals needed to represent each
+// value exactly.
+func WithFixedPointFloats(maxPrecision int) Option {
+	return WithFloatFormat(func(f float64, bitSize int) string {
+		s := strconv.FormatFloat(f, 'f', -1, bitSize)
+		if i := strings.IndexByte(s, '.'); maxPrecision >= 0 && i >= 0 && len(s)-i-1 > maxPrecision {
+			s = strconv.FormatFloat(f, 'f', maxPrecision, bitSize)
+			if maxPrecision > 0 {
+				s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+			}
+		}
+		if s == "-0" {
+			s = "0"
+		}
+		return s
+	})
+}
+
+// WithStringNormalizer applies fn to string values before they are encoded,
+// typically to bring Unicode text to a normal form, so that visually
+// identical input composed differently, such as "é" as one code point or as
+// "e" followed by a combining accent, does not produce mismatched values on
+// the server.  NFC normalization is done with the golang.org/x/text module:
+//
+//	query.Values(opts, query.WithStringNormalizer(norm.NFC.String))
+//
+// fn applies to the values of fields of string kinds, including slice
+// elements and map values, but not to the output of custom Encoders or
+// String methods.
+func WithStringNormalizer(fn func(string) string) Option {
+	return func(c *config) {
+		c.normalize = fn
+	}
+}
+
+// BoolCase selects the capitalization of encoded bool values.
+type BoolCase int
+
+const (
+	// BoolLower encodes bools as "true" and "false".  This is the
+	// default.
+	BoolLower BoolCase = iota
+
+	// BoolTitle encodes bools as "True" and "False", as expected by some
+	// .NET and Java services.
+	BoolTitle
+
+	// BoolUpper encodes bools as "TRUE" and "FALSE".
+	BoolUpper
+)
+
+// WithBoolCase sets the capitalization of bool values.  It does not apply to
+// fields with the "int" option, nor to bools whose type has a String method.
+// Decode accepts all of them.
+func WithBoolCase(c BoolCase) Option {
+	return func(cfg *config) {
+		cfg.boolCase = c
+	}
+}
+
+// formatFloat returns the string representation of the finite float f,
+// whose type has bitSize bits, following WithFloatFormat and
+// WithDecimalSeparator.
+func (c *config) formatFloat(f float64, bitSize int) string {
+	var s string
+	if c.floatFormat != nil {
+		s = c.floatFormat(f, bitSize)
+	} else {
+		s = strconv.FormatFloat(f, 'g', -1, bitSize)
+	}
+	if c.decimalSeparator != "" {
+		s = strings.Replace(s, ".", c.decimalSeparator, 1)
+	}
+	return s
+}
+
+// separatorConflict returns an error if the values of a slice or array of
+// type t, joined by del, are floats holding del as their decimal separator.
+func (c *config) separatorConflict(t reflect.Type, del string) error {
+	if c.decimalSeparator == "" || !strings.Contains(c.decimalSeparator, del) {
+		return nil
+	}
+	switch et := elemType(t); et.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if !et.Implements(stringerType) {
+			return fmt.Errorf("decimal separator %q conflicts with the %q delimiter of the values", c.decimalSeparator, del)
+		}
+	}
+	return nil
+}
+
+// WithTagKey reads field names and options from struct tags with the given
+// key instead of "url".
+func WithTagKey(key string) Option {
+	return func(c *config) {
+		c.tagKey = key
+	}
+}
+
+// WithJSONTagFallback names fields without a url tag (or a tag for the key
+// given to WithTagKey) after their json tag, so that API model structs tagged
+// for encoding/json can be reused as is.  The options of json tags, such as
+// "omitempty" and "string", are ignored, as their meaning differs, and fields
+// tagged `json:"-"` are skipped.
+func WithJSONTagFallback() Option {
+	return func(c *config) {
+		c.jsonFallback = true
+	}
+}
+
+// WithGorillaSchema follows the conventions of the github.com/gorilla/schema
+// package, so that structs tagged for it can be encoded and decoded without
+// being re-tagged.  Names and options are read from "schema" tags, nested
+// fields are named "parent.child", and the fields of the elements of slices
+// of structs are named "parent.0.child", "parent.1.child" and so on.
+func WithGorillaSchema() Option {
+	return func(c *config) {
+		c.tagKey = "schema"
+		c.nestOpen, c.nestClose = ".", ""
+		c.indexStructs = true
+	}
+}
+
+// WithGRPCGateway follows the query parameter rules of grpc-gateway, so that
+// plain structs can be used by clients of gateway services.  Nested fields are
+// named by their dotted field path, as in "parent.child", and fields without
+// a name in their tag are named after their JSON name, the field name in
+// lowerCamelCase, as in "pageSize".  Slices are encoded as repeated
+// parameters.
+//
+// Times are formatted as in RFC 3339 with nanoseconds, and durations as
+// decimal seconds, as in "1.5s", matching the JSON forms of the well-known
+// Timestamp and Duration types.  Field masks are encoded from slices of paths
+// with the "comma" option.
+func WithGRPCGateway() Option {
+	return func(c *config) {
+		c.nestOpen, c.nestClose = ".", ""
+		c.camelNames = true
+		c.timeLayout = time.RFC3339Nano
+		c.durationSeconds = true
+	}
+}
+
+// ArrayFormat controls how Values encodes slices and arrays whose fields have
+// none of the "comma", "space", "semicolon", "brackets" and "numbered"
+// options.
+type ArrayFormat int
+
+const (
+	// ArrayRepeat repeats the parameter for each element, as in
+	// "a=x&a=y".  This is the default.
+	ArrayRepeat ArrayFormat = iota
+
+	// ArrayIndices scopes each element under its index, as in
+	// "a[0]=x&a[1]=y".  The fields of elements of slices of structs are
+	// scoped likewise, as in "a[0][name]=x".
+	ArrayIndices
+
+	// ArrayBrackets appends empty brackets to the name of each element, as
+	// in "a[]=x&a[]=y".  The fields of elements of slices of structs are
+	// named likewise, as in "a[][name]=x".
+	ArrayBrackets
+
+	// ArrayComma joins the elements with commas, as in "a=x,y", as the
+	// "comma" option does.  The fields of elements of slices of structs are
+	// scoped under their index, as with ArrayIndices.
+	ArrayComma
+)
+
+// WithArrayFormat sets how slices and arrays are encoded and decoded.
+func WithArrayFormat(format ArrayFormat) Option {
+	return func(c *config) {
+		c.arrayFormat = format
+	}
+}
+
+// WithDeepObject produces the deep object syntax of PHP's http_build_query
+// and of the qs library for Node.js, as in "filter[items][0][name]=x", so
+// that nested structs and slices of them can be sent to backends using
+// either.  Nested fields are named "parent[child]", and the elements of
+// slices and arrays are scoped under their index.
+func WithDeepObject() Option {
+	return func(c *config) {
+		c.nestOpen, c.nestClose = "[", "]"
+		c.arrayFormat = ArrayIndices
+	}
+}
+
+// WithRails follows the conventions of Ruby on Rails for nested parameters,
+// as in "a[b]=1" for nested fields, "a[]=1&a[]=2" for slices and
+// "a[][b]=1&a[][c]=2" for slices of structs, which Rails groups into an
+// array of hashes.
+//
+// When decoding slices of structs, the values of each parameter are assigned
+// to the elements in order, so every element must encode the same fields.
+func WithRails() Option {
+	return func(c *config) {
+		c.nestOpen, c.nestClose = "[", "]"
+		c.arrayFormat = ArrayBrackets
+	}
+}
+
+// WithStripe follows the form encoding of the Stripe API, as in
+// "expand[]=customer" for slices, "metadata[order_id]=6735" for maps and
+// nested fields, and "items[0][price]=price_1" for slices of structs, so that
+// clients of payment services using the same conventions produce the
+// parameters they expect.
+func WithStripe() Option {
+	return func(c *config) {
+		c.nestOpen, c.nestClose = "[", "]"
+		c.arrayFormat = ArrayBrackets
+		c.indexStructElems = true
+		c.nestedMaps = true
+	}
+}
+
+// WithNestedMaps encodes maps like nested structs, with one parameter per
+// entry named after its key, in the order of the keys, as in
+// "metadata[color]=red&metadata[size]=L".  Slices held by a map are encoded
+// as multiple values of the same name.  Decode reads maps back the same way.
+// Without it, maps are encoded by their default string representation, like
+// other values.  WithStripe implies WithNestedMaps.
+func WithNestedMaps() Option {
+	return func(c *config) {
+		c.nestedMaps = true
+	}
+}
+
+// QSOptions mirrors the options of the stringify function of the qs library
+// for Node.js that affect the produced query.  The zero value matches the
+// defaults of qs.
+type QSOptions struct {
+	// ArrayFormat is the arrayFormat option of qs: "indices" (the
+	// default), "brackets", "repeat" or "comma".
+	ArrayFormat string
+
+	// AllowDots names nested fields "parent.child" instead of
+	// "parent[child]".  Elements of slices keep their brackets, as in
+	// "parent.child[0]".
+	AllowDots bool
+
+	// EncodeValuesOnly leaves keys unescaped in the output of
+	// EncodeString, as in "a[0]=b" instead of "a%5B0%5D=b".
+	EncodeValuesOnly bool
+}
+
+// WithQS follows the rules of qs.stringify, configured by o, so that queries
+// produced in Go are parsed identically by the qs library, which Express uses
+// to parse queries.  It is meant to be used with EncodeString, which writes
+// parameters in field order and escapes them as qs does, percent-encoding
+// every character but "A-Z", "a-z", "0-9", "-", ".", "_" and "~".
+//
+// WithQS panics if o.ArrayFormat is not one of the formats of qs.
+func WithQS(o QSOptions) Option {
+	var format ArrayFormat
+	switch o.ArrayFormat {
+	case "", "indices":
+		format = ArrayIndices
+	case "brackets":
+		format = ArrayBrackets
+	case "repeat":
+		format = ArrayRepeat
+	case "comma":
+		format = ArrayComma
+	default:
+		panic("query: unknown qs arrayFormat " + strconv.Quote(o.ArrayFormat))
+	}
+
+	return func(c *config) {
+		c.nestOpen, c.nestClose = "[", "]"
+		if o.AllowDots {
+			c.nestOpen, c.nestClose = ".", ""
+		}
+		c.arrayFormat = format
+		c.indexStructs = true
+		c.bracketIndexes = true
+		c.escape = escapeRFC3986
+		c.rawKeys = o.EncodeValuesOnly
+	}
+}
+
+// WithJQuery follows the rules of jQuery.param, so that queries produced in Go
+// are identical to those sent by jQuery, which many older backends were built
+// to parse.  It is meant to be used with EncodeString, which writes parameters
+// in field order and escapes them as jQuery does, using the rules of
+// encodeURIComponent with spaces written as "+".
+//
+// By default jQuery names nested fields "parent[child]" and the elements of
+// slices "a[]", except for slices of structs whose elements are scoped under
+// their index, as in "a[0][child]".  With traditional set, as for
+// jQuery.param(obj, true), slices are encoded as repeated parameters instead.
+// jQuery then sends nested objects as the useless "[object Object]"; nested
+// structs are still encoded as "parent[child]".
+func WithJQuery(traditional bool) Option {
+	return func(c *config) {
+		c.nestOpen, c.nestClose = "[", "]"
+		c.arrayFormat = ArrayBrackets
+		c.indexStructElems = true
+		if traditional {
+			c.arrayFormat = ArrayRepeat
+			c.indexStructElems = false
+		}
+		c.escape = escapeURIComponent
+	}
+}
+
+// WithStrictEscaping makes EncodeString escape keys and values strictly as
+// RFC 3986 recommends: every byte outside the unreserved set of letters,
+// digits, "-", ".", "_" and "~" is percent-encoded with uppercase hex digits,
+// so spaces become "%20" rather than "+".  Request signing schemes and some
+// strict gateways require this form.  It takes precedence over the escaping
+// selected by earlier options such as WithQS and WithJQuery.
+func WithStrictEscaping() Option {
+	return func(c *config) {
+		c.escape = escapeRFC3986
+		c.rawKeys = false
+	}
+}
+
+// WithSemicolons makes EncodeString separate parameters with ";" rather than
+// "&", as in "a=1;b=2", and DecodeString accept both separators, as the HTML
+// 4 specification recommended.  Some legacy servers still expect this form.
+func WithSemicolons() Option {
+	return func(c *config) {
+		c.semicolons = true
+	}
+}
+
+// WithDedupe drops the values of a parameter that repeat an earlier value of
+// the same parameter, so that fields or Encoders producing the same pair
+// encode it only once, as in "tag=a&tag=b" rather than "tag=a&tag=b&tag=a".
+// Some rate-limiting proxies count redundant pairs against their limits.
+func WithDedupe() Option {
+	return func(c *config) {
+		c.dedupe = true
+	}
+}
+
+// dedupeValues removes the repeated values of each parameter of values,
+// keeping the first occurrences in order.
+func dedupeValues(values url.Values) {
+	for k, vs := range values {
+		seen := make(map[string]bool, len(vs))
+		kept := vs[:0]
+		for _, v := range vs {
+			if !seen[v] {
+				seen[v] = true
+				kept = append(kept, v)
+			}
+		}
+		values[k] = kept
+	}
+}
+
+// WithBareEmptyValues makes EncodeString and Canonicalize write parameters
+// with an empty value as their key alone, as in "a&b=2", rather than as
+// "a=&b=2".  Both forms are common and some servers tell them apart, for
+// instance treating a bare key as a flag.  Decoding accepts either form.
+func WithBareEmptyValues() Option {
+	return func(c *config) {
+		c.bareEmpty = true
+	}
+}
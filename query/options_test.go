@@ -0,0 +1,877 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValues_embeddedOrder(t *testing.T) {
+	tests := []struct {
+		in    interface{}
+		order EmbeddedOrder
+		want  url.Values
+	}{
+		{
+			D{B: B{C: "bar"}, C: "foo"},
+			EmbeddedLast,
+			url.Values{"C": {"foo", "bar"}},
+		},
+		{
+			D{B: B{C: "bar"}, C: "foo"},
+			EmbeddedInline,
+			url.Values{"C": {"bar", "foo"}},
+		},
+		{
+			F{e{B: B{C: "bar"}, C: "foo"}},
+			EmbeddedInline,
+			url.Values{"C": {"bar", "foo"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(tt.in, WithEmbeddedOrder(tt.order))
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, tt.in, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestValues_embeddedNaming(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		naming EmbeddedNaming
+		want   url.Values
+	}{
+		{
+			H{B: B{C: "bar"}, C: "foo"},
+			EmbeddedScoped,
+			url.Values{"b[C]": {"bar"}, "C": {"foo"}},
+		},
+		{
+			H{B: B{C: "bar"}, C: "foo"},
+			EmbeddedFlatten,
+			url.Values{"C": {"foo", "bar"}},
+		},
+		{
+			struct {
+				*B `url:"b"`
+			}{&B{C: "bar"}},
+			EmbeddedFlatten,
+			url.Values{"C": {"bar"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(tt.in, WithEmbeddedNaming(tt.naming))
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, tt.in, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, tt.in, v, tt.want)
+		}
+	}
+}
+
+type failingEncoder string
+
+func (f failingEncoder) EncodeValues(key string, v *url.Values) error {
+	return errors.New(string(f))
+}
+
+func TestValues_collectErrors(t *testing.T) {
+	s := struct {
+		A failingEncoder `url:"a"`
+		B string         `url:"b"`
+		C struct {
+			D failingEncoder `url:"d"`
+		} `url:"c"`
+	}{A: "bad a", B: "b"}
+	s.C.D = "bad d"
+
+	_, err := Values(s)
+	if err == nil || err.Error() != "bad a" {
+		t.Errorf("Values(%v) returned error %v, want bad a", s, err)
+	}
+
+	v, err := Values(s, WithCollectErrors())
+	if err == nil {
+		t.Fatalf("Values(%v) returned no error", s)
+	}
+	want := url.Values{"b": {"b"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	var names []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			t.Fatalf("error %v is not a *FieldError", err)
+		}
+		names = append(names, fe.Name)
+	}
+	if want := []string{"a", "c[d]"}; !reflect.DeepEqual(want, names) {
+		t.Errorf("Values(%v) returned errors for %v, want %v", s, names, want)
+	}
+}
+
+func TestValues_collisionCheck(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string // error, or "" for none
+	}{
+		{
+			struct {
+				A []string `url:"a"`
+				B string   `url:"b"`
+				N struct {
+					A string `url:"a"`
+				} `url:"n"`
+			}{A: []string{"x", "y"}},
+			"",
+		},
+		{
+			D{B: B{C: "bar"}, C: "foo"},
+			`query: parameter "C": field B.C collides with field C`,
+		},
+		{
+			struct {
+				A string `url:"n[a]"`
+				N struct {
+					A string `url:"a"`
+				} `url:"n"`
+			}{},
+			`query: parameter "n[a]": field N.A collides with field A`,
+		},
+		{
+			struct {
+				A EncodedArgs `url:"a"`
+				B string      `url:"a"`
+			}{},
+			`query: parameter "a": field B collides with field A`,
+		},
+	}
+
+	for i, tt := range tests {
+		_, err := Values(tt.in, WithCollisionCheck())
+		if got := fmt.Sprint(err); tt.want == "" && err != nil || tt.want != "" && got != tt.want {
+			t.Errorf("%d. Values(%v) returned error %v, want %q", i, tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestValues_nonFiniteFloats(t *testing.T) {
+	s := struct {
+		A float64   `url:"a"`
+		B float32   `url:"b"`
+		C []float64 `url:"c,comma"`
+		D []float64 `url:"d"`
+		E float64   `url:"e"`
+	}{
+		A: math.NaN(),
+		B: float32(math.Inf(1)),
+		C: []float64{1, math.Inf(-1), 2},
+		D: []float64{math.NaN(), 3},
+		E: 1.5,
+	}
+
+	tests := []struct {
+		opts []Option
+		want url.Values
+	}{
+		{
+			nil,
+			url.Values{"a": {"NaN"}, "b": {"+Inf"}, "c": {"1,-Inf,2"}, "d": {"NaN", "3"}, "e": {"1.5"}},
+		},
+		{
+			[]Option{WithNonFiniteFloats(NonFiniteOmit)},
+			url.Values{"c": {"1,2"}, "d": {"3"}, "e": {"1.5"}},
+		},
+		{
+			[]Option{WithNonFiniteReplacement("null")},
+			url.Values{"a": {"null"}, "b": {"null"}, "c": {"1,null,2"}, "d": {"null", "3"}, "e": {"1.5"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(s, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, s, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, s, v, tt.want)
+		}
+	}
+
+	_, err := Values(s, WithNonFiniteFloats(NonFiniteError))
+	if want := `query: parameter "a": non-finite float value NaN`; err == nil || err.Error() != want {
+		t.Errorf("Values(%v) returned error %v, want %q", s, err, want)
+	}
+
+	_, err = Values(s, WithNonFiniteFloats(NonFiniteError), WithCollectErrors())
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 4 {
+		t.Errorf("Values(%v) returned %d errors, want 4: %v", s, n, err)
+	}
+}
+
+type celsius float64
+
+func (c celsius) String() string { return strconv.FormatFloat(float64(c), 'f', 1, 64) + "C" }
+
+func TestValues_floatFormat(t *testing.T) {
+	s := struct {
+		A float64   `url:"a"`
+		B float32   `url:"b"`
+		C []float64 `url:"c,semicolon"`
+		D float64   `url:"d"`
+		E celsius   `url:"e"`
+		F int       `url:"f"`
+	}{
+		A: 1.5,
+		B: 0.1,
+		C: []float64{1, 2.25},
+		D: math.NaN(),
+		E: 20,
+		F: 3,
+	}
+
+	tests := []struct {
+		opts []Option
+		want url.Values
+	}{
+		{
+			[]Option{WithDecimalSeparator(",")},
+			url.Values{"a": {"1,5"}, "b": {"0,1"}, "c": {"1;2,25"}, "d": {"NaN"}, "e": {"20.0C"}, "f": {"3"}},
+		},
+		{
+			[]Option{WithFloatFormat(func(f float64, bitSize int) string {
+				return strconv.FormatFloat(f, 'f', 2, bitSize)
+			}), WithNonFiniteReplacement("")},
+			url.Values{"a": {"1.50"}, "b": {"0.10"}, "c": {"1.00;2.25"}, "d": {""}, "e": {"20.0C"}, "f": {"3"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(s, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, s, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, s, v, tt.want)
+		}
+	}
+}
+
+func TestDecimalSeparator_delimiters(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		opts []Option
+	}{
+		{struct {
+			F []float64 `url:"f,comma"`
+		}{[]float64{1.5, 2.5}}, nil},
+		{struct {
+			F []float32 `url:"f"`
+		}{[]float32{1.5, 2.5}}, []Option{WithArrayFormat(ArrayComma)}},
+		{struct {
+			F []float64 `url:"f,style=form,explode=false"`
+		}{[]float64{1.5, 2.5}}, nil},
+	}
+	for i, tt := range tests {
+		opts := append(tt.opts, WithDecimalSeparator(","))
+		_, err := Values(tt.in, opts...)
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Name != "f" {
+			t.Errorf("%d. Values(%v) returned error %v, want a *FieldError for f", i, tt.in, err)
+		}
+	}
+
+	// Decode accepts the separator
+	in := struct {
+		A float64   `url:"a"`
+		C []float64 `url:"c,semicolon"`
+	}{1.5, []float64{1, 2.25}}
+	values, err := Values(in, WithDecimalSeparator(","))
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	out := in
+	out.A, out.C = 0, nil
+	if err := Decode(values, &out, WithDecimalSeparator(",")); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", values, err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", values, out, in)
+	}
+}
+
+func TestValues_fixedPointFloats(t *testing.T) {
+	s := struct {
+		A float64   `url:"a"`
+		B float32   `url:"b"`
+		C []float64 `url:"c"`
+	}{
+		A: 0.0000001,
+		B: 1e21,
+		C: []float64{2.5, -0.0000001, 1.23456789},
+	}
+
+	tests := []struct {
+		opts []Option
+		want url.Values
+	}{
+		{
+			nil,
+			url.Values{"a": {"1e-07"}, "b": {"1e+21"}, "c": {"2.5", "-1e-07", "1.23456789"}},
+		},
+		{
+			[]Option{WithFixedPointFloats(-1)},
+			url.Values{"a": {"0.0000001"}, "b": {"1000000000000000000000"}, "c": {"2.5", "-0.0000001", "1.23456789"}},
+		},
+		{
+			[]Option{WithFixedPointFloats(3)},
+			url.Values{"a": {"0"}, "b": {"1000000000000000000000"}, "c": {"2.5", "0", "1.235"}},
+		},
+		{
+			[]Option{WithDecimalSeparator(","), WithFixedPointFloats(3)},
+			url.Values{"a": {"0"}, "b": {"1000000000000000000000"}, "c": {"2,5", "0", "1,235"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(s, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, s, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, s, v, tt.want)
+		}
+	}
+}
+
+type shout string
+
+func (s shout) String() string { return strings.ToUpper(string(s)) + "!" }
+
+func TestValues_stringNormalizer(t *testing.T) {
+	// compose composes "e" followed by a combining acute accent, as NFC
+	// does.
+	compose := func(s string) string {
+		return strings.ReplaceAll(s, "e\u0301", "\u00e9")
+	}
+	type named string
+	s := struct {
+		A string            `url:"a"`
+		B []string          `url:"b,comma"`
+		C map[string]string `url:"c"`
+		D *named            `url:"d"`
+		E shout             `url:"e"`
+	}{
+		A: "cafe\u0301",
+		B: []string{"e\u0301", "x"},
+		C: map[string]string{"k": "e\u0301"},
+		D: new(named),
+		E: "e\u0301",
+	}
+	*s.D = "re\u0301sume\u0301"
+
+	v, err := Values(s, WithStringNormalizer(compose), WithNestedMaps())
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	want := url.Values{
+		"a":    {"caf\u00e9"},
+		"b":    {"\u00e9,x"},
+		"c[k]": {"\u00e9"},
+		"d":    {"r\u00e9sum\u00e9"},
+		"e":    {"E\u0301!"},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Values returned %q, want %q", v, want)
+	}
+}
+
+func TestValues_boolCase(t *testing.T) {
+	s := struct {
+		A bool   `url:"a"`
+		B []bool `url:"b,comma"`
+		C bool   `url:"c,int"`
+		D *bool  `url:"d"`
+	}{
+		A: true,
+		B: []bool{false, true},
+		C: true,
+		D: new(bool),
+	}
+
+	tests := []struct {
+		c    BoolCase
+		want url.Values
+	}{
+		{BoolLower, url.Values{"a": {"true"}, "b": {"false,true"}, "c": {"1"}, "d": {"false"}}},
+		{BoolTitle, url.Values{"a": {"True"}, "b": {"False,True"}, "c": {"1"}, "d": {"False"}}},
+		{BoolUpper, url.Values{"a": {"TRUE"}, "b": {"FALSE,TRUE"}, "c": {"1"}, "d": {"FALSE"}}},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(s, WithBoolCase(tt.c))
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, s, err)
+		}
+
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, s, v, tt.want)
+		}
+	}
+}
+
+type gorillaItem struct {
+	Name  string `schema:"name"`
+	Price int    `schema:"price,omitempty"`
+}
+
+type gorillaForm struct {
+	Query   string        `schema:"q,required"`
+	Tags    []string      `schema:"tag"`
+	Items   []gorillaItem `schema:"items"`
+	Address struct {
+		City string `schema:"city"`
+	} `schema:"address"`
+	Ignored string `schema:"-"`
+}
+
+func TestGorillaSchema(t *testing.T) {
+	in := gorillaForm{
+		Query: "foo",
+		Tags:  []string{"a", "b"},
+		Items: []gorillaItem{{"x", 1}, {"y", 0}},
+	}
+	in.Address.City = "SFO"
+
+	v, err := Values(in, WithGorillaSchema())
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{
+		"q":             {"foo"},
+		"tag":           {"a", "b"},
+		"items.0.name":  {"x"},
+		"items.0.price": {"1"},
+		"items.1.name":  {"y"},
+		"address.city":  {"SFO"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	var out gorillaForm
+	if err := Decode(v, &out, WithGorillaSchema()); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+
+	delete(v, "q")
+	if err := Decode(v, &out, WithGorillaSchema()); err == nil {
+		t.Errorf("Decode(%v) returned no error for missing required parameter", v)
+	}
+}
+
+type gatewayRequest struct {
+	PageSize   int
+	ID         string
+	URLPath    string
+	UpdateMask []string `url:"update_mask,comma"`
+	Filter     struct {
+		MinScore float64
+	}
+	Labels  []string
+	Created time.Time
+	Timeout time.Duration
+}
+
+func TestGRPCGateway(t *testing.T) {
+	in := gatewayRequest{
+		PageSize:   10,
+		ID:         "x",
+		URLPath:    "/a",
+		UpdateMask: []string{"name", "address.city"},
+		Labels:     []string{"a", "b"},
+		Created:    time.Date(2000, 1, 1, 12, 34, 56, 500, time.UTC),
+		Timeout:    90*time.Second + 500*time.Millisecond,
+	}
+	in.Filter.MinScore = 0.5
+
+	v, err := Values(in, WithGRPCGateway())
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{
+		"pageSize":        {"10"},
+		"id":              {"x"},
+		"urlPath":         {"/a"},
+		"update_mask":     {"name,address.city"},
+		"filter.minScore": {"0.5"},
+		"labels":          {"a", "b"},
+		"created":         {"2000-01-01T12:34:56.0000005Z"},
+		"timeout":         {"90.5s"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	var out gatewayRequest
+	if err := Decode(v, &out, WithGRPCGateway()); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+}
+
+type deepObjectItem struct {
+	Name  string `url:"name"`
+	Price int    `url:"price"`
+}
+
+type deepObjectFilter struct {
+	Status []string         `url:"status"`
+	Items  []deepObjectItem `url:"items"`
+	Range  struct {
+		Min int `url:"min"`
+	} `url:"range"`
+}
+
+func TestDeepObject(t *testing.T) {
+	in := struct {
+		Filter deepObjectFilter `url:"filter"`
+		IDs    []int            `url:"ids,comma"`
+		Tags   []string         `url:"tags,brackets"`
+	}{
+		Filter: deepObjectFilter{
+			Status: []string{"open", "closed"},
+			Items:  []deepObjectItem{{"x", 1}, {"y", 2}},
+		},
+		IDs:  []int{1, 2},
+		Tags: []string{"a"},
+	}
+	in.Filter.Range.Min = 3
+
+	v, err := Values(in, WithDeepObject())
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{
+		"filter[status][0]":       {"open"},
+		"filter[status][1]":       {"closed"},
+		"filter[items][0][name]":  {"x"},
+		"filter[items][0][price]": {"1"},
+		"filter[items][1][name]":  {"y"},
+		"filter[items][1][price]": {"2"},
+		"filter[range][min]":      {"3"},
+		"ids":                     {"1,2"},
+		"tags[]":                  {"a"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	out := in
+	out.Filter = deepObjectFilter{}
+	out.IDs, out.Tags = nil, nil
+	if err := Decode(v, &out, WithDeepObject()); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+}
+
+func TestRails(t *testing.T) {
+	in := struct {
+		Items  []deepObjectItem `url:"items"`
+		Tags   []string         `url:"tags"`
+		Filter struct {
+			IDs []int `url:"ids"`
+		} `url:"filter"`
+	}{
+		Items: []deepObjectItem{{"x", 1}, {"y", 2}},
+		Tags:  []string{"a", "b"},
+	}
+	in.Filter.IDs = []int{3}
+
+	v, err := Values(in, WithRails())
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{
+		"items[][name]":  {"x", "y"},
+		"items[][price]": {"1", "2"},
+		"tags[]":         {"a", "b"},
+		"filter[ids][]":  {"3"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	out := in
+	out.Items, out.Tags, out.Filter.IDs = nil, nil, nil
+	if err := Decode(v, &out, WithRails()); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+}
+
+func TestStripe(t *testing.T) {
+	in := struct {
+		Expand   []string          `url:"expand,omitempty"`
+		Metadata map[string]string `url:"metadata,omitempty"`
+		Items    []deepObjectItem  `url:"items,omitempty"`
+	}{
+		Expand:   []string{"customer", "invoice"},
+		Metadata: map[string]string{"order_id": "6735"},
+		Items:    []deepObjectItem{{"x", 1}, {"y", 2}},
+	}
+
+	v, err := Values(in, WithStripe())
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{
+		"expand[]":           {"customer", "invoice"},
+		"metadata[order_id]": {"6735"},
+		"items[0][name]":     {"x"},
+		"items[0][price]":    {"1"},
+		"items[1][name]":     {"y"},
+		"items[1][price]":    {"2"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	out := in
+	out.Expand, out.Metadata, out.Items = nil, nil, nil
+	if err := Decode(v, &out, WithStripe()); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+}
+
+func TestQS(t *testing.T) {
+	type item struct {
+		B string `url:"b"`
+	}
+	in := struct {
+		A []string `url:"a"`
+		N struct {
+			C string `url:"c"`
+			E string `url:"e"`
+		} `url:"n"`
+		Items []item `url:"items"`
+		S     string `url:"s"`
+	}{
+		A:     []string{"b", "c"},
+		Items: []item{{"x"}},
+		S:     "b c",
+	}
+	in.N.C, in.N.E = "d", "f"
+
+	tests := []struct {
+		opts QSOptions
+		want string
+	}{
+		{
+			QSOptions{},
+			"a%5B0%5D=b&a%5B1%5D=c&n%5Bc%5D=d&n%5Be%5D=f&items%5B0%5D%5Bb%5D=x&s=b%20c",
+		},
+		{
+			QSOptions{EncodeValuesOnly: true},
+			"a[0]=b&a[1]=c&n[c]=d&n[e]=f&items[0][b]=x&s=b%20c",
+		},
+		{
+			QSOptions{ArrayFormat: "brackets", EncodeValuesOnly: true},
+			"a[]=b&a[]=c&n[c]=d&n[e]=f&items[][b]=x&s=b%20c",
+		},
+		{
+			QSOptions{ArrayFormat: "repeat", EncodeValuesOnly: true},
+			"a=b&a=c&n[c]=d&n[e]=f&items[b]=x&s=b%20c",
+		},
+		{
+			QSOptions{ArrayFormat: "comma"},
+			"a=b%2Cc&n%5Bc%5D=d&n%5Be%5D=f&items%5B0%5D%5Bb%5D=x&s=b%20c",
+		},
+		{
+			QSOptions{AllowDots: true, EncodeValuesOnly: true},
+			"a[0]=b&a[1]=c&n.c=d&n.e=f&items[0].b=x&s=b%20c",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := EncodeString(in, WithQS(tt.opts))
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v, %+v) returned\n%s\nwant\n%s", i, in, tt.opts, got, tt.want)
+		}
+
+		values, err := url.ParseQuery(got)
+		if err != nil {
+			t.Fatalf("%d. url.ParseQuery(%q) returned error: %v", i, got, err)
+		}
+		out := in
+		out.A, out.Items, out.S = nil, nil, ""
+		out.N.C, out.N.E = "", ""
+		if err := Decode(values, &out, WithQS(tt.opts)); err != nil {
+			t.Errorf("%d. Decode(%v) returned error: %v", i, values, err)
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Errorf("%d. Decode(%v) decoded %+v, want %+v", i, values, out, in)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WithQS with an unknown array format did not panic")
+		}
+	}()
+	WithQS(QSOptions{ArrayFormat: "unknown"})
+}
+
+func TestJQuery(t *testing.T) {
+	type item struct {
+		B string `url:"b"`
+	}
+	in := struct {
+		A     []string           `url:"a"`
+		N     struct{ C string } `url:"n"`
+		Items []item             `url:"items"`
+		S     string             `url:"s"`
+	}{
+		A:     []string{"1", "2"},
+		Items: []item{{"x"}, {"y"}},
+		S:     "it's a (b)*c!",
+	}
+	in.N.C = "d"
+
+	tests := []struct {
+		traditional bool
+		want        string
+	}{
+		{false, "a%5B%5D=1&a%5B%5D=2&n%5BC%5D=d&items%5B0%5D%5Bb%5D=x&items%5B1%5D%5Bb%5D=y&s=it's+a+(b)*c!"},
+		{true, "a=1&a=2&n%5BC%5D=d&items=%7Bx%7D&items=%7By%7D&s=it's+a+(b)*c!"},
+	}
+
+	for i, tt := range tests {
+		got, err := EncodeString(in, WithJQuery(tt.traditional))
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v, WithJQuery(%v)) returned\n%s\nwant\n%s", i, in, tt.traditional, got, tt.want)
+		}
+	}
+
+	values, err := url.ParseQuery(tests[0].want)
+	if err != nil {
+		t.Fatalf("url.ParseQuery returned error: %v", err)
+	}
+	out := in
+	out.A, out.Items, out.S, out.N.C = nil, nil, "", ""
+	if err := Decode(values, &out, WithJQuery(false)); err != nil {
+		t.Errorf("Decode(%v) returned error: %v", values, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", values, out, in)
+	}
+}
+
+func TestStrictEscaping(t *testing.T) {
+	in := struct {
+		Q    string   `url:"q"`
+		Tags []string `url:"tags,brackets"`
+	}{"a b+c*é~-._!'()", []string{"x/y"}}
+
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "q=a+b%2Bc%2A%C3%A9~-._%21%27%28%29&tags%5B%5D=x%2Fy"},
+		{[]Option{WithStrictEscaping()}, "q=a%20b%2Bc%2A%C3%A9~-._%21%27%28%29&tags%5B%5D=x%2Fy"},
+		{[]Option{WithQS(QSOptions{EncodeValuesOnly: true}), WithStrictEscaping()}, "q=a%20b%2Bc%2A%C3%A9~-._%21%27%28%29&tags%5B%5D=x%2Fy"},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v) returned %q, want %q", i, in, got, tt.want)
+		}
+	}
+}
+
+func TestJSONTagFallback(t *testing.T) {
+	type model struct {
+		ID      int    `json:"id"`
+		Name    string `json:"name,omitempty" url:"n"`
+		Secret  string `json:"-"`
+		Count   int    `json:",omitempty"`
+		Created string `json:"created_at,omitempty"`
+		Trace   string `json:"trace" header:"X-Trace"`
+	}
+	in := model{ID: 1, Name: "x", Secret: "s", Count: 2, Trace: "t"}
+
+	v, err := Values(in, WithJSONTagFallback())
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	want := url.Values{"id": {"1"}, "n": {"x"}, "Count": {"2"}, "created_at": {""}, "trace": {"t"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	var out model
+	if err := Decode(v, &out, WithJSONTagFallback()); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	in.Secret = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", v, out, in)
+	}
+
+	h, err := Headers(in, WithJSONTagFallback())
+	if err != nil {
+		t.Fatalf("Headers(%v) returned error: %v", in, err)
+	}
+	if len(h) != 1 || h.Get("X-Trace") != "t" {
+		t.Errorf("Headers(%v) returned %v, want only X-Trace", in, h)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package otelquery records OpenTelemetry metrics for the encodings and
+// decodings of the query package.
+//
+// Create an Observer from a Meter and install it for all encodings, or pass
+// it to individual ones with query.WithObserver:
+//
+//	o, err := otelquery.NewObserver(otel.Meter(otelquery.ScopeName))
+//	if err != nil {
+//		return err
+//	}
+//	query.SetObserver(o)
+//
+// The following instruments are recorded, with an "op" attribute of "encode"
+// or "decode" and a "type" attribute naming the Go type:
+//
+//	querystring.duration  histogram of the time taken, in seconds
+//	querystring.params    histogram of the number of parameter values
+//	querystring.errors    counter of the operations returning an error
+//
+// The error rate is the querystring.errors count divided by the
+// querystring.duration count.
+package otelquery
+
+import (
+	"context"
+
+	"github.com/google/go-querystring/query"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope name suggested for the Meter passed
+// to NewObserver.
+const ScopeName = "github.com/google/go-querystring/query/otelquery"
+
+// Observer is a query.Observer recording OpenTelemetry metrics.
+type Observer struct {
+	duration metric.Float64Histogram
+	params   metric.Int64Histogram
+	errors   metric.Int64Counter
+}
+
+// NewObserver returns an Observer recording its instruments with m.
+func NewObserver(m metric.Meter) (*Observer, error) {
+	duration, err := m.Float64Histogram("querystring.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time taken to encode or decode a query."))
+	if err != nil {
+		return nil, err
+	}
+	params, err := m.Int64Histogram("querystring.params",
+		metric.WithUnit("{param}"),
+		metric.WithDescription("Number of parameter values encoded or decoded."))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := m.Int64Counter("querystring.errors",
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Number of encodings and decodings returning an error."))
+	if err != nil {
+		return nil, err
+	}
+	return &Observer{duration: duration, params: params, errors: errors}, nil
+}
+
+// Observe records the metrics of s.
+func (o *Observer) Observe(s query.Stats) {
+	ctx := context.Background()
+	attrs := metric.WithAttributes(
+		attribute.String("op", string(s.Op)),
+		attribute.String("type", typeName(s)),
+	)
+	o.duration.Record(ctx, s.Duration.Seconds(), attrs)
+	o.params.Record(ctx, int64(s.Params), attrs)
+	if s.Err != nil {
+		o.errors.Add(ctx, 1, attrs)
+	}
+}
+
+// typeName returns the name of the type of s, or "" if unknown.
+func typeName(s query.Stats) string {
+	if s.Type == nil {
+		return ""
+	}
+	return s.Type.String()
+}
@@ -0,0 +1,110 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package otelquery
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-querystring/query"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// recorder is a metric.Meter recording the measurements of its instruments
+// by instrument name and "op" attribute.
+type recorder struct {
+	noop.Meter
+	got map[string]float64
+}
+
+func (r *recorder) record(name string, v float64, opts []metric.RecordOption) {
+	attrs := metric.NewRecordConfig(opts).Attributes()
+	op, _ := attrs.Value("op")
+	r.got[name+" "+op.AsString()] += v
+}
+
+func (r *recorder) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return float64Histogram{r: r, name: name}, nil
+}
+
+func (r *recorder) Int64Histogram(name string, _ ...metric.Int64HistogramOption) (metric.Int64Histogram, error) {
+	return int64Histogram{r: r, name: name}, nil
+}
+
+func (r *recorder) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return int64Counter{r: r, name: name}, nil
+}
+
+type float64Histogram struct {
+	noop.Float64Histogram
+	r    *recorder
+	name string
+}
+
+func (h float64Histogram) Record(_ context.Context, _ float64, opts ...metric.RecordOption) {
+	h.r.record(h.name, 1, opts)
+}
+
+type int64Histogram struct {
+	noop.Int64Histogram
+	r    *recorder
+	name string
+}
+
+func (h int64Histogram) Record(_ context.Context, v int64, opts ...metric.RecordOption) {
+	h.r.record(h.name, float64(v), opts)
+}
+
+type int64Counter struct {
+	noop.Int64Counter
+	r    *recorder
+	name string
+}
+
+func (c int64Counter) Add(_ context.Context, v int64, opts ...metric.AddOption) {
+	attrs := metric.NewAddConfig(opts).Attributes()
+	op, _ := attrs.Value("op")
+	c.r.got[c.name+" "+op.AsString()] += float64(v)
+}
+
+func TestObserver(t *testing.T) {
+	r := &recorder{got: make(map[string]float64)}
+	o, err := NewObserver(r)
+	if err != nil {
+		t.Fatalf("NewObserver returned error: %v", err)
+	}
+
+	type Options struct {
+		Q    string   `url:"q"`
+		Tags []string `url:"tag"`
+		N    int      `url:"n"`
+	}
+	if _, err := query.Values(Options{Q: "go", Tags: []string{"a", "b"}}, query.WithObserver(o)); err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	var out Options
+	if err := query.Decode(url.Values{"n": {"x"}}, &out, query.WithObserver(o)); err == nil {
+		t.Fatalf("Decode returned no error for an invalid int")
+	}
+
+	// querystring.duration counts the operations recorded
+	want := map[string]float64{
+		"querystring.duration encode": 1,
+		"querystring.params encode":   4,
+		"querystring.duration decode": 1,
+		"querystring.params decode":   1,
+		"querystring.errors decode":   1,
+	}
+	for k, v := range want {
+		if r.got[k] != v {
+			t.Errorf("recorded %v for %s, want %v", r.got[k], k, v)
+		}
+	}
+	if len(r.got) != len(want) {
+		t.Errorf("recorded %v, want %v", r.got, want)
+	}
+}
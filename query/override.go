@@ -0,0 +1,102 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// Override maps URL parameter names to values replacing those encoded from
+// the fields of a struct, for a single call:
+//
+//	v, err := query.Values(opts, query.WithOverrides(query.Override{
+//		"page":  "3",
+//		"debug": nil,
+//	}))
+//
+// A nil value removes the parameter, while Null sets it to an empty value.
+// A string or a []string replaces the values of the parameter, which is
+// added if no field produces it.  Other values are formatted with fmt.Sprint,
+// or their String method.
+type Override map[string]interface{}
+
+// ValuesWith encodes v like Values and layers the parameters of extra on
+// top, replacing the values of the parameters that v also produces.  The
+// extra parameters take precedence over the fields of v, and the overrides
+// of WithOverrides options over both.  Like overrides, they are subject to
+// the limits and reserved names set by other options.
+func ValuesWith(v interface{}, extra map[string]string, opts ...Option) (url.Values, error) {
+	o := make(Override, len(extra))
+	for k, s := range extra {
+		o[k] = s
+	}
+	return Values(v, append([]Option{WithOverrides(o)}, opts...)...)
+}
+
+// overrideValues returns the values v sets a parameter to, or nil if it
+// removes the parameter.
+func overrideValues(v interface{}) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		if v == nil {
+			return nil
+		}
+		return append([]string{}, v...)
+	case fmt.Stringer:
+		return []string{v.String()}
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+// WithOverrides replaces or removes the encoded parameters named in o, so
+// that individual parameters can be forced or suppressed without changing
+// the struct encoded.  The overrides are applied once all fields are
+// encoded, before the parameters are checked against the limits and
+// reserved names set by other options.  Overrides given by several
+// WithOverrides options are combined, later ones taking precedence.
+func WithOverrides(o Override) Option {
+	return func(c *config) {
+		if c.overrides == nil {
+			c.overrides = make(Override, len(o))
+		}
+		for k, v := range o {
+			c.overrides[k] = v
+		}
+	}
+}
+
+// applyOverrides applies o to values, returning the names of the parameters
+// set, in sorted order.
+func applyOverrides(values url.Values, o Override) []string {
+	var set []string
+	for k, v := range o {
+		vs := overrideValues(v)
+		if vs == nil {
+			delete(values, k)
+			continue
+		}
+		values[k] = vs
+		set = append(set, k)
+	}
+	sort.Strings(set)
+	return set
+}
+
+// applyOverrides applies e.overrides to values.  Parameters added are
+// ordered after those of fields, and attributed to the Override in errors.
+func (e *encoder) applyOverrides(values url.Values) {
+	for _, k := range applyOverrides(values, e.overrides) {
+		e.record(k)
+		if e.keyFields != nil {
+			e.keyFields[k] = "Override"
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithOverrides(t *testing.T) {
+	type list struct {
+		Page  int    `url:"page"`
+		Debug bool   `url:"debug"`
+		Q     string `url:"q"`
+	}
+	opts := list{Page: 1, Debug: true, Q: "go"}
+	tests := []struct {
+		overrides []Override
+		want      string
+	}{
+		{nil, "page=1&debug=true&q=go"},
+		{[]Override{{"page": "3", "debug": nil}}, "page=3&q=go"},
+		{[]Override{{"q": []string{"a", "b"}, "b": 2, "a": time.Second}}, "page=1&debug=true&q=a&q=b&a=1s&b=2"},
+		{[]Override{{"page": "3"}, {"page": nil}}, "debug=true&q=go"},
+		{[]Override{{"q": []string(nil)}}, "page=1&debug=true"},
+	}
+	for i, tt := range tests {
+		var options []Option
+		for _, o := range tt.overrides {
+			options = append(options, WithOverrides(o))
+		}
+		got, err := EncodeString(opts, options...)
+		if err != nil {
+			t.Errorf("%d. EncodeString returned error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v) returned %q, want %q", i, tt.overrides, got, tt.want)
+		}
+	}
+
+	if opts != (list{Page: 1, Debug: true, Q: "go"}) {
+		t.Errorf("overrides changed the struct: %+v", opts)
+	}
+
+	_, err := Values(opts, WithOverrides(Override{"sig": "x"}), WithReservedKeys("sig"))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Name != "sig" {
+		t.Errorf("Values with a reserved override returned error %v, want a *FieldError for sig", err)
+	}
+}
+
+func TestMerge_Overrides(t *testing.T) {
+	type a struct {
+		Page int `url:"page"`
+	}
+	type b struct {
+		Q string `url:"q"`
+	}
+	got, err := Merge(a{1}, b{"go"}, WithOverrides(Override{"page": "2", "q": nil}))
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if want := (url.Values{"page": {"2"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge returned %v, want %v", got, want)
+	}
+}
+
+func TestValuesWith(t *testing.T) {
+	type list struct {
+		Page int    `url:"page"`
+		Q    string `url:"q"`
+	}
+	tests := []struct {
+		extra map[string]string
+		opts  []Option
+		want  url.Values
+	}{
+		{nil, nil, url.Values{"page": {"1"}, "q": {"go"}}},
+		{map[string]string{"page": "2", "sort": "asc"}, nil, url.Values{"page": {"2"}, "q": {"go"}, "sort": {"asc"}}},
+		{map[string]string{"page": "2"}, []Option{WithOverrides(Override{"page": "3", "q": nil})}, url.Values{"page": {"3"}}},
+	}
+	for i, tt := range tests {
+		got, err := ValuesWith(list{1, "go"}, tt.extra, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. ValuesWith(%v) returned error: %v", i, tt.extra, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. ValuesWith(%v) returned %v, want %v", i, tt.extra, got, tt.want)
+		}
+	}
+
+	if _, err := ValuesWith(list{}, map[string]string{"sig": "x"}, WithReservedKeys("sig")); err == nil {
+		t.Errorf("ValuesWith with a reserved extra parameter did not return an error")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PerPage holds the number of results per page requested from paginated APIs.
+// It is usually embedded in Page or Cursor.
+type PerPage struct {
+	Size int `url:"per_page,omitempty"`
+}
+
+// Page selects a page of results by number, as in "?page=2&per_page=50".  It
+// is meant to be embedded in the option structs of paginated API calls:
+//
+//	type ListOptions struct {
+//		query.Page
+//		State string `url:"state,omitempty"`
+//	}
+type Page struct {
+	Number int `url:"page,omitempty"`
+	PerPage
+}
+
+// Cursor selects a page of results by an opaque cursor returned by a
+// previous call, as in "?cursor=abc&per_page=50".
+type Cursor struct {
+	Value string `url:"cursor,omitempty"`
+	PerPage
+}
+
+// ParseLinks parses a Link header, as defined by RFC 8288 (formerly RFC
+// 5988), and returns the target URL of each link by relation type, such as
+// "next" or "last".  Links with several relation types are returned under
+// each of them.  If several links share a relation type, the first one wins.
+func ParseLinks(header string) (map[string]string, error) {
+	links := make(map[string]string)
+	s := header
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return links, nil
+		}
+		if s[0] != '<' {
+			return nil, fmt.Errorf("query: malformed Link header %q", header)
+		}
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return nil, fmt.Errorf("query: malformed Link header %q", header)
+		}
+		target := s[1:end]
+		s = s[end+1:]
+
+		// link parameters, up to the next link
+		var rels []string
+		for {
+			s = strings.TrimLeft(s, " \t")
+			if s == "" || s[0] == ',' {
+				break
+			}
+			if s[0] != ';' {
+				return nil, fmt.Errorf("query: malformed Link header %q", header)
+			}
+			var name, value string
+			name, value, s = linkParam(strings.TrimLeft(s[1:], " \t"))
+			if strings.EqualFold(name, "rel") {
+				rels = strings.Fields(value)
+			}
+		}
+
+		for _, rel := range rels {
+			rel = strings.ToLower(rel)
+			if _, ok := links[rel]; !ok {
+				links[rel] = target
+			}
+		}
+	}
+}
+
+// linkParam parses the link parameter at the start of s, of the form name,
+// name=token or name="quoted string", and returns its name, its unquoted value
+// and the rest of s.
+func linkParam(s string) (name, value, rest string) {
+	i := strings.IndexAny(s, "=;,")
+	if i < 0 {
+		return strings.TrimSpace(s), "", ""
+	}
+	name = strings.TrimSpace(s[:i])
+	if s[i] != '=' {
+		return name, "", s[i:]
+	}
+	s = strings.TrimLeft(s[i+1:], " \t")
+
+	if !strings.HasPrefix(s, `"`) {
+		i = strings.IndexAny(s, ";,")
+		if i < 0 {
+			return name, strings.TrimSpace(s), ""
+		}
+		return name, strings.TrimSpace(s[:i]), s[i:]
+	}
+
+	var buf strings.Builder
+	for i = 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			buf.WriteByte(s[i])
+		case c == '"':
+			return name, buf.String(), s[i+1:]
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return name, buf.String(), ""
+}
+
+// DecodeLink decodes the query parameters of the link with relation type rel
+// in the Link header into v, using Decode, and reports whether the header has
+// such a link.  It is typically used to obtain the options of the next page
+// of results from a response:
+//
+//	var next ListOptions
+//	ok, err := query.DecodeLink(resp.Header.Get("Link"), "next", &next)
+func DecodeLink(header, rel string, v interface{}, opts ...Option) (bool, error) {
+	links, err := ParseLinks(header)
+	if err != nil {
+		return false, err
+	}
+	target, ok := links[strings.ToLower(rel)]
+	if !ok {
+		return false, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false, err
+	}
+	return true, Decode(u.Query(), v, opts...)
+}
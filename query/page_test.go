@@ -0,0 +1,122 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	type ListOptions struct {
+		Page
+		State string `url:"state,omitempty"`
+	}
+	type SearchOptions struct {
+		Cursor
+		Q string `url:"q"`
+	}
+
+	tests := []struct {
+		in   interface{}
+		want url.Values
+	}{
+		{ListOptions{}, url.Values{}},
+		{
+			ListOptions{Page: Page{Number: 2, PerPage: PerPage{50}}, State: "open"},
+			url.Values{"page": {"2"}, "per_page": {"50"}, "state": {"open"}},
+		},
+		{
+			SearchOptions{Cursor: Cursor{Value: "abc"}, Q: "go"},
+			url.Values{"cursor": {"abc"}, "q": {"go"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(tt.in)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, tt.in, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		header string
+		want   map[string]string
+	}{
+		{"", map[string]string{}},
+		{
+			`<https://api.example.com/items?page=2&per_page=50>; rel="next", ` +
+				`<https://api.example.com/items?page=5&per_page=50>; rel="last"`,
+			map[string]string{
+				"next": "https://api.example.com/items?page=2&per_page=50",
+				"last": "https://api.example.com/items?page=5&per_page=50",
+			},
+		},
+		{
+			// multiple relation types, other parameters, unquoted values
+			// and commas in targets
+			`</items?ids=1,2>; title="a; b, c"; rel="next Prefetch",</first>;rel=first`,
+			map[string]string{
+				"next":     "/items?ids=1,2",
+				"prefetch": "/items?ids=1,2",
+				"first":    "/first",
+			},
+		},
+		{
+			`</a>; rel="next", </b>; rel="next"`,
+			map[string]string{"next": "/a"},
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseLinks(tt.header)
+		if err != nil {
+			t.Errorf("%d. ParseLinks(%q) returned error: %v", i, tt.header, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%d. ParseLinks(%q) returned %v, want %v", i, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestParseLinks_invalid(t *testing.T) {
+	for _, header := range []string{"https://example.com", "<https://example.com", "</a> rel=next"} {
+		if _, err := ParseLinks(header); err == nil {
+			t.Errorf("ParseLinks(%q) returned no error", header)
+		}
+	}
+}
+
+func TestDecodeLink(t *testing.T) {
+	header := `<https://api.example.com/items?page=3&per_page=20&cursor=xyz>; rel="next"`
+
+	var next Page
+	ok, err := DecodeLink(header, "Next", &next)
+	if err != nil || !ok {
+		t.Fatalf("DecodeLink(%q) returned %v, %v", header, ok, err)
+	}
+	if want := (Page{Number: 3, PerPage: PerPage{20}}); next != want {
+		t.Errorf("DecodeLink(%q) decoded %v, want %v", header, next, want)
+	}
+
+	var cursor Cursor
+	if _, err := DecodeLink(header, "next", &cursor); err != nil {
+		t.Fatalf("DecodeLink(%q) returned error: %v", header, err)
+	}
+	if want := (Cursor{Value: "xyz", PerPage: PerPage{20}}); cursor != want {
+		t.Errorf("DecodeLink(%q) decoded %v, want %v", header, cursor, want)
+	}
+
+	if ok, err := DecodeLink(header, "prev", &next); ok || err != nil {
+		t.Errorf("DecodeLink(%q, prev) returned %v, %v, want false, nil", header, ok, err)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// A Pair is a single URL parameter.
+type Pair struct {
+	Key, Value string
+}
+
+// Pairs encodes v like Values, but returns the parameters as an ordered list
+// of pairs.  Parameters are ordered as their fields are encoded, that is in
+// declaration order with embedded structs following the rules of Values, and
+// the values of a parameter are kept together in the order they were added.
+// Parameters added by custom Encoders follow in sorted order.
+func Pairs(v interface{}, opts ...Option) ([]Pair, error) {
+	e := &encoder{config: newConfig(opts)}
+	values, err := e.encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return e.pairs(values), nil
+}
+
+// EncodeString encodes v and returns the resulting query string, without a
+// leading "?".  Unlike the Encode method of the url.Values returned by Values,
+// which sorts parameters by name, EncodeString writes them in the order of
+// Pairs.  Keys and values are escaped with url.QueryEscape unless an option,
+// such as WithQS, selects another escaping.
+func EncodeString(v interface{}, opts ...Option) (string, error) {
+	e := &encoder{config: newConfig(opts)}
+	values, err := e.encode(v)
+	if err != nil {
+		return "", err
+	}
+	return e.writePairs(e.pairs(values)), nil
+}
+
+// DecodeString parses rawQuery, a query string without its leading "?", and
+// decodes its parameters into v using Decode.  It is the counterpart of
+// EncodeString: parameters are separated by "&", or by either "&" or ";" with
+// WithSemicolons, whereas url.ParseQuery rejects semicolons.
+func DecodeString(rawQuery string, v interface{}, opts ...Option) error {
+	values, err := newConfig(opts).parseQuery(rawQuery)
+	if err != nil {
+		return err
+	}
+	return Decode(values, v, opts...)
+}
+
+// parseQuery parses rawQuery like url.ParseQuery, splitting it at the
+// separators configured.
+func (c *config) parseQuery(rawQuery string) (url.Values, error) {
+	seps := "&"
+	if c.semicolons {
+		seps = "&;"
+	}
+
+	values := make(url.Values)
+	for _, part := range strings.FieldsFunc(rawQuery, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+		k, v, _ := strings.Cut(part, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = append(values[key], value)
+	}
+	return values, nil
+}
+
+// pairs returns values as pairs, in the order of orderedKeys.
+func (e *encoder) pairs(values url.Values) []Pair {
+	var pairs []Pair
+	for _, k := range e.orderedKeys(values) {
+		for _, v := range values[k] {
+			pairs = append(pairs, Pair{k, v})
+		}
+	}
+	return pairs
+}
+
+// orderedKeys returns the parameter names of values in the order of Pairs.
+func (e *encoder) orderedKeys(values url.Values) []string {
+	e.recordAdded(values)
+	keys := e.keys
+	if e.keyLess != nil {
+		keys = append([]string(nil), keys...)
+		sort.SliceStable(keys, func(i, j int) bool {
+			return e.keyLess(keys[i], keys[j])
+		})
+	}
+	return keys
+}
+
+// WithKeyOrder orders the parameters of Pairs and EncodeString by less,
+// which reports whether the parameter named a goes before the one named b,
+// rather than in the order of their fields.  This serves services whose
+// request signatures prescribe an order of parameters.  The sort is stable,
+// so parameters that less does not order keep the order of their fields, and
+// the values of a parameter are always kept together.
+func WithKeyOrder(less func(a, b string) bool) Option {
+	return func(c *config) {
+		c.keyLess = less
+	}
+}
+
+// WithKeyPriority orders the parameters of Pairs and EncodeString so that
+// those named by keys come first, in the order of keys, followed by the
+// others in the order of their fields.  It replaces any order set with
+// WithKeyOrder.
+func WithKeyPriority(keys ...string) Option {
+	rank := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	return WithKeyOrder(func(a, b string) bool {
+		ra, okA := rank[a]
+		rb, okB := rank[b]
+		if okA && okB {
+			return ra < rb
+		}
+		return okA && !okB
+	})
+}
+
+// writePairs returns pairs as a query string, escaped as configured.
+func (c *config) writePairs(pairs []Pair) string {
+	escape := c.escape
+	if escape == nil {
+		escape = url.QueryEscape
+	}
+
+	sep := byte('&')
+	if c.semicolons {
+		sep = ';'
+	}
+
+	var buf strings.Builder
+	for i, p := range pairs {
+		if i > 0 {
+			buf.WriteByte(sep)
+		}
+		if c.rawKeys {
+			buf.WriteString(p.Key)
+		} else {
+			buf.WriteString(escape(p.Key))
+		}
+		if p.Value == "" && c.bareEmpty {
+			continue
+		}
+		buf.WriteByte('=')
+		buf.WriteString(escape(p.Value))
+	}
+	return buf.String()
+}
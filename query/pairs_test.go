@@ -0,0 +1,230 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// orderedEncoder adds parameters out of order, to check that those added by
+// Encoders are sorted.
+type orderedEncoder struct{}
+
+func (orderedEncoder) EncodeValues(key string, v *url.Values) error {
+	v.Add(key+"_z", "1")
+	v.Add(key+"_a", "2")
+	return nil
+}
+
+func TestPairs(t *testing.T) {
+	type Embedded struct {
+		E string `url:"e"`
+	}
+	in := struct {
+		Embedded
+		Z   string   `url:"z"`
+		A   []string `url:"a"`
+		Enc orderedEncoder
+		M   string `url:"m"`
+		Z2  string `url:"z"`
+	}{Embedded{"e"}, "1", []string{"x", "y"}, orderedEncoder{}, "m", "2"}
+
+	got, err := Pairs(in)
+	if err != nil {
+		t.Fatalf("Pairs(%v) returned error: %v", in, err)
+	}
+	want := []Pair{
+		{"z", "1"}, {"z", "2"},
+		{"a", "x"}, {"a", "y"},
+		{"Enc_a", "2"}, {"Enc_z", "1"},
+		{"m", "m"},
+		{"e", "e"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Pairs(%v) returned %v, want %v", in, got, want)
+	}
+
+	s, err := EncodeString(in)
+	if err != nil {
+		t.Fatalf("EncodeString(%v) returned error: %v", in, err)
+	}
+	if want := "z=1&z=2&a=x&a=y&Enc_a=2&Enc_z=1&m=m&e=e"; s != want {
+		t.Errorf("EncodeString(%v) returned %q, want %q", in, s, want)
+	}
+}
+
+func TestEncodeString(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, ""},
+		{struct{ Q string }{"a b&c"}, "Q=a+b%26c"},
+		{
+			struct {
+				B []string `url:"b,brackets"`
+				A string   `url:"a"`
+			}{[]string{"1"}, "2"},
+			"b%5B%5D=1&a=2",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := EncodeString(tt.in)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := EncodeString(""); err == nil {
+		t.Errorf("expected EncodeString() to return an error on invalid input")
+	}
+	if _, err := Pairs(""); err == nil {
+		t.Errorf("expected Pairs() to return an error on invalid input")
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	type s struct {
+		A string `url:"a"`
+		B []int  `url:"b"`
+	}
+
+	tests := []struct {
+		in   string
+		opts []Option
+		want s
+	}{
+		{"", nil, s{}},
+		{"a=x+y&b=1&b=2", nil, s{A: "x y", B: []int{1, 2}}},
+		{"a=x;y", nil, s{A: "x;y"}},
+		{"a=x;b=1&b=2", []Option{WithSemicolons()}, s{A: "x", B: []int{1, 2}}},
+		{"a=%3B;;b=1", []Option{WithSemicolons()}, s{A: ";", B: []int{1}}},
+	}
+	for i, tt := range tests {
+		var got s
+		if err := DecodeString(tt.in, &got, tt.opts...); err != nil {
+			t.Errorf("%d. DecodeString(%q) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%d. DecodeString(%q) decoded %+v, want %+v", i, tt.in, got, tt.want)
+		}
+	}
+
+	var got s
+	if err := DecodeString("a=%zz", &got); err == nil {
+		t.Errorf("DecodeString with an invalid escape returned no error")
+	}
+}
+
+func TestEncodeString_semicolons(t *testing.T) {
+	in := struct {
+		A string `url:"a"`
+		B []int  `url:"b"`
+	}{"x;y", []int{1, 2}}
+
+	got, err := EncodeString(in, WithSemicolons())
+	if err != nil {
+		t.Fatalf("EncodeString(%v) returned error: %v", in, err)
+	}
+	if want := "a=x%3By;b=1;b=2"; got != want {
+		t.Errorf("EncodeString(%v) returned %q, want %q", in, got, want)
+	}
+}
+
+func TestEncodeString_keyOrder(t *testing.T) {
+	in := struct {
+		Nonce     string   `url:"nonce"`
+		Action    string   `url:"action"`
+		Tags      []string `url:"tag"`
+		Timestamp int      `url:"timestamp"`
+	}{"n", "list", []string{"b", "a"}, 1}
+
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "nonce=n&action=list&tag=b&tag=a&timestamp=1"},
+		{
+			[]Option{WithKeyOrder(func(a, b string) bool { return a < b })},
+			"action=list&nonce=n&tag=b&tag=a&timestamp=1",
+		},
+		{
+			[]Option{WithKeyPriority("timestamp", "action", "missing")},
+			"timestamp=1&action=list&nonce=n&tag=b&tag=a",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := EncodeString(in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v) returned %q, want %q", i, in, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeString_bareEmptyValues(t *testing.T) {
+	type Options struct {
+		A string   `url:"a"`
+		B []string `url:"b"`
+	}
+	in := Options{"", []string{"", "1"}}
+
+	got, err := EncodeString(in, WithBareEmptyValues())
+	if err != nil {
+		t.Fatalf("EncodeString(%v) returned error: %v", in, err)
+	}
+	if want := "a&b&b=1"; got != want {
+		t.Errorf("EncodeString(%v) returned %q, want %q", in, got, want)
+	}
+
+	var out Options
+	if err := DecodeString(got, &out); err != nil {
+		t.Fatalf("DecodeString(%q) returned error: %v", got, err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("DecodeString(%q) decoded %v, want %v", got, out, in)
+	}
+}
+
+func TestEncodeString_dedupe(t *testing.T) {
+	type Base struct {
+		Tags []string `url:"tag"`
+	}
+	in := struct {
+		Base
+		Tags []string `url:"tag"`
+		Q    string   `url:"q"`
+	}{Base{[]string{"a", "c"}}, []string{"a", "b", "a"}, "go"}
+
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "tag=a&tag=b&tag=a&tag=a&tag=c&q=go"},
+		{[]Option{WithDedupe()}, "tag=a&tag=b&tag=c&q=go"},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%v) returned %q, want %q", i, in, got, tt.want)
+		}
+	}
+}
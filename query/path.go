@@ -0,0 +1,117 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// ExpandPath replaces the "{name}" placeholders in template with the values of
+// the fields of v that have a matching "path" tag.  The "path" tag holds the
+// placeholder name followed by optional options, which are applied as for url
+// tags, e.g. the "int" and "unix" options.  For example:
+//
+//	type Options struct {
+//		Owner string `path:"owner"`
+//		Repo  string `path:"repo"`
+//		Page  int    `url:"page,omitempty"`
+//	}
+//
+//	p, _ := query.ExpandPath("/repos/{owner}/{repo}/issues", opt)
+//
+// Values are escaped with url.PathEscape.  Only the fields of v itself and of
+// its embedded structs are considered.  It is an error for a placeholder to
+// have no matching field or an empty value.
+//
+// Fields with a path tag but no url tag are skipped by Values and Decode, so
+// one struct can describe both the path and the query of a request; see
+// BuildTarget.
+func ExpandPath(template string, v interface{}, opts ...Option) (string, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return "", fmt.Errorf("query: ExpandPath() expects struct input. Got %v", val.Kind())
+	}
+
+	e := &encoder{config: newConfig(opts)}
+	params := make(map[string]string)
+	if err := e.pathParams(params, val); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(template, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(template[i:], '}')
+		if j < 0 {
+			break
+		}
+		name := template[i+1 : i+j]
+		s, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("query: no path field for placeholder {%s}", name)
+		}
+		if s == "" {
+			return "", fmt.Errorf("query: empty value for placeholder {%s}", name)
+		}
+		b.WriteString(template[:i])
+		b.WriteString(url.PathEscape(s))
+		template = template[i+j+1:]
+	}
+	b.WriteString(template)
+	return b.String(), nil
+}
+
+// BuildTarget expands the path placeholders of template using ExpandPath,
+// and then merges the query parameters encoded from v into it using
+// BuildURL.  The template may be a path or a full URL.
+func BuildTarget(template string, v interface{}, opts ...Option) (string, error) {
+	p, err := ExpandPath(template, v, opts...)
+	if err != nil {
+		return "", err
+	}
+	return BuildURL(p, v, opts...)
+}
+
+// pathParams adds the values of the path fields of the struct val to params.
+func (e *encoder) pathParams(params map[string]string, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := structField(typ, i)
+		sv := val.Field(i)
+
+		if sf.Anonymous && sf.Tag.Get("path") == "" {
+			if ev, ok := embeddedStruct(sv); ok && ev.IsValid() {
+				if err := e.pathParams(params, ev); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		tag := sf.Tag.Get("path")
+		if sf.PkgPath != "" || tag == "" || tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+		s, err := e.valueString(sv, opts)
+		if err == errSkipValue {
+			continue
+		}
+		if err != nil {
+			return &FieldError{Name: name, Err: err}
+		}
+		params[name] = s
+	}
+	return nil
+}
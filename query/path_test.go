@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+type repoPath struct {
+	Owner string `path:"owner"`
+	Repo  string `path:"repo"`
+}
+
+func TestExpandPath(t *testing.T) {
+	opt := struct {
+		repoPath
+		Number int    `path:"number"`
+		State  string `url:"state,omitempty"`
+		Page   int    `url:"page,omitempty" path:"page"`
+	}{repoPath{"google", "go query"}, 12, "open", 2}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"/repos/{owner}/{repo}/issues/{number}", "/repos/google/go%20query/issues/12"},
+		{"/static", "/static"},
+		{"/pages/{page}", "/pages/2"},
+		{"/broken/{owner", "/broken/{owner"},
+	}
+
+	for i, tt := range tests {
+		got, err := ExpandPath(tt.template, opt)
+		if err != nil {
+			t.Errorf("%d. ExpandPath(%q) returned error: %v", i, tt.template, err)
+		}
+		if got != tt.want {
+			t.Errorf("%d. ExpandPath(%q) returned %q, want %q", i, tt.template, got, tt.want)
+		}
+	}
+
+	got, err := BuildTarget("https://api.github.com/repos/{owner}/{repo}/issues?per_page=10", &opt)
+	if err != nil {
+		t.Fatalf("BuildTarget() returned error: %v", err)
+	}
+	if want := "https://api.github.com/repos/google/go%20query/issues?page=2&per_page=10&state=open"; got != want {
+		t.Errorf("BuildTarget() returned %q, want %q", got, want)
+	}
+
+	for _, template := range []string{"/{missing}", "/{owner}"} {
+		if _, err := ExpandPath(template, repoPath{}); err == nil {
+			t.Errorf("ExpandPath(%q) returned no error", template)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package prometheus provides option structs for the query API of
+// Prometheus, to be encoded by the query package:
+//
+//	end := time.Now()
+//	opt := prometheus.RangeQuery{
+//		Query: `rate(http_requests_total[5m])`,
+//		Start: prometheus.Unix(end.Add(-time.Hour)),
+//		End:   prometheus.Unix(end),
+//		Step:  prometheus.Duration(30 * time.Second),
+//	}
+//	v, _ := query.Values(opt)
+//	// query: rate(...), start: 1700000000, end: 1700003600, step: 30s
+package prometheus
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InstantQuery holds the parameters of an instant query, as in
+// GET /api/v1/query?query=up.  Zero fields are left out.
+type InstantQuery struct {
+	Query   string   `url:"query"`
+	Time    Time     `url:"time,omitempty"`
+	Timeout Duration `url:"timeout,omitempty"`
+	Limit   int      `url:"limit,omitempty"`
+}
+
+// RangeQuery holds the parameters of a range query, as in
+// GET /api/v1/query_range?query=up&start=...&end=...&step=30s.
+type RangeQuery struct {
+	Query   string   `url:"query"`
+	Start   Time     `url:"start"`
+	End     Time     `url:"end"`
+	Step    Duration `url:"step"`
+	Timeout Duration `url:"timeout,omitempty"`
+	Limit   int      `url:"limit,omitempty"`
+}
+
+// SeriesQuery holds the parameters of requests for series, label names and
+// label values, as in GET /api/v1/series?match[]=up.
+type SeriesQuery struct {
+	Match []string `url:"match,brackets,omitempty"`
+	Start Time     `url:"start,omitempty"`
+	End   Time     `url:"end,omitempty"`
+	Limit int      `url:"limit,omitempty"`
+}
+
+// A Time is a time.Time written either in RFC 3339 format or as a Unix
+// timestamp in seconds, with a fractional part if needed, both of which
+// Prometheus accepts.
+type Time struct {
+	time.Time
+	Unix bool // write as a Unix timestamp
+}
+
+// RFC3339 returns t written in RFC 3339 format, in UTC.
+func RFC3339(t time.Time) Time {
+	return Time{Time: t}
+}
+
+// Unix returns t written as a Unix timestamp.
+func Unix(t time.Time) Time {
+	return Time{Time: t, Unix: true}
+}
+
+// String returns t as written in query parameters.
+func (t Time) String() string {
+	if !t.Unix {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	s := strconv.FormatInt(t.Time.Unix(), 10)
+	if ns := t.Nanosecond(); ns != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", ns), "0")
+	}
+	return s
+}
+
+// EncodeValues implements query.Encoder.
+func (t Time) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, t.String())
+	return nil
+}
+
+// UnmarshalText parses a time in either form.
+func (t *Time) UnmarshalText(text []byte) error {
+	s := string(text)
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, frac, _ := strings.Cut(s, ".")
+		n, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return fmt.Errorf("prometheus: invalid time %q", s)
+		}
+		var ns int64
+		if frac != "" {
+			frac = (frac + "000000000")[:9]
+			ns, _ = strconv.ParseInt(frac, 10, 64)
+			if sec < 0 {
+				ns = -ns
+			}
+		}
+		*t = Unix(time.Unix(n, ns))
+		return nil
+	}
+	tm, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("prometheus: invalid time %q", s)
+	}
+	*t = RFC3339(tm)
+	return nil
+}
+
+// A Duration is a time.Duration written in the duration format of
+// Prometheus, such as "30s" or "1h30m", using the units "y", "w", "d", "h",
+// "m", "s" and "ms".
+type Duration time.Duration
+
+// durationUnits lists the time units of Prometheus, from largest to
+// smallest.
+var durationUnits = []struct {
+	name string
+	d    time.Duration
+}{
+	{"y", 365 * 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+}
+
+// String returns d in the duration format.  Durations that are not a whole
+// number of milliseconds are written in seconds as a float, which
+// Prometheus accepts as well.
+func (d Duration) String() string {
+	td := time.Duration(d)
+	switch {
+	case td == 0:
+		return "0s"
+	case td < 0 || td%time.Millisecond != 0:
+		return strconv.FormatFloat(td.Seconds(), 'f', -1, 64)
+	}
+	var b strings.Builder
+	for _, u := range durationUnits {
+		if n := td / u.d; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteString(u.name)
+			td -= n * u.d
+		}
+	}
+	return b.String()
+}
+
+// UnmarshalText parses a duration in the duration format or in seconds.
+func (d *Duration) UnmarshalText(text []byte) error {
+	s := string(text)
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		*d = Duration(time.Duration(sec * float64(time.Second)))
+		return nil
+	}
+
+	if s == "" {
+		return fmt.Errorf("prometheus: invalid duration %q", s)
+	}
+	var td time.Duration
+	rest := s
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return fmt.Errorf("prometheus: invalid duration %q", s)
+		}
+		n, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil {
+			return fmt.Errorf("prometheus: invalid duration %q", s)
+		}
+		rest = rest[i:]
+		j := strings.IndexFunc(rest, func(r rune) bool { return r >= '0' && r <= '9' })
+		if j < 0 {
+			j = len(rest)
+		}
+		unit := -1
+		for k, u := range durationUnits {
+			if rest[:j] == u.name {
+				unit = k
+			}
+		}
+		if unit < 0 {
+			return fmt.Errorf("prometheus: invalid duration unit in %q", s)
+		}
+		td += time.Duration(n) * durationUnits[unit].d
+		rest = rest[j:]
+	}
+	*d = Duration(td)
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prometheus
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestTime(t *testing.T) {
+	tm := time.Date(2023, 11, 14, 22, 13, 20, 500000000, time.UTC)
+	tests := []struct {
+		t    Time
+		want string
+	}{
+		{RFC3339(tm), "2023-11-14T22:13:20.5Z"},
+		{RFC3339(tm.In(time.FixedZone("X", 3600))), "2023-11-14T22:13:20.5Z"},
+		{Unix(tm), "1700000000.5"},
+		{Unix(tm.Truncate(time.Second)), "1700000000"},
+	}
+	for i, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("%d. String() returned %q, want %q", i, got, tt.want)
+		}
+		var got Time
+		if err := got.UnmarshalText([]byte(tt.want)); err != nil {
+			t.Errorf("%d. UnmarshalText(%q) returned error: %v", i, tt.want, err)
+		} else if !got.Equal(tt.t.Time) || got.Unix != tt.t.Unix {
+			t.Errorf("%d. UnmarshalText(%q) returned %v, want %v", i, tt.want, got, tt.t)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{30 * time.Second, "30s"},
+		{90 * time.Minute, "1h30m"},
+		{8 * 24 * time.Hour, "1w1d"},
+		{1500 * time.Millisecond, "1s500ms"},
+		{1500 * time.Microsecond, "0.0015"},
+	}
+	for i, tt := range tests {
+		if got := Duration(tt.d).String(); got != tt.want {
+			t.Errorf("%d. String(%v) returned %q, want %q", i, tt.d, got, tt.want)
+		}
+		var got Duration
+		if err := got.UnmarshalText([]byte(tt.want)); err != nil {
+			t.Errorf("%d. UnmarshalText(%q) returned error: %v", i, tt.want, err)
+		} else if time.Duration(got) != tt.d {
+			t.Errorf("%d. UnmarshalText(%q) returned %v, want %v", i, tt.want, time.Duration(got), tt.d)
+		}
+	}
+
+	for _, s := range []string{"", "s", "10x", "1h30"} {
+		var d Duration
+		if err := d.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("UnmarshalText(%q) returned no error", s)
+		}
+	}
+}
+
+func TestRangeQuery(t *testing.T) {
+	end := time.Unix(1700003600, 0)
+	opt := RangeQuery{
+		Query: "up",
+		Start: Unix(end.Add(-time.Hour)),
+		End:   Unix(end),
+		Step:  Duration(30 * time.Second),
+	}
+	v, err := query.Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	want := url.Values{
+		"query": {"up"},
+		"start": {"1700000000"},
+		"end":   {"1700003600"},
+		"step":  {"30s"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+
+	var got RangeQuery
+	if err := query.Decode(v, &got); err != nil {
+		t.Fatalf("Decode(%v) returned error: %v", v, err)
+	}
+	if !got.Start.Equal(opt.Start.Time) || !got.End.Equal(opt.End.Time) || got.Step != opt.Step || got.Query != opt.Query {
+		t.Errorf("Decode(%v) decoded %v, want %v", v, got, opt)
+	}
+}
+
+func TestInstantQuery(t *testing.T) {
+	opt := InstantQuery{Query: "up"}
+	v, err := query.Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	if want := (url.Values{"query": {"up"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+}
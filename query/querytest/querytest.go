@@ -0,0 +1,81 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package querytest provides helpers for testing the encoding of option
+// structs by the query package, such as those of API client libraries:
+//
+//	func TestListOptions(t *testing.T) {
+//		opt := ListOptions{State: "open", Page: 2}
+//		querytest.AssertEncodes(t, opt, "page=2&state=open")
+//		querytest.AssertRoundTrip(t, opt)
+//	}
+package querytest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-querystring/query"
+)
+
+// AssertEncodes reports an error through t unless v encodes, with opts, to
+// the same parameters as the query string want.  The order of parameters and
+// the way they are escaped do not matter; see query.Equal.
+func AssertEncodes(t testing.TB, v interface{}, want string, opts ...query.Option) {
+	t.Helper()
+	values, err := query.Values(v, opts...)
+	if err != nil {
+		t.Errorf("Values(%+v) returned error: %v", v, err)
+		return
+	}
+	if diff := query.DiffQuery(values.Encode(), want); diff != "" {
+		t.Errorf("Values(%+v) returned %q, want %q (-got +want):\n%s", v, values.Encode(), want, diff)
+	}
+}
+
+// AssertEncodesStrict reports an error through t unless v encodes, with
+// opts, to exactly the query string want, as written by query.EncodeString.
+// It is meant for servers sensitive to the order of parameters or to their
+// escaping.
+func AssertEncodesStrict(t testing.TB, v interface{}, want string, opts ...query.Option) {
+	t.Helper()
+	got, err := query.EncodeString(v, opts...)
+	if err != nil {
+		t.Errorf("EncodeString(%+v) returned error: %v", v, err)
+		return
+	}
+	if got != want {
+		t.Errorf("EncodeString(%+v) returned %q, want %q", v, got, want)
+	}
+}
+
+// AssertRoundTrip reports an error through t unless v, a struct or a pointer
+// to one, is unchanged after being encoded by query.Values and decoded by
+// query.Decode into a new value of its type, with opts.
+func AssertRoundTrip(t testing.TB, v interface{}, opts ...query.Option) {
+	t.Helper()
+	values, err := query.Values(v, opts...)
+	if err != nil {
+		t.Errorf("Values(%+v) returned error: %v", v, err)
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	ptr := rv.Kind() == reflect.Ptr
+	if ptr {
+		rv = rv.Elem()
+	}
+	out := reflect.New(rv.Type())
+	if err := query.Decode(values, out.Interface(), opts...); err != nil {
+		t.Errorf("Decode(%v) returned error: %v", values, err)
+		return
+	}
+	got := out.Interface()
+	if !ptr {
+		got = out.Elem().Interface()
+	}
+	if !reflect.DeepEqual(v, got) {
+		t.Errorf("Decode(%v) decoded %+v, want %+v", values, got, v)
+	}
+}
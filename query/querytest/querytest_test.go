@@ -0,0 +1,59 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package querytest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recorder records the errors reported through it.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+type options struct {
+	State string   `url:"state,omitempty"`
+	Page  int      `url:"page"`
+	Tags  []string `url:"tags,omitempty"`
+	Func  func()   `url:"-"`
+}
+
+type lossy struct {
+	A string `url:"a"`
+	B string `url:"a"`
+}
+
+func TestHelpers(t *testing.T) {
+	opt := options{State: "a b", Page: 2}
+	tests := []struct {
+		check func(testing.TB)
+		fail  bool
+	}{
+		{func(t testing.TB) { AssertEncodes(t, opt, "page=2&state=a%20b") }, false},
+		{func(t testing.TB) { AssertEncodes(t, opt, "state=a+b&page=2") }, false},
+		{func(t testing.TB) { AssertEncodes(t, opt, "page=2") }, true},
+		{func(t testing.TB) { AssertEncodes(t, "", "") }, true},
+		{func(t testing.TB) { AssertEncodesStrict(t, opt, "state=a+b&page=2") }, false},
+		{func(t testing.TB) { AssertEncodesStrict(t, opt, "page=2&state=a+b") }, true},
+		{func(t testing.TB) { AssertRoundTrip(t, opt) }, false},
+		{func(t testing.TB) { AssertRoundTrip(t, &opt) }, false},
+		{func(t testing.TB) { AssertRoundTrip(t, lossy{"x", "y"}) }, true},
+	}
+	for i, tt := range tests {
+		r := new(recorder)
+		tt.check(r)
+		if failed := len(r.errs) > 0; failed != tt.fail {
+			t.Errorf("%d. check reported errors %q, want failure %v", i, r.errs, tt.fail)
+		}
+	}
+}
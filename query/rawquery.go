@@ -0,0 +1,167 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"strings"
+)
+
+// A Query is a parsed query string that remembers the order and the raw text
+// of its parameters, so that proxies and middleware can inspect or adjust a
+// query and pass it on without perturbing it.  Unlike url.Values, whose
+// Encode method sorts parameters and escapes them anew, the String method of
+// a Query writes the parameters in their original order, each unchanged
+// parameter with its original text, as in "b=%7e&a=x+y" rather than
+// "a=x+y&b=~".  Only the parameters added or modified are escaped, following
+// the options given to ParseQuery.
+type Query struct {
+	config *config
+	params []queryParam
+}
+
+// queryParam is a parameter of a Query.  raw holds its original text, or is
+// empty if the parameter was added or modified.  sep is the separator
+// preceding it, ignored for the first parameter.  blank is set for the empty
+// parameters of the original query, as between the separators of "a=1&&b=2".
+type queryParam struct {
+	Pair
+	raw   string
+	sep   byte
+	blank bool
+}
+
+// ParseQuery parses rawQuery, a query string without its leading "?".
+// Parameters are separated by "&", or by either "&" or ";" with
+// WithSemicolons.  Empty parameters, as in "a=1&&b=2", are kept as they are.
+func ParseQuery(rawQuery string, opts ...Option) (*Query, error) {
+	q := &Query{config: newConfig(opts)}
+	if rawQuery == "" {
+		return q, nil
+	}
+
+	var sep byte
+	start := 0
+	for i := 0; i <= len(rawQuery); i++ {
+		if i < len(rawQuery) && !q.isSep(rawQuery[i]) {
+			continue
+		}
+		part := rawQuery[start:i]
+		p := queryParam{raw: part, sep: sep, blank: part == ""}
+		if part != "" {
+			k, v, _ := strings.Cut(part, "=")
+			key, err := url.QueryUnescape(k)
+			if err != nil {
+				return nil, err
+			}
+			value, err := url.QueryUnescape(v)
+			if err != nil {
+				return nil, err
+			}
+			p.Pair = Pair{key, value}
+		}
+		q.params = append(q.params, p)
+		if i < len(rawQuery) {
+			sep = rawQuery[i]
+		}
+		start = i + 1
+	}
+	return q, nil
+}
+
+// isSep reports whether b separates parameters.
+func (q *Query) isSep(b byte) bool {
+	return b == '&' || b == ';' && q.config.semicolons
+}
+
+// Pairs returns the parameters of q in order.
+func (q *Query) Pairs() []Pair {
+	var pairs []Pair
+	for _, p := range q.params {
+		if !p.blank {
+			pairs = append(pairs, p.Pair)
+		}
+	}
+	return pairs
+}
+
+// Values returns the parameters of q as url.Values, for Decode.
+func (q *Query) Values() url.Values {
+	values := make(url.Values)
+	for _, p := range q.Pairs() {
+		values[p.Key] = append(values[p.Key], p.Value)
+	}
+	return values
+}
+
+// Get returns the first value of the parameter key, or "" if there is none.
+func (q *Query) Get(key string) string {
+	for _, p := range q.params {
+		if !p.blank && p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Set replaces the values of the parameter key by value, keeping the
+// position of its first occurrence, or adds it at the end.  The text of the
+// parameter is left unchanged if its value is.
+func (q *Query) Set(key, value string) {
+	found := false
+	params := q.params[:0]
+	for _, p := range q.params {
+		if !p.blank && p.Key == key {
+			if found {
+				continue
+			}
+			found = true
+			if p.Value != value {
+				p.Value, p.raw = value, ""
+			}
+		}
+		params = append(params, p)
+	}
+	q.params = params
+	if !found {
+		q.Add(key, value)
+	}
+}
+
+// Add adds the value to the parameter key, at the end of q.
+func (q *Query) Add(key, value string) {
+	sep := byte('&')
+	if q.config.semicolons {
+		sep = ';'
+	}
+	q.params = append(q.params, queryParam{Pair: Pair{key, value}, sep: sep})
+}
+
+// Del removes the parameter key.
+func (q *Query) Del(key string) {
+	params := q.params[:0]
+	for _, p := range q.params {
+		if p.blank || p.Key != key {
+			params = append(params, p)
+		}
+	}
+	q.params = params
+}
+
+// String returns the query string of q, without a leading "?".
+func (q *Query) String() string {
+	var buf strings.Builder
+	for i, p := range q.params {
+		if i > 0 {
+			buf.WriteByte(p.sep)
+		}
+		if p.raw != "" || p.blank {
+			buf.WriteString(p.raw)
+		} else {
+			buf.WriteString(q.config.writePairs([]Pair{p.Pair}))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,80 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery_roundTrip(t *testing.T) {
+	tests := []string{
+		"",
+		"b=%7e&a=x+y&a=x%20y",
+		"flag&empty=&=v",
+		"a=1&&b=2&",
+		"q=%E2%9C%93&Q=%e2%9c%93",
+	}
+	for i, raw := range tests {
+		q, err := ParseQuery(raw)
+		if err != nil {
+			t.Errorf("%d. ParseQuery(%q) returned error: %v", i, raw, err)
+			continue
+		}
+		if got := q.String(); got != raw {
+			t.Errorf("%d. ParseQuery(%q).String() returned %q", i, raw, got)
+		}
+	}
+
+	if _, err := ParseQuery("a=%zz"); err == nil {
+		t.Errorf("expected ParseQuery() to return an error on invalid escapes")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	q, err := ParseQuery("z=%7e&a=1&b=x+y&a=2&&c=3")
+	if err != nil {
+		t.Fatalf("ParseQuery returned error: %v", err)
+	}
+
+	wantPairs := []Pair{{"z", "~"}, {"a", "1"}, {"b", "x y"}, {"a", "2"}, {"c", "3"}}
+	if got := q.Pairs(); !reflect.DeepEqual(got, wantPairs) {
+		t.Errorf("Pairs() returned %v, want %v", got, wantPairs)
+	}
+	wantValues := url.Values{"z": {"~"}, "a": {"1", "2"}, "b": {"x y"}, "c": {"3"}}
+	if got := q.Values(); !reflect.DeepEqual(got, wantValues) {
+		t.Errorf("Values() returned %v, want %v", got, wantValues)
+	}
+	if got := q.Get("b"); got != "x y" {
+		t.Errorf("Get(%q) returned %q, want %q", "b", got, "x y")
+	}
+
+	steps := []struct {
+		op   func()
+		want string
+	}{
+		{func() { q.Set("z", "~") }, "z=%7e&a=1&b=x+y&a=2&&c=3"},
+		{func() { q.Set("a", "3&4") }, "z=%7e&a=3%264&b=x+y&&c=3"},
+		{func() { q.Add("d", "") }, "z=%7e&a=3%264&b=x+y&&c=3&d="},
+		{func() { q.Del("z") }, "a=3%264&b=x+y&&c=3&d="},
+		{func() { q.Set("e", "5") }, "a=3%264&b=x+y&&c=3&d=&e=5"},
+	}
+	for i, s := range steps {
+		s.op()
+		if got := q.String(); got != s.want {
+			t.Errorf("%d. String() returned %q, want %q", i, got, s.want)
+		}
+	}
+
+	q, err = ParseQuery("a=1;b=2&c=3", WithSemicolons(), WithBareEmptyValues())
+	if err != nil {
+		t.Fatalf("ParseQuery returned error: %v", err)
+	}
+	q.Add("d", "")
+	if got, want := q.String(), "a=1;b=2&c=3;d"; got != want {
+		t.Errorf("String() returned %q, want %q", got, want)
+	}
+}
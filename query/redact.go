@@ -0,0 +1,142 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of redacted fields in diagnostic output.
+//
+// A field is redacted if its tag has the "redact" option, as in
+// `url:"token,redact"`, if its parameter name or Go field name matches a
+// pattern given to WithRedaction, or if it is nested in a redacted field.
+// Redaction only applies to diagnostics: the messages sent to a Logger, the
+// steps recorded by WithTrace, the values described by Explain and the
+// messages of encoding and decoding errors.  The values returned by Values and
+// the other encoding functions are unchanged.
+const Redacted = "[REDACTED]"
+
+// WithRedaction redacts the fields whose parameter name or Go field name
+// matches one of patterns, in addition to those with the "redact" option.
+// Patterns use the syntax of path.Match and are matched case-insensitively
+// against the name of the field itself, without the names of enclosing
+// structs, as in "*token*" or "*secret*".
+func WithRedaction(patterns ...string) Option {
+	return func(c *config) {
+		c.redactPatterns = append(c.redactPatterns, patterns...)
+	}
+}
+
+// redacts reports whether the field sf, whose parameter name is name without
+// its scope, is redacted.  An empty name means the one derived from sf.
+func (c *config) redacts(sf reflect.StructField, name string, opts tagOptions) bool {
+	if opts.Contains("redact") {
+		return true
+	}
+	if name == "" {
+		name = c.fieldName(sf)
+	}
+	for _, p := range c.redactPatterns {
+		p = strings.ToLower(p)
+		if ok, _ := path.Match(p, strings.ToLower(name)); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, strings.ToLower(sf.Name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// typeRedacts reports whether the struct type t has redacted fields, which
+// makes it unfit for logging as a whole.
+func (c *config) typeRedacts(t reflect.Type) bool {
+	return c.typeRedactsVisiting(t, make(map[reflect.Type]bool))
+}
+
+func (c *config) typeRedactsVisiting(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	found := false
+	c.walkFields(t, func(f typeField) {
+		if found {
+			return
+		}
+		found = c.redacts(f.sf, f.name, f.opts) || c.typeRedactsVisiting(f.sf.Type, visiting)
+	})
+	return found
+}
+
+// redactError returns err with the occurrences of secrets in its message
+// replaced by Redacted, or with its whole message replaced if no secrets are
+// given.  The original error remains available through errors.Unwrap.
+func redactError(err error, secrets ...string) error {
+	msg := Redacted
+	if len(secrets) > 0 {
+		msg = err.Error()
+		for _, s := range secrets {
+			if s != "" {
+				msg = strings.ReplaceAll(msg, s, Redacted)
+			}
+		}
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactLog returns val, about to be logged by e, with the values of redacted
+// fields hidden.
+func (e *encoder) redactLog(val interface{}) interface{} {
+	if e.redacting {
+		return Redacted
+	}
+	switch v := val.(type) {
+	case url.Values:
+		if len(e.redactedKeys) == 0 {
+			return v
+		}
+		r := make(url.Values, len(v))
+		for k, vs := range v {
+			if e.redactedKeys[k] {
+				vs = redactStrings(vs)
+			}
+			r[k] = vs
+		}
+		return r
+	case reflect.Value:
+		if v.IsValid() && e.typeRedacts(v.Type()) {
+			return Redacted
+		}
+	default:
+		if t := reflect.TypeOf(val); t != nil && e.typeRedacts(t) {
+			return Redacted
+		}
+	}
+	return val
+}
+
+// redactStrings returns a slice holding Redacted in place of each of vs.
+func redactStrings(vs []string) []string {
+	r := make([]string, len(vs))
+	for i := range r {
+		r[i] = Redacted
+	}
+	return r
+}
@@ -0,0 +1,95 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type redactOptions struct {
+	User     string `url:"user"`
+	Password string `url:"password,redact"`
+	APIToken string `url:"api_token"`
+	Auth     struct {
+		Key string `url:"key"`
+	} `url:"auth,redact"`
+}
+
+func TestRedaction_diagnostics(t *testing.T) {
+	in := redactOptions{User: "alice", Password: "hunter2", APIToken: "t0ken"}
+	in.Auth.Key = "k3y"
+	secrets := []string{"hunter2", "t0ken", "k3y"}
+	opt := WithRedaction("*token*")
+
+	// values themselves are unchanged
+	v, err := Values(in, opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	if got := v.Get("password"); got != "hunter2" {
+		t.Errorf("Values(%v) encoded password as %q, want %q", in, got, "hunter2")
+	}
+
+	var logged []string
+	l := LoggerFunc(func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	})
+	var tr Trace
+	if _, err := Values(in, opt, WithLogger(l), WithTrace(&tr)); err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+	var steps []string
+	for _, s := range tr.Steps {
+		steps = append(steps, s.String())
+	}
+	fields, err := Explain(in, opt)
+	if err != nil {
+		t.Fatalf("Explain(%v) returned error: %v", in, err)
+	}
+
+	outputs := map[string]string{
+		"Logger":    strings.Join(logged, "\n"),
+		"WithTrace": strings.Join(steps, "\n"),
+		"Explain":   fmt.Sprint(fields),
+	}
+	for name, out := range outputs {
+		for _, s := range secrets {
+			if strings.Contains(out, s) {
+				t.Errorf("%s output contains %q:\n%s", name, s, out)
+			}
+		}
+		if !strings.Contains(out, "alice") {
+			t.Errorf("%s output lacks unredacted value %q:\n%s", name, "alice", out)
+		}
+	}
+}
+
+func TestRedaction_errors(t *testing.T) {
+	type Numbers struct {
+		PIN    int     `url:"pin,redact"`
+		Secret float64 `url:"secret"`
+		N      int     `url:"n"`
+	}
+
+	var out Numbers
+	err := Decode(url.Values{"pin": {"12x4"}}, &out)
+	if err == nil || strings.Contains(err.Error(), "12x4") || !strings.Contains(err.Error(), Redacted) {
+		t.Errorf("Decode returned error %v, want the value redacted", err)
+	}
+	err = Decode(url.Values{"n": {"12x4"}}, &out)
+	if err == nil || !strings.Contains(err.Error(), "12x4") {
+		t.Errorf("Decode returned error %v, want the value of an unredacted field", err)
+	}
+
+	in := Numbers{Secret: math.Inf(1)}
+	_, err = Values(in, WithRedaction("SECRET"), WithNonFiniteFloats(NonFiniteError))
+	if err == nil || strings.Contains(err.Error(), "Inf") {
+		t.Errorf("Values(%v) returned error %v, want the value redacted", in, err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rsql builds RSQL filter expressions, a superset of FIQL used by many
+// Spring based APIs, as in "name==Kill*;year=gt=2003".  Expressions are
+// fmt.Stringers, so they can be held by tagged fields of the option structs
+// encoded by the query package:
+//
+//	type ListOptions struct {
+//		Filter rsql.Node `url:"filter,omitempty"`
+//	}
+//
+//	opt := ListOptions{Filter: rsql.And(
+//		rsql.Eq("genres", "sci-fi"),
+//		rsql.Or(rsql.Lt("year", 2000), rsql.In("director", "Scott", "Lucas")),
+//	)}
+//	// filter=genres==sci-fi;(year<2000,director=in=(Scott,Lucas))
+package rsql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A Node is an RSQL expression.  Its String method returns it in RSQL syntax.
+type Node interface {
+	String() string
+	precedence() int
+}
+
+const (
+	precOr = iota + 1
+	precAnd
+	precComparison
+)
+
+type comparison struct {
+	selector, op string
+	args         []interface{}
+	list         bool
+}
+
+func (c comparison) String() string {
+	args := make([]string, len(c.args))
+	for i, a := range c.args {
+		args[i] = Argument(a)
+	}
+	if c.list {
+		return c.selector + c.op + "(" + strings.Join(args, ",") + ")"
+	}
+	return c.selector + c.op + args[0]
+}
+
+func (c comparison) precedence() int { return precComparison }
+
+// Eq matches when selector equals arg.  RSQL servers usually treat "*" in
+// string arguments as a wildcard.
+func Eq(selector string, arg interface{}) Node {
+	return comparison{selector, "==", []interface{}{arg}, false}
+}
+
+// Ne matches when selector does not equal arg.
+func Ne(selector string, arg interface{}) Node {
+	return comparison{selector, "!=", []interface{}{arg}, false}
+}
+
+// Lt matches when selector is less than arg.
+func Lt(selector string, arg interface{}) Node {
+	return comparison{selector, "<", []interface{}{arg}, false}
+}
+
+// Le matches when selector is less than or equal to arg.
+func Le(selector string, arg interface{}) Node {
+	return comparison{selector, "<=", []interface{}{arg}, false}
+}
+
+// Gt matches when selector is greater than arg.
+func Gt(selector string, arg interface{}) Node {
+	return comparison{selector, ">", []interface{}{arg}, false}
+}
+
+// Ge matches when selector is greater than or equal to arg.
+func Ge(selector string, arg interface{}) Node {
+	return comparison{selector, ">=", []interface{}{arg}, false}
+}
+
+// In matches when selector equals one of args.
+func In(selector string, args ...interface{}) Node {
+	return comparison{selector, "=in=", args, true}
+}
+
+// Out matches when selector equals none of args.
+func Out(selector string, args ...interface{}) Node {
+	return comparison{selector, "=out=", args, true}
+}
+
+// Compare matches using the custom comparison operator op, such as "=like=",
+// for servers defining their own operators.
+func Compare(selector, op string, args ...interface{}) Node {
+	return comparison{selector, op, args, len(args) != 1}
+}
+
+type logical struct {
+	op    string
+	prec  int
+	nodes []Node
+}
+
+func (l logical) String() string {
+	parts := make([]string, len(l.nodes))
+	for i, n := range l.nodes {
+		if n.precedence() < l.prec {
+			parts[i] = "(" + n.String() + ")"
+		} else {
+			parts[i] = n.String()
+		}
+	}
+	return strings.Join(parts, l.op)
+}
+
+func (l logical) precedence() int {
+	if len(l.nodes) == 1 {
+		return l.nodes[0].precedence()
+	}
+	return l.prec
+}
+
+// And matches when all nodes match.  It is written with ";".
+func And(nodes ...Node) Node { return logical{";", precAnd, nodes} }
+
+// Or matches when any of nodes matches.  It is written with ",".
+func Or(nodes ...Node) Node { return logical{",", precOr, nodes} }
+
+// Argument returns v as an RSQL argument.  Times are written in RFC 3339
+// format, and other values in their default format.  Arguments containing
+// reserved characters or whitespace, or that are empty, are double quoted,
+// escaping quotes and backslashes with a backslash.
+func Argument(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case time.Time:
+		s = v.Format(time.RFC3339)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s != "" && !strings.ContainsAny(s, "\"'();,=!~<> \t\r\n\\") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,59 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rsql
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestNode(t *testing.T) {
+	tests := []struct {
+		node Node
+		want string
+	}{
+		{Eq("name", "Kill*"), "name==Kill*"},
+		{Ne("name", "a b"), `name!="a b"`},
+		{Gt("year", 2003), "year>2003"},
+		{Le("created", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), "created<=2020-01-02T00:00:00Z"},
+		{In("genre", "sci-fi", "action"), "genre=in=(sci-fi,action)"},
+		{Out("id", 1, 2), "id=out=(1,2)"},
+		{Compare("title", "=like=", "star"), "title=like=star"},
+		{Eq("q", `say "hi" \o/`), `q=="say \"hi\" \\o/"`},
+		{Eq("q", ""), `q==""`},
+		{And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3))), "a==1;(b==2,c==3)"},
+		{Or(And(Eq("a", 1), Eq("b", 2)), Eq("c", 3)), "a==1;b==2,c==3"},
+		{And(Or(Eq("a", 1))), "a==1"},
+	}
+	for i, tt := range tests {
+		if got := tt.node.String(); got != tt.want {
+			t.Errorf("%d. String() returned %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestNode_field(t *testing.T) {
+	type ListOptions struct {
+		Filter Node `url:"filter,omitempty"`
+	}
+
+	opt := ListOptions{Filter: And(Eq("genres", "sci-fi"), Lt("year", 2000))}
+	v, err := query.Values(opt)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", opt, err)
+	}
+	if want := (url.Values{"filter": {"genres==sci-fi;year<2000"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+
+	v, err = query.Values(ListOptions{})
+	if err != nil || len(v) != 0 {
+		t.Errorf("Values of an empty filter returned %v, %v, want no parameters", v, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Only restricts the encoding to the fields named by names, so that a single
+// struct type can back endpoints accepting different parameters.  A field of
+// the top-level struct, or of a struct embedded in it, is named by its
+// parameter name, as in "state", or by its Go field name.  A field of a
+// nested struct is named by its scoped parameter name only, as in
+// "filter[state]", so that "state" does not select it as well.  Selecting a
+// nested struct encodes all of its fields, and selecting a field of a nested
+// struct encodes that field alone.  Only may be combined with Except, which
+// takes precedence.
+func Only(names ...string) Option {
+	return func(c *config) {
+		c.only = addNames(c.only, names)
+	}
+}
+
+// Except leaves out of the encoding the fields named by names, as for Only.
+// Excluding a nested struct leaves out all of its fields.
+func Except(names ...string) Option {
+	return func(c *config) {
+		c.except = addNames(c.except, names)
+	}
+}
+
+func addNames(set map[string]bool, names []string) map[string]bool {
+	if set == nil {
+		set = make(map[string]bool)
+	}
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+// selects reports whether the field sf, named name in its tag and scoped in
+// the URL, is encoded according to Only and Except.  It sets e.included when
+// the field is selected by Only as a whole.
+func (e *encoder) selects(sf reflect.StructField, name, scoped string) bool {
+	if e.paths != nil && !e.selectsPath() {
+		return false
+	}
+
+	// Unscoped names and Go names only apply to top-level fields
+	top := name == scoped
+	named := func(set map[string]bool) bool {
+		return set[scoped] || top && set[sf.Name]
+	}
+	if e.except != nil && named(e.except) {
+		return false
+	}
+	if e.only == nil || e.included {
+		return true
+	}
+	if named(e.only) {
+		e.included = true
+		return true
+	}
+
+	// Follow nested structs holding selected fields
+	if !nestedStructType(sf.Type) {
+		return false
+	}
+	for n := range e.only {
+		if strings.HasPrefix(n, scoped+e.nestOpen) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectsPath reports whether the field at e.path is selected by e.paths,
+// either directly, as part of a selected struct, or as a struct holding
+// selected fields.
+func (e *encoder) selectsPath() bool {
+	path := strings.Join(e.path, ".")
+	for p := range e.paths {
+		if p == path || strings.HasPrefix(path, p+".") || strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestValues_selection(t *testing.T) {
+	type Filter struct {
+		State string `url:"state"`
+		Label string `url:"label"`
+	}
+	type Embedded struct {
+		Debug bool `url:"debug"`
+	}
+	type Options struct {
+		Embedded
+		Q      string `url:"q"`
+		Page   int    `url:"page"`
+		Filter Filter `url:"filter"`
+	}
+	in := Options{Embedded{true}, "go", 2, Filter{"open", "bug"}}
+
+	tests := []struct {
+		opts []Option
+		want url.Values
+	}{
+		{
+			[]Option{Only("q", "page")},
+			url.Values{"q": {"go"}, "page": {"2"}},
+		},
+		{
+			// Go field names and whole nested structs
+			[]Option{Only("Q", "filter")},
+			url.Values{"q": {"go"}, "filter[state]": {"open"}, "filter[label]": {"bug"}},
+		},
+		{
+			// fields of nested structs and of embedded structs
+			[]Option{Only("filter[state]", "debug")},
+			url.Values{"filter[state]": {"open"}, "debug": {"true"}},
+		},
+		{
+			[]Option{Except("debug")},
+			url.Values{"q": {"go"}, "page": {"2"}, "filter[state]": {"open"}, "filter[label]": {"bug"}},
+		},
+		{
+			[]Option{Except("filter", "Page")},
+			url.Values{"q": {"go"}, "debug": {"true"}},
+		},
+		{
+			// Except takes precedence
+			[]Option{Only("filter"), Except("filter[label]")},
+			url.Values{"filter[state]": {"open"}},
+		},
+		{
+			// unscoped names do not select nested fields
+			[]Option{Only("state", "label", "q")},
+			url.Values{"q": {"go"}},
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := Values(in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, in, got, tt.want)
+		}
+	}
+}
+
+func TestValues_selectionSharedNames(t *testing.T) {
+	type Owner struct {
+		Name  string `url:"name"`
+		Email string `url:"email"`
+	}
+	type Options struct {
+		Name  string `url:"name"`
+		Owner Owner  `url:"owner"`
+	}
+	in := Options{"repo", Owner{"gopher", "gopher@example.com"}}
+
+	tests := []struct {
+		opts []Option
+		want url.Values
+	}{
+		{
+			[]Option{Only("name", "owner[email]")},
+			url.Values{"name": {"repo"}, "owner[email]": {"gopher@example.com"}},
+		},
+		{
+			[]Option{Only("Name")},
+			url.Values{"name": {"repo"}},
+		},
+		{
+			[]Option{Only("owner[name]")},
+			url.Values{"owner[name]": {"gopher"}},
+		},
+		{
+			[]Option{Except("name")},
+			url.Values{"owner[name]": {"gopher"}, "owner[email]": {"gopher@example.com"}},
+		},
+		{
+			[]Option{Except("owner[name]", "Email")},
+			url.Values{"name": {"repo"}, "owner[email]": {"gopher@example.com"}},
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := Values(in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, in, got, tt.want)
+		}
+	}
+}
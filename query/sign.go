@@ -0,0 +1,75 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+)
+
+// SignatureParam is the name of the parameter holding the signature of
+// queries produced by Sign.
+const SignatureParam = "signature"
+
+// ErrInvalidSignature is returned by Verify for queries whose signature is
+// missing or does not match their parameters.
+var ErrInvalidSignature = errors.New("query: invalid signature")
+
+// Sign encodes v using Values and returns the resulting query string with a
+// signature parameter appended, as commonly done for signed URLs and webhook
+// callbacks.  The options are the ones accepted by Values.
+//
+// The parameters are written in the canonical order of SigV4Query, and the
+// signature is the hex encoded HMAC-SHA256, under key, of that canonical query
+// string.  Because the signature covers the canonical form, queries re-encoded
+// by proxies along the way still verify.
+//
+// SignatureParam is reserved, as with WithReservedKeys: a field encoding to
+// it is reported as a *FieldError rather than dropped from the query.
+func Sign(v interface{}, key []byte, opts ...Option) (string, error) {
+	values, err := Values(v, append(opts[:len(opts):len(opts)], WithReservedKeys(SignatureParam))...)
+	if err != nil {
+		return "", err
+	}
+
+	q := canonicalQuery(values)
+	sig := signature(q, key)
+	if q == "" {
+		return SignatureParam + "=" + sig, nil
+	}
+	return q + "&" + SignatureParam + "=" + sig, nil
+}
+
+// Verify checks the signature of rawQuery, a query string produced by Sign
+// with the same key.  It returns ErrInvalidSignature if the signature is
+// missing, repeated or wrong.  The verified parameters can then be decoded
+// using Decode.
+func Verify(rawQuery string, key []byte) error {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return err
+	}
+	sigs := values[SignatureParam]
+	if len(sigs) != 1 {
+		return ErrInvalidSignature
+	}
+	values.Del(SignatureParam)
+
+	want := signature(canonicalQuery(values), key)
+	if !hmac.Equal([]byte(sigs[0]), []byte(want)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signature returns the hex encoded HMAC-SHA256 of q under key.
+func signature(q string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(q))
+	return hex.EncodeToString(mac.Sum(nil))
+}
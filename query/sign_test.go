@@ -0,0 +1,91 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	key := []byte("secret")
+	opt := struct {
+		User  string   `url:"user"`
+		Tags  []string `url:"tag"`
+		Event string   `url:"event"`
+	}{"gopher", []string{"b", "a"}, "push"}
+
+	got, err := Sign(opt, key)
+	if err != nil {
+		t.Fatalf("Sign(%v) returned error: %v", opt, err)
+	}
+	want := "event=push&tag=a&tag=b&user=gopher&signature=" + signature("event=push&tag=a&tag=b&user=gopher", key)
+	if got != want {
+		t.Errorf("Sign(%v) returned %q, want %q", opt, got, want)
+	}
+	if err := Verify(got, key); err != nil {
+		t.Errorf("Verify(%q) returned error: %v", got, err)
+	}
+
+	// reordered parameters still verify
+	sig := got[strings.Index(got, "signature="):]
+	reordered := sig + "&user=gopher&tag=a&event=push&tag=b"
+	if err := Verify(reordered, key); err != nil {
+		t.Errorf("Verify(%q) returned error: %v", reordered, err)
+	}
+}
+
+func TestVerify_invalid(t *testing.T) {
+	key := []byte("secret")
+	valid, err := Sign(struct{ A string }{"b"}, key)
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		key   []byte
+	}{
+		{valid, []byte("other")},
+		{valid + "&A=c", key},
+		{strings.Replace(valid, "A=b", "A=c", 1), key},
+		{"A=b", key},
+		{valid + "&" + valid[strings.Index(valid, "signature="):], key},
+	}
+
+	for i, tt := range tests {
+		if err := Verify(tt.query, tt.key); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%d. Verify(%q) returned %v, want ErrInvalidSignature", i, tt.query, err)
+		}
+	}
+}
+
+func TestSign_empty(t *testing.T) {
+	key := []byte("secret")
+	got, err := Sign(nil, key)
+	if err != nil {
+		t.Fatalf("Sign(nil) returned error: %v", err)
+	}
+	if !strings.HasPrefix(got, "signature=") {
+		t.Errorf("Sign(nil) returned %q, want only a signature", got)
+	}
+	if err := Verify(got, key); err != nil {
+		t.Errorf("Verify(%q) returned error: %v", got, err)
+	}
+}
+
+func TestSign_reserved(t *testing.T) {
+	opt := struct {
+		User      string `url:"user"`
+		Signature string `url:"signature"`
+	}{"gopher", "mine"}
+
+	_, err := Sign(opt, []byte("secret"))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Name != SignatureParam {
+		t.Errorf("Sign(%v) returned error %v, want a *FieldError for %q", opt, err, SignatureParam)
+	}
+}
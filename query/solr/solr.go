@@ -0,0 +1,144 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package solr builds the query parameters of Solr search requests, whose
+// conventions, such as repeated fq filters and local params, are awkward to
+// express with url tags:
+//
+//	q := solr.NewQuery("*:*").
+//		Filter("inStock:true").
+//		Filter(solr.Local("term", solr.Param{Key: "f", Value: "cat"}) + "electronics").
+//		Fields("id", "name", "price").
+//		Sort("price asc").
+//		Rows(10)
+//	v := q.Values()
+//
+// Query implements query.Encoder, so it can also be embedded in the option
+// structs encoded by the query package.
+package solr
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A Query builds the parameters of a Solr request.  Its methods return the
+// Query, so that calls can be chained.
+type Query struct {
+	values url.Values
+}
+
+// NewQuery returns a Query for the main query q.
+func NewQuery(q string) *Query {
+	return &Query{values: url.Values{"q": {q}}}
+}
+
+// Filter adds a filter query.  Each filter is sent as its own fq parameter, so
+// that Solr caches them independently.
+func (q *Query) Filter(fq string) *Query {
+	q.values.Add("fq", fq)
+	return q
+}
+
+// Fields sets the fields to return, sent as a comma separated fl parameter.
+func (q *Query) Fields(fields ...string) *Query {
+	q.values.Set("fl", strings.Join(fields, ","))
+	return q
+}
+
+// Sort sets the sort order, such as "price asc" or "score desc, id asc".
+func (q *Query) Sort(sort ...string) *Query {
+	q.values.Set("sort", strings.Join(sort, ","))
+	return q
+}
+
+// Start sets the offset of the first result to return.
+func (q *Query) Start(start int) *Query {
+	q.values.Set("start", strconv.Itoa(start))
+	return q
+}
+
+// Rows sets the number of results to return.
+func (q *Query) Rows(rows int) *Query {
+	q.values.Set("rows", strconv.Itoa(rows))
+	return q
+}
+
+// DefType sets the query parser of the main query, such as "edismax".
+func (q *Query) DefType(defType string) *Query {
+	q.values.Set("defType", defType)
+	return q
+}
+
+// FacetField enables faceting and adds a field to facet on.
+func (q *Query) FacetField(field string) *Query {
+	q.values.Set("facet", "true")
+	q.values.Add("facet.field", field)
+	return q
+}
+
+// Set sets any other parameter, replacing existing values.
+func (q *Query) Set(key, value string) *Query {
+	q.values.Set(key, value)
+	return q
+}
+
+// Add adds a value to any other parameter.
+func (q *Query) Add(key, value string) *Query {
+	q.values.Add(key, value)
+	return q
+}
+
+// Values returns a copy of the parameters of q.
+func (q *Query) Values() url.Values {
+	v := make(url.Values, len(q.values))
+	for k, vs := range q.values {
+		v[k] = append([]string(nil), vs...)
+	}
+	return v
+}
+
+// EncodeValues implements query.Encoder.  Solr parameters are not scoped, so
+// key is ignored.
+func (q *Query) EncodeValues(key string, v *url.Values) error {
+	for k, vs := range q.values {
+		(*v)[k] = append((*v)[k], vs...)
+	}
+	return nil
+}
+
+// A Param is a local parameter.
+type Param struct {
+	Key, Value string
+}
+
+// Local returns the local params prefix "{!typ key=value ...}" selecting the
+// query parser typ with params, to be followed by the query it applies to.
+// An empty typ leaves the parser unchanged.  Values are quoted when needed.
+func Local(typ string, params ...Param) string {
+	var buf strings.Builder
+	buf.WriteString("{!")
+	buf.WriteString(typ)
+	for i, p := range params {
+		if i > 0 || typ != "" {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(p.Key)
+		buf.WriteByte('=')
+		buf.WriteString(localValue(p.Value))
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// localValue returns the local param value v, single quoted with backslash
+// escapes if it is empty or contains spaces, quotes, backslashes or braces.
+func localValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t'\"\\{}") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(v) + "'"
+}
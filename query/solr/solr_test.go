@@ -0,0 +1,71 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package solr
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestQuery(t *testing.T) {
+	q := NewQuery("*:*").
+		Filter("inStock:true").
+		Filter(Local("term", Param{"f", "cat"})+"electronics").
+		Fields("id", "name").
+		Sort("price asc", "id asc").
+		Start(20).
+		Rows(10).
+		FacetField("cat").
+		FacetField("manu")
+
+	want := url.Values{
+		"q":           {"*:*"},
+		"fq":          {"inStock:true", "{!term f=cat}electronics"},
+		"fl":          {"id,name"},
+		"sort":        {"price asc,id asc"},
+		"start":       {"20"},
+		"rows":        {"10"},
+		"facet":       {"true"},
+		"facet.field": {"cat", "manu"},
+	}
+	if got := q.Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Values() returned %v, want %v", got, want)
+	}
+
+	opt := struct {
+		Solr *Query
+		WT   string `url:"wt"`
+	}{q, "json"}
+	v, err := query.Values(opt)
+	if err != nil {
+		t.Fatalf("query.Values returned error: %v", err)
+	}
+	want.Set("wt", "json")
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("query.Values returned %v, want %v", v, want)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	tests := []struct {
+		typ    string
+		params []Param
+		want   string
+	}{
+		{"lucene", nil, "{!lucene}"},
+		{"term", []Param{{"f", "cat"}}, "{!term f=cat}"},
+		{"dismax", []Param{{"qf", "title^2 body"}, {"mm", "2"}}, "{!dismax qf='title^2 body' mm=2}"},
+		{"", []Param{{"tag", "it's"}, {"ex", `a\b`}}, `{!tag='it\'s' ex='a\\b'}`},
+		{"field", []Param{{"v", ""}}, "{!field v=''}"},
+	}
+	for _, tt := range tests {
+		if got := Local(tt.typ, tt.params...); got != tt.want {
+			t.Errorf("Local(%q, %v) returned %q, want %q", tt.typ, tt.params, got, tt.want)
+		}
+	}
+}
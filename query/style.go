@@ -0,0 +1,178 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// styleDelimiters maps the OpenAPI styles supported for query parameters to
+// the delimiter of their values when not exploded.
+var styleDelimiters = map[string]string{
+	"form":           ",",
+	"spaceDelimited": " ",
+	"pipeDelimited":  "|",
+	"deepObject":     ",",
+}
+
+// WithOpenAPIStyle encodes the fields without a "style" option following the
+// given OpenAPI 3 serialization style and explode setting, as clients
+// generated from an OpenAPI specification would.  See Values for the styles
+// and their encodings.
+func WithOpenAPIStyle(style string, explode bool) Option {
+	return func(c *config) {
+		c.style, c.explode = style, explode
+	}
+}
+
+// fieldStyle returns the OpenAPI style and explode setting of a field with
+// opts, and whether it has a style at all.  A "style" option overrides the
+// style set by WithOpenAPIStyle, in which case explode defaults to true for
+// the "form" and "deepObject" styles, as specified by OpenAPI.  An "explode"
+// or "explode=false" option overrides either default.
+func (c *config) fieldStyle(opts tagOptions) (style string, explode bool, ok bool) {
+	style, ok = opts.Value("style")
+	if ok {
+		explode = style == "form" || style == "deepObject"
+	} else if c.style != "" {
+		style, explode, ok = c.style, c.explode, true
+	}
+	if !ok {
+		return "", false, false
+	}
+
+	if opts.Contains("explode") {
+		explode = true
+	} else if v, set := opts.Value("explode"); set {
+		explode = v != "false"
+	}
+	return style, explode, true
+}
+
+// styledValue adds the value sv of the field named name to values, following
+// the OpenAPI style and explode setting:
+//
+//   - form, exploded: a slice repeats the parameter, as in "id=3&id=4", and
+//     the fields of a struct become parameters of their own, as in
+//     "role=admin&firstName=Alex".
+//   - form, not exploded: the elements of a slice are joined by commas, as in
+//     "id=3,4", as are the names and values of the fields of a struct, as in
+//     "id=role,admin,firstName,Alex".
+//   - spaceDelimited and pipeDelimited, not exploded: like form, with spaces
+//     or pipes as delimiters.  Exploded, they are encoded like form.
+//   - deepObject: the fields of a struct are named "id[role]", whatever
+//     options such as WithGorillaSchema are in effect.
+func (e *encoder) styledValue(values url.Values, name string, sv reflect.Value, style string, explode bool, opts tagOptions) error {
+	del, ok := styleDelimiters[style]
+	if !ok {
+		return e.fieldError(name, &FieldError{Name: name, Err: fmt.Errorf("unknown style %q", style)})
+	}
+
+	if sv.Kind() == reflect.Struct && sv.Type() != timeType {
+		switch {
+		case style == "deepObject":
+			saved := e.config
+			c := *saved
+			c.nestOpen, c.nestClose = "[", "]"
+			e.config = &c
+			defer func() { e.config = saved }()
+			return e.reflectValue(values, sv, name)
+		case explode:
+			return e.reflectValue(values, sv, "")
+		}
+
+		// Encode the fields separately, then join their names and values.
+		sub := &encoder{config: e.config, path: e.path, redacting: e.redacting, included: true}
+		fields := make(url.Values)
+		if err := sub.reflectValue(fields, sv, ""); err != nil {
+			return err
+		}
+		e.errs = append(e.errs, sub.errs...)
+		var strs []string
+		for _, p := range sub.pairs(fields) {
+			strs = append(strs, p.Key, p.Value)
+		}
+		if err := e.claim(name); err != nil {
+			return err
+		}
+		e.add(values, name, strings.Join(strs, del))
+		return nil
+	}
+
+	if err := e.claim(name); err != nil {
+		return err
+	}
+
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		str, ok, err := e.fieldValue(name, sv, opts)
+		if ok {
+			e.add(values, name, str)
+		}
+		return err
+	}
+
+	if !explode && style != "deepObject" {
+		if err := e.separatorConflict(sv.Type(), del); err != nil {
+			return e.fieldError(name, &FieldError{Name: name, Err: err})
+		}
+	}
+	var strs []string
+	for i := 0; i < sv.Len(); i++ {
+		str, ok, err := e.fieldValue(name, sv.Index(i), opts)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if explode || style == "deepObject" {
+			e.add(values, name, str)
+		} else {
+			strs = append(strs, str)
+		}
+	}
+	if !explode && style != "deepObject" {
+		e.add(values, name, strings.Join(strs, del))
+	}
+	return nil
+}
+
+// decodeStyledStruct populates the struct sv from the URL parameter name,
+// encoded following the OpenAPI style and explode setting.  It is the inverse
+// of encoder.styledValue.
+func (d *decoder) decodeStyledStruct(sv reflect.Value, name, style string, explode bool) error {
+	switch {
+	case style == "deepObject":
+		saved := d.config
+		c := *saved
+		c.nestOpen, c.nestClose = "[", "]"
+		d.config = &c
+		defer func() { d.config = saved }()
+		return d.reflectValue(sv, name)
+	case explode:
+		return d.reflectValue(sv, "")
+	}
+
+	vs := d.values[name]
+	if len(vs) == 0 || vs[0] == "" {
+		return nil
+	}
+	strs := strings.Split(vs[0], styleDelimiters[style])
+	if len(strs)%2 != 0 {
+		return d.fieldError(name, fmt.Errorf("odd number of names and values in %q", vs[0]))
+	}
+	fields := make(url.Values)
+	for i := 0; i < len(strs); i += 2 {
+		fields.Add(strs[i], strs[i+1])
+	}
+
+	all := d.values
+	defer func() { d.values = all }()
+	d.values = fields
+	return d.reflectValue(sv, "")
+}
@@ -0,0 +1,108 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type styleObject struct {
+	Role      string `url:"role"`
+	FirstName string `url:"firstName"`
+}
+
+func TestOpenAPIStyles(t *testing.T) {
+	ids := []int{3, 4, 5}
+	obj := styleObject{"admin", "Alex"}
+
+	tests := []struct {
+		in   interface{}
+		opts []Option
+		want url.Values
+	}{
+		{
+			struct {
+				ID  []int       `url:"id,style=form"`
+				Obj styleObject `url:"obj,style=form"`
+			}{ids, obj},
+			nil,
+			url.Values{"id": {"3", "4", "5"}, "role": {"admin"}, "firstName": {"Alex"}},
+		},
+		{
+			struct {
+				ID  []int       `url:"id,style=form,explode=false"`
+				Obj styleObject `url:"obj,style=form,explode=false"`
+			}{ids, obj},
+			nil,
+			url.Values{"id": {"3,4,5"}, "obj": {"role,admin,firstName,Alex"}},
+		},
+		{
+			struct {
+				ID  []int       `url:"id,style=spaceDelimited"`
+				Obj styleObject `url:"obj,style=pipeDelimited"`
+			}{ids, obj},
+			nil,
+			url.Values{"id": {"3 4 5"}, "obj": {"role|admin|firstName|Alex"}},
+		},
+		{
+			struct {
+				ID []int `url:"id,style=pipeDelimited,explode"`
+			}{ids},
+			nil,
+			url.Values{"id": {"3", "4", "5"}},
+		},
+		{
+			struct {
+				Obj styleObject `url:"obj,style=deepObject"`
+			}{obj},
+			[]Option{WithGorillaSchema(), WithTagKey("url")},
+			url.Values{"obj[role]": {"admin"}, "obj[firstName]": {"Alex"}},
+		},
+		{
+			// the default style applies to fields without a style
+			struct {
+				ID  []int       `url:"id"`
+				Obj styleObject `url:"obj,style=deepObject"`
+				N   int         `url:"n"`
+			}{ids, obj, 1},
+			[]Option{WithOpenAPIStyle("pipeDelimited", false)},
+			url.Values{"id": {"3|4|5"}, "obj[role]": {"admin"}, "obj[firstName]": {"Alex"}, "n": {"1"}},
+		},
+	}
+
+	for i, tt := range tests {
+		v, err := Values(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, tt.in, v, tt.want)
+		}
+
+		out := reflect.New(reflect.TypeOf(tt.in))
+		if err := Decode(v, out.Interface(), tt.opts...); err != nil {
+			t.Errorf("%d. Decode(%v) returned error: %v", i, v, err)
+			continue
+		}
+		if got := out.Elem().Interface(); !reflect.DeepEqual(tt.in, got) {
+			t.Errorf("%d. Decode(%v) decoded %+v, want %+v", i, v, got, tt.in)
+		}
+	}
+}
+
+func TestOpenAPIStyles_unknown(t *testing.T) {
+	in := struct {
+		ID []int `url:"id,style=matrix"`
+	}{[]int{1}}
+	if _, err := Values(in); err == nil {
+		t.Errorf("Values(%v) returned no error for an unknown style", in)
+	}
+	if err := Check(in); err == nil {
+		t.Errorf("Check(%v) returned no error for an unknown style", in)
+	}
+}
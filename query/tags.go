@@ -0,0 +1,84 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// tagOverrides maps struct types to the tags registered for their fields
+// with OverrideTags.
+var tagOverrides sync.Map // map[reflect.Type]map[string]reflect.StructTag
+
+// OverrideTags replaces, at run time, the struct tags of fields of the struct
+// type T, so that types that cannot be edited, such as generated models or
+// types of other packages, can still be encoded and decoded as needed.  tags
+// maps Go field names of T to complete struct tags, which replace those in
+// the source:
+//
+//	func init() {
+//		err := query.OverrideTags[vendor.ListParams](map[string]string{
+//			"PageSize": `url:"per_page,omitempty"`,
+//			"Secret":   `url:"-"`,
+//		})
+//		...
+//	}
+//
+// Fields not in tags keep their tags.  A later call for the same type
+// replaces the tags of the earlier one, and a nil map removes them.  Tags
+// apply wherever the fields of T are found, including in nested and
+// embedded structs, and are followed by all functions of the package.
+// OverrideTags is meant to be called from init functions, before any value
+// of type T is encoded.
+func OverrideTags[T any](tags map[string]string) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("query: OverrideTags() expects struct type. Got %v", t)
+	}
+	if tags == nil {
+		tagOverrides.Delete(t)
+		return nil
+	}
+
+	m := make(map[string]reflect.StructTag, len(tags))
+	for name, tag := range tags {
+		if sf, ok := t.FieldByName(name); !ok || len(sf.Index) != 1 {
+			return fmt.Errorf("query: OverrideTags() %v has no field %s", t, name)
+		}
+		m[name] = reflect.StructTag(tag)
+	}
+	tagOverrides.Store(t, m)
+	return nil
+}
+
+// structField returns the i'th field of the struct type t, with the tag set
+// by OverrideTags if any.
+func structField(t reflect.Type, i int) reflect.StructField {
+	sf := t.Field(i)
+	if tags, ok := tagOverrides.Load(t); ok {
+		if tag, ok := tags.(map[string]reflect.StructTag)[sf.Name]; ok {
+			sf.Tag = tag
+		}
+	}
+	return sf
+}
+
+// fieldByName is like the FieldByName method of t, with the tag set by
+// OverrideTags for the struct type declaring the field if any.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	sf, ok := t.FieldByName(name)
+	if !ok {
+		return sf, false
+	}
+	for _, x := range sf.Index[:len(sf.Index)-1] {
+		t = indirectType(t.Field(x).Type)
+	}
+	index := sf.Index
+	sf = structField(t, index[len(index)-1])
+	sf.Index = index
+	return sf, true
+}
@@ -0,0 +1,77 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type vendorParams struct {
+	PageSize int
+	Secret   string `json:"secret"`
+	Query    string `url:"q"`
+}
+
+type vendorEmbedding struct {
+	vendorParams
+	Filter vendorParams `url:"filter"`
+}
+
+func TestOverrideTags(t *testing.T) {
+	err := OverrideTags[vendorParams](map[string]string{
+		"PageSize": `url:"per_page,omitempty"`,
+		"Secret":   `url:"-"`,
+	})
+	if err != nil {
+		t.Fatalf("OverrideTags returned error: %v", err)
+	}
+	defer OverrideTags[vendorParams](nil)
+
+	v := vendorEmbedding{
+		vendorParams: vendorParams{PageSize: 10, Secret: "s", Query: "go"},
+		Filter:       vendorParams{Secret: "s", Query: "x"},
+	}
+	got, err := Values(v)
+	want := url.Values{"per_page": {"10"}, "q": {"go"}, "filter[q]": {"x"}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Values returned %v, %v, want %v", got, err, want)
+	}
+
+	var out vendorParams
+	if err := Decode(url.Values{"per_page": {"5"}, "Secret": {"s"}}, &out); err != nil || out != (vendorParams{PageSize: 5}) {
+		t.Errorf("Decode returned %+v, %v", out, err)
+	}
+
+	if err := Check(v); err != nil {
+		t.Errorf("Check returned error: %v", err)
+	}
+	if err := OverrideTags[vendorParams](map[string]string{"PageSize": `url:"q"`}); err != nil {
+		t.Fatalf("OverrideTags returned error: %v", err)
+	}
+	if err := Check(v); err == nil {
+		t.Errorf("Check did not report the parameter produced twice by an overridden tag")
+	}
+
+	OverrideTags[vendorParams](nil)
+	got, err = Values(vendorParams{PageSize: 1})
+	want = url.Values{"PageSize": {"1"}, "Secret": {""}, "q": {""}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Values after removing overrides returned %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestOverrideTags_Errors(t *testing.T) {
+	if err := OverrideTags[int](map[string]string{}); err == nil {
+		t.Errorf("OverrideTags[int] did not return an error")
+	}
+	if err := OverrideTags[vendorParams](map[string]string{"Missing": ""}); err == nil {
+		t.Errorf("OverrideTags with an unknown field did not return an error")
+	}
+	if err := OverrideTags[vendorEmbedding](map[string]string{"PageSize": ""}); err == nil {
+		t.Errorf("OverrideTags with a promoted field did not return an error")
+	}
+}
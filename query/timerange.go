@@ -0,0 +1,220 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeRangeType = reflect.TypeOf(TimeRange{})
+
+// rangeSeparator separates the bounds of a TimeRange encoded as a single
+// value, unless the "range" option gives another.
+const rangeSeparator = ".."
+
+// TimeRange is a range of times, as used by filters on creation or update
+// times.  A zero Start or End leaves the range open on that side.  By
+// default, a TimeRange field is encoded as two parameters scoped under its
+// name, as "created[start]" and "created[end]".  The "start" and "end"
+// options name them instead, and the "range" option encodes the range as a
+// single value with the bounds separated by "..", or by the separator given
+// as its value:
+//
+//	type ListOptions struct {
+//		Created query.TimeRange `url:"created,omitempty,start=created_after,end=created_before"`
+//		Updated query.TimeRange `url:"updated,omitempty,range,dateonly"`
+//	}
+//
+//	// created_after=2013-01-01T00:00:00Z&updated=2013-01-01..2013-02-01
+//
+// Both bounds are formatted following the options that apply to time.Time
+// fields, such as "unix", "utc" and "dateonly", and WithTimeLocation and
+// WithDateOnly.  Decode reads TimeRange fields back following the same
+// options.
+type TimeRange struct {
+	Start, End time.Time
+}
+
+// IsZero reports whether both bounds of r are zero, so that the "omitempty"
+// option leaves out unbounded ranges.
+func (r TimeRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// String returns r as a single value, with the bounds in RFC 3339 format.
+func (r TimeRange) String() string {
+	return formatRangeBound(r.Start, time.RFC3339) + rangeSeparator + formatRangeBound(r.End, time.RFC3339)
+}
+
+func formatRangeBound(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// EncodeValues implements Encoder.  The bounds are encoded in RFC 3339 format
+// under key followed by "[start]" and "[end]".  Fields of type TimeRange are
+// encoded following their options instead.
+func (r TimeRange) EncodeValues(key string, v *url.Values) error {
+	if !r.Start.IsZero() {
+		v.Add(key+"[start]", r.Start.Format(time.RFC3339))
+	}
+	if !r.End.IsZero() {
+		v.Add(key+"[end]", r.End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// DecodeValues implements Decoder.  It accepts the bounds in RFC 3339 format
+// under key followed by "[start]" and "[end]", or as a single value under key
+// with the bounds separated by "..".  Fields of type TimeRange are decoded
+// following their options instead.
+func (r *TimeRange) DecodeValues(key string, v url.Values) error {
+	start, end := v.Get(key+"[start]"), v.Get(key+"[end]")
+	if s := v.Get(key); s != "" {
+		var ok bool
+		if start, end, ok = strings.Cut(s, rangeSeparator); !ok {
+			return fmt.Errorf("invalid time range %q", s)
+		}
+	}
+
+	*r = TimeRange{}
+	for _, b := range []struct {
+		s string
+		t *time.Time
+	}{{start, &r.Start}, {end, &r.End}} {
+		if b.s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, b.s)
+		if err != nil {
+			return err
+		}
+		*b.t = t
+	}
+	return nil
+}
+
+// timeRangeValue encodes the TimeRange r of the field named name with the
+// options opts.
+func (e *encoder) timeRangeValue(values url.Values, name string, r TimeRange, opts tagOptions) error {
+	var bounds [2]string
+	for i, t := range []time.Time{r.Start, r.End} {
+		if t.IsZero() {
+			continue
+		}
+		s, ok, err := e.fieldValue(name, reflect.ValueOf(t), opts)
+		if err != nil || !ok {
+			return err
+		}
+		bounds[i] = s
+	}
+
+	if sep, single := rangeOption(opts); single {
+		if err := e.claim(name); err != nil {
+			return err
+		}
+		e.add(values, name, bounds[0]+sep+bounds[1])
+		return nil
+	}
+
+	for i, option := range []string{"start", "end"} {
+		k := e.boundName(name, option, opts)
+		if err := e.claim(k); err != nil {
+			return err
+		}
+		if bounds[i] != "" {
+			e.add(values, k, bounds[i])
+		}
+	}
+	return nil
+}
+
+// rangeOption returns the separator of the bounds of a TimeRange field with
+// the options opts, and whether the range is encoded as a single value.
+func rangeOption(opts tagOptions) (string, bool) {
+	sep, single := opts.Value("range")
+	if !single && opts.Contains("range") {
+		sep, single = rangeSeparator, true
+	}
+	return sep, single
+}
+
+// boundName returns the name of the parameter of the "start" or "end" bound
+// of the TimeRange field named name with the options opts.
+func (c *config) boundName(name, option string, opts tagOptions) string {
+	if k, ok := opts.Value(option); ok {
+		return k
+	}
+	return c.scopedName(name, option)
+}
+
+// decodeTimeRange decodes the TimeRange of the field named name with the
+// options opts, the counterpart of encoder.timeRangeValue.  It reports
+// whether any of its parameters is present, and returns nil if they are all
+// empty.
+func (d *decoder) decodeTimeRange(name string, opts tagOptions) (interface{}, bool, error) {
+	var bounds [2]string
+	if sep, single := rangeOption(opts); single {
+		vs, ok := d.values[name]
+		if !ok {
+			return nil, false, nil
+		}
+		if len(vs) == 0 || vs[0] == "" {
+			return nil, true, nil
+		}
+		if bounds[0], bounds[1], ok = strings.Cut(vs[0], sep); !ok {
+			return nil, true, fmt.Errorf("invalid time range %q", vs[0])
+		}
+	} else {
+		present := false
+		for i, option := range []string{"start", "end"} {
+			if vs, ok := d.values[d.boundName(name, option, opts)]; ok {
+				present = true
+				if len(vs) > 0 {
+					bounds[i] = vs[0]
+				}
+			}
+		}
+		if !present {
+			return nil, false, nil
+		}
+	}
+	if bounds[0] == "" && bounds[1] == "" {
+		return nil, true, nil
+	}
+
+	var r TimeRange
+	for i, t := range []*time.Time{&r.Start, &r.End} {
+		if bounds[i] == "" {
+			continue
+		}
+		if err := d.setValue(reflect.ValueOf(t).Elem(), bounds[i], opts); err != nil {
+			return nil, true, err
+		}
+	}
+	return r, true, nil
+}
+
+// indirectTimeRange returns the TimeRange held by v, following pointers, and
+// whether v is a TimeRange or a pointer to one.  Nil pointers hold a zero
+// TimeRange.
+func indirectTimeRange(v reflect.Value) (TimeRange, bool) {
+	if indirectType(v.Type()) != timeRangeType || !v.CanInterface() {
+		return TimeRange{}, false
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return TimeRange{}, true
+		}
+		v = v.Elem()
+	}
+	return v.Interface().(TimeRange), true
+}
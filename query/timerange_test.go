@@ -0,0 +1,173 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeRange(t *testing.T) {
+	jan := time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2013, 2, 1, 0, 0, 0, 0, time.UTC)
+	type list struct {
+		Created TimeRange  `url:"created,omitempty,start=created_after,end=created_before"`
+		Updated TimeRange  `url:"updated,omitempty,range,dateonly"`
+		Due     *TimeRange `url:"due,omitempty,unix"`
+		Seen    TimeRange  `url:"seen,omitempty,range=/,dateonly"`
+	}
+	tests := []struct {
+		in   list
+		opts []Option
+		want url.Values
+	}{
+		{list{}, nil, url.Values{}},
+		{
+			list{
+				Created: TimeRange{Start: jan},
+				Updated: TimeRange{jan, feb},
+				Due:     &TimeRange{End: feb},
+				Seen:    TimeRange{End: feb},
+			},
+			nil,
+			url.Values{
+				"created_after": {"2013-01-01T00:00:00Z"},
+				"updated":       {"2013-01-01..2013-02-01"},
+				"due[end]":      {"1359676800"},
+				"seen":          {"/2013-02-01"},
+			},
+		},
+		{
+			list{Created: TimeRange{jan, feb}},
+			[]Option{WithGRPCGateway(), WithDateOnly()},
+			url.Values{"created_after": {"2013-01-01"}, "created_before": {"2013-02-01"}},
+		},
+		{
+			list{Due: &TimeRange{jan, feb}},
+			[]Option{WithGRPCGateway()},
+			url.Values{"due.start": {"1356998400"}, "due.end": {"1359676800"}},
+		},
+	}
+	for i, tt := range tests {
+		got, err := Values(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. Values(%+v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%+v) returned %v, want %v", i, tt.in, got, tt.want)
+		}
+	}
+
+	if err := Check(list{}); err != nil {
+		t.Errorf("Check returned error: %v", err)
+	}
+	bad := struct {
+		A time.Time `url:"a,range"`
+		B TimeRange `url:"b,start=x,utc"`
+	}{}
+	if err := Check(bad); err == nil {
+		t.Errorf("Check did not report the range option on a time.Time")
+	}
+}
+
+func TestTimeRange_Encoder(t *testing.T) {
+	jan := time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := TimeRange{Start: jan}
+	v := url.Values{}
+	if err := r.EncodeValues("t", &v); err != nil {
+		t.Fatalf("EncodeValues returned error: %v", err)
+	}
+	if want := (url.Values{"t[start]": {"2013-01-01T00:00:00Z"}}); !reflect.DeepEqual(v, want) {
+		t.Errorf("EncodeValues returned %v, want %v", v, want)
+	}
+	if got, want := r.String(), "2013-01-01T00:00:00Z.."; got != want {
+		t.Errorf("String returned %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		in   url.Values
+		want TimeRange
+		err  bool
+	}{
+		{v, r, false},
+		{url.Values{"t": {"..2013-01-01T00:00:00Z"}}, TimeRange{End: jan}, false},
+		{url.Values{"t": {"2013-01-01"}}, TimeRange{}, true},
+		{url.Values{"t[end]": {"x"}}, TimeRange{}, true},
+	}
+	for i, tt := range tests {
+		var got TimeRange
+		err := got.DecodeValues("t", tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%d. DecodeValues(%v) returned %v, %v, want %v", i, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestTimeRange_roundTrip(t *testing.T) {
+	type filter struct {
+		Created TimeRange  `url:"created,omitempty,start=created_after,end=created_before"`
+		Updated TimeRange  `url:"updated,omitempty,range,dateonly"`
+		Seen    TimeRange  `url:"seen,omitempty,range=_,unix"`
+		Due     *TimeRange `url:"due,omitempty,end=due_before,dateonly"`
+		Closed  TimeRange  `url:"closed,omitempty"`
+	}
+	start := time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC)
+	end := time.Date(2013, 2, 1, 0, 0, 0, 0, time.UTC)
+	day := time.Date(2013, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   filter
+		opts []Option
+		want string
+	}{
+		{filter{}, nil, ""},
+		{
+			filter{Created: TimeRange{Start: start}},
+			nil,
+			"created_after=2013-01-02T03%3A04%3A05Z",
+		},
+		{
+			filter{Created: TimeRange{End: end}, Updated: TimeRange{day, end}},
+			nil,
+			"created_before=2013-02-01T00%3A00%3A00Z&updated=2013-01-02..2013-02-01",
+		},
+		{
+			filter{Updated: TimeRange{End: end}, Seen: TimeRange{start, end}},
+			nil,
+			"updated=..2013-02-01&seen=1357095845_1359676800",
+		},
+		{
+			filter{Due: &TimeRange{day, end}, Closed: TimeRange{Start: start}},
+			nil,
+			"due%5Bstart%5D=2013-01-02&due_before=2013-02-01&closed%5Bstart%5D=2013-01-02T03%3A04%3A05Z",
+		},
+		{
+			filter{Closed: TimeRange{day, end}},
+			[]Option{WithDateOnly()},
+			"closed%5Bstart%5D=2013-01-02&closed%5Bend%5D=2013-02-01",
+		},
+	}
+	for i, tt := range tests {
+		got, err := EncodeString(tt.in, tt.opts...)
+		if err != nil {
+			t.Errorf("%d. EncodeString(%+v) returned error: %v", i, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d. EncodeString(%+v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+		values, _ := url.ParseQuery(got)
+		var out filter
+		if err := Decode(values, &out, tt.opts...); err != nil {
+			t.Errorf("%d. Decode(%q) returned error: %v", i, got, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tt.in) {
+			t.Errorf("%d. Decode(%q) decoded %+v, want %+v", i, got, out, tt.in)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/url"
+)
+
+// TokenParam is the name of the parameter holding the tokens produced by
+// EncryptValues.
+const TokenParam = "token"
+
+// ErrInvalidToken is returned by DecryptValues for tokens that are missing,
+// malformed, or were not produced with the same key.
+var ErrInvalidToken = errors.New("query: invalid token")
+
+// EncryptValues encodes v using Values and returns a single TokenParam
+// parameter holding the encoded query, encrypted and authenticated under key
+// with AES-GCM.  The key must be 16, 24 or 32 bytes long, selecting AES-128,
+// AES-192 or AES-256.  The options are the ones accepted by Values.
+//
+// The token is opaque and tamper-proof, which makes it suitable for
+// pagination cursors and links sent by email.  It is encoded with the URL
+// safe base64 alphabet, so it needs no escaping.  Each call uses a random
+// nonce, so encrypting the same value twice produces different tokens.
+func EncryptValues(v interface{}, key []byte, opts ...Option) (url.Values, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(values.Encode()), nil)
+	return url.Values{TokenParam: {base64.RawURLEncoding.EncodeToString(sealed)}}, nil
+}
+
+// DecryptValues decrypts the TokenParam parameter of values, produced by
+// EncryptValues with the same key, and decodes the parameters it holds into
+// v using Decode.  It returns ErrInvalidToken if the token cannot be
+// decrypted.
+func DecryptValues(values url.Values, key []byte, v interface{}, opts ...Option) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	tokens := values[TokenParam]
+	if len(tokens) != 1 {
+		return ErrInvalidToken
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(tokens[0])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return ErrInvalidToken
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	decrypted, err := url.ParseQuery(string(plaintext))
+	if err != nil {
+		return ErrInvalidToken
+	}
+	return Decode(decrypted, v, opts...)
+}
+
+// newAEAD returns AES-GCM under key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
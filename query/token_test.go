@@ -0,0 +1,83 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type tokenOptions struct {
+	After string `url:"after"`
+	Limit int    `url:"limit"`
+}
+
+func TestEncryptValues(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	in := tokenOptions{After: "id:42", Limit: 10}
+
+	values, err := EncryptValues(in, key)
+	if err != nil {
+		t.Fatalf("EncryptValues(%v) returned error: %v", in, err)
+	}
+	if len(values) != 1 || len(values[TokenParam]) != 1 {
+		t.Fatalf("EncryptValues(%v) returned %v, want a single token", in, values)
+	}
+	if token := values.Get(TokenParam); url.QueryEscape(token) != token {
+		t.Errorf("EncryptValues(%v) returned token %q needing escaping", in, token)
+	}
+
+	var out tokenOptions
+	if err := DecryptValues(values, key, &out); err != nil {
+		t.Fatalf("DecryptValues(%v) returned error: %v", values, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("DecryptValues(%v) decoded %v, want %v", values, out, in)
+	}
+
+	again, err := EncryptValues(in, key)
+	if err != nil {
+		t.Fatalf("EncryptValues(%v) returned error: %v", in, err)
+	}
+	if reflect.DeepEqual(values, again) {
+		t.Errorf("EncryptValues(%v) returned the same token twice", in)
+	}
+}
+
+func TestDecryptValues_invalid(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	valid, err := EncryptValues(tokenOptions{Limit: 1}, key)
+	if err != nil {
+		t.Fatalf("EncryptValues returned error: %v", err)
+	}
+	token := valid.Get(TokenParam)
+	tampered := []byte(token)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		values url.Values
+		key    []byte
+	}{
+		{valid, []byte("fedcba9876543210")},
+		{url.Values{TokenParam: {string(tampered)}}, key},
+		{url.Values{TokenParam: {"not base64!"}}, key},
+		{url.Values{TokenParam: {"AAAA"}}, key},
+		{url.Values{TokenParam: {token, token}}, key},
+		{url.Values{}, key},
+	}
+
+	for i, tt := range tests {
+		var out tokenOptions
+		if err := DecryptValues(tt.values, tt.key, &out); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%d. DecryptValues(%v) returned %v, want ErrInvalidToken", i, tt.values, err)
+		}
+	}
+
+	if _, err := EncryptValues(tokenOptions{}, []byte("short")); err == nil {
+		t.Errorf("EncryptValues with a short key returned no error")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// A Trace records the steps taken by Values, and the other encoding
+// functions, while walking a struct.  Unlike the messages of a Logger, its
+// steps are meant to be inspected by tools and tests.
+type Trace struct {
+	Steps []TraceStep
+}
+
+// A TraceStep records the decision taken for a struct field and the
+// parameters it wrote.
+type TraceStep struct {
+	// Field is the path of Go field names leading to the field, as in
+	// "User.Name".
+	Field string
+
+	// Param is the URL parameter name of the field, or empty if the field
+	// was skipped before its name was determined.
+	Param string
+
+	Action TraceAction
+
+	// Written holds the parameters written for the field, in order.
+	// Parameters written for the fields of nested structs are recorded by
+	// the steps of those fields.
+	Written []Pair
+}
+
+// A TraceAction is the decision taken for a struct field.
+type TraceAction string
+
+const (
+	TraceUnexported TraceAction = "unexported" // skipped as unexported
+	TraceIgnored    TraceAction = "ignored"    // skipped by its tag
+	TraceUntagged   TraceAction = "untagged"   // skipped for lacking a tag
+	TraceNil        TraceAction = "nil"        // skipped as a nil embedded struct pointer
+	TraceOmitted    TraceAction = "omitted"    // skipped by the "omitempty" option
+	TraceFile       TraceAction = "file"       // skipped by the "file" option
+	TraceExcluded   TraceAction = "excluded"   // skipped by Only or Except
+	TraceInvalid    TraceAction = "invalid"    // skipped for breaking a constraint of its tag
+	TraceEmbedded   TraceAction = "embedded"   // fields flattened into the embedding struct
+	TraceNested     TraceAction = "nested"     // fields scoped under the field's name
+	TraceEncoder    TraceAction = "encoder"    // encoded by its EncodeValues method
+	TraceEncoded    TraceAction = "encoded"    // encoded by the rules of Values
+)
+
+// String returns a one line description of s, as in
+// "User.Name (user[name]): encoded user[name]=acme".
+func (s TraceStep) String() string {
+	var b strings.Builder
+	b.WriteString(s.Field)
+	if s.Param != "" {
+		b.WriteString(" (" + s.Param + ")")
+	}
+	b.WriteString(": " + string(s.Action))
+	for _, p := range s.Written {
+		b.WriteString(" " + p.Key + "=" + p.Value)
+	}
+	return b.String()
+}
+
+// WithTrace appends the steps of the encoding to t.  Steps are recorded in
+// the order fields are visited, so the steps of embedded structs follow those
+// of the embedding struct unless WithEmbeddedOrder(EmbeddedInline) is given.
+func WithTrace(t *Trace) Option {
+	return func(c *config) {
+		c.trace = t
+	}
+}
+
+// traceStep records the action taken for the field being encoded, which is
+// named param.
+func (e *encoder) traceStep(action TraceAction, param string) {
+	if e.trace == nil {
+		return
+	}
+	e.trace.Steps = append(e.trace.Steps, TraceStep{
+		Field:  strings.Join(e.path, "."),
+		Param:  param,
+		Action: action,
+	})
+}
+
+// traceValue records the parameter k=s as written by the last step.
+func (e *encoder) traceValue(k, s string) {
+	if e.trace == nil || len(e.trace.Steps) == 0 {
+		return
+	}
+	if e.redacting {
+		s = Redacted
+	}
+	last := &e.trace.Steps[len(e.trace.Steps)-1]
+	last.Written = append(last.Written, Pair{k, s})
+}
+
+// countValues returns the number of values of each parameter of values when
+// tracing or yielding, to be passed to noteAdded.
+func (e *encoder) countValues(values url.Values) map[string]int {
+	if e.trace == nil && e.yield == nil {
+		return nil
+	}
+	counts := make(map[string]int, len(values))
+	for k, vs := range values {
+		counts[k] = len(vs)
+	}
+	return counts
+}
+
+// noteAdded traces and yields the values added to values since countValues
+// returned counts, such as those added by a custom Encoder, in the order of
+// their keys.
+func (e *encoder) noteAdded(values url.Values, counts map[string]int) {
+	if counts == nil {
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		vs := values[k]
+		if n := counts[k]; n < len(vs) {
+			for _, s := range vs[n:] {
+				e.traceValue(k, s)
+				e.emit(k, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	type Inner struct {
+		B string `url:"b"`
+	}
+	type Embedded struct {
+		E string `url:"e"`
+	}
+	in := struct {
+		Embedded
+		A      []string    `url:"a,comma"`
+		N      Inner       `url:"n"`
+		O      string      `url:"o,omitempty"`
+		I      string      `url:"-"`
+		C      EncodedArgs `url:"c"`
+		hidden string
+	}{
+		Embedded: Embedded{"x"},
+		A:        []string{"1", "2"},
+		N:        Inner{"y"},
+		C:        EncodedArgs{"p", "q"},
+	}
+
+	var tr Trace
+	if _, err := Values(in, WithTrace(&tr)); err != nil {
+		t.Fatalf("Values(%v) returned error: %v", in, err)
+	}
+
+	want := []TraceStep{
+		{Field: "Embedded", Action: TraceEmbedded},
+		{Field: "A", Param: "a", Action: TraceEncoded, Written: []Pair{{"a", "1,2"}}},
+		{Field: "N", Param: "n", Action: TraceNested},
+		{Field: "N.B", Param: "n[b]", Action: TraceEncoded, Written: []Pair{{"n[b]", "y"}}},
+		{Field: "O", Param: "o", Action: TraceOmitted},
+		{Field: "I", Action: TraceIgnored},
+		{Field: "C", Param: "c", Action: TraceEncoder, Written: []Pair{{"c.0", "p"}, {"c.1", "q"}}},
+		{Field: "hidden", Action: TraceUnexported},
+		{Field: "Embedded.E", Param: "e", Action: TraceEncoded, Written: []Pair{{"e", "x"}}},
+	}
+	if !reflect.DeepEqual(tr.Steps, want) {
+		t.Errorf("WithTrace recorded:\n%v\nwant:\n%v", tr.Steps, want)
+	}
+
+	if got, want := want[3].String(), "N.B (n[b]): encoded n[b]=y"; got != want {
+		t.Errorf("TraceStep.String() returned %q, want %q", got, want)
+	}
+}
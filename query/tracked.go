@@ -0,0 +1,197 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Tracked wraps a struct of type T, remembering which of its fields were
+// explicitly assigned so that only those are encoded.  This tells a field set
+// to its zero value apart from one left unset, as needed for sparse PATCH
+// requests, without pointer fields:
+//
+//	var t query.Tracked[UpdateOptions]
+//	t.Set("Archived", false)
+//	t.Set("Owner.Name", "gopher")
+//	v, err := t.Values() // archived=false&owner[name]=gopher
+//
+// Fields are named by their Go names, joined by dots for fields of nested
+// structs; fields of embedded structs may be named directly.  Setting a
+// nested struct as a whole encodes all of its fields.  The options of the
+// url tags still apply, so tracked fields should not use "omitempty".  The
+// zero value is an empty Tracked ready to use.
+type Tracked[T any] struct {
+	v   T
+	set map[string]bool // Go paths of the assigned fields
+}
+
+// Track returns a Tracked holding v with no fields assigned.
+func Track[T any](v T) *Tracked[T] {
+	return &Tracked[T]{v: v}
+}
+
+// Value returns a pointer to the wrapped struct.  Fields changed through it
+// must be recorded with Mark to be encoded.
+func (t *Tracked[T]) Value() *T {
+	return &t.v
+}
+
+// Set assigns value to the named field and marks it as assigned.  value must
+// be assignable to the field, or a number or a value of the same kind
+// convertible to it; nil sets the zero value.  Nil pointers to the structs
+// holding the field are allocated.
+func (t *Tracked[T]) Set(field string, value interface{}) error {
+	path, f, err := t.field(field, true)
+	if err != nil {
+		return err
+	}
+	if !f.CanSet() {
+		return fmt.Errorf("query: Tracked.Set() cannot set field %s", field)
+	}
+	if value == nil {
+		f.Set(reflect.Zero(f.Type()))
+	} else {
+		rv := reflect.ValueOf(value)
+		switch {
+		case rv.Type().AssignableTo(f.Type()):
+		case rv.Type().ConvertibleTo(f.Type()) &&
+			(rv.Kind() == f.Kind() || isNumberKind(rv.Kind()) && isNumberKind(f.Kind())):
+			rv = rv.Convert(f.Type())
+		default:
+			return fmt.Errorf("query: Tracked.Set() cannot assign %T to field %s of type %v", value, field, f.Type())
+		}
+		f.Set(rv)
+	}
+	t.mark(path)
+	return nil
+}
+
+// Mark marks the named fields as assigned, such as after changing them
+// through Value.
+func (t *Tracked[T]) Mark(fields ...string) error {
+	for _, field := range fields {
+		path, _, err := t.field(field, false)
+		if err != nil {
+			return err
+		}
+		t.mark(path)
+	}
+	return nil
+}
+
+// IsSet reports whether the named field was assigned.
+func (t *Tracked[T]) IsSet(field string) bool {
+	path, _, err := t.field(field, false)
+	return err == nil && t.set[path]
+}
+
+// Fields returns the Go paths of the assigned fields, in sorted order.
+// Fields of embedded structs are named with the embedded struct fields, as
+// in "Page.PerPage".
+func (t *Tracked[T]) Fields() []string {
+	fields := make([]string, 0, len(t.set))
+	for f := range t.set {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Reset forgets which fields were assigned, keeping their values.
+func (t *Tracked[T]) Reset() {
+	t.set = nil
+}
+
+// Values encodes the assigned fields of the wrapped struct like Values.
+func (t *Tracked[T]) Values(opts ...Option) (url.Values, error) {
+	if len(t.set) == 0 {
+		if _, err := structType(t.v); err != nil {
+			return nil, fmt.Errorf("query: Tracked.Values() %v", err)
+		}
+		return make(url.Values), nil
+	}
+	return Values(t.v, append(opts[:len(opts):len(opts)], onlyPaths(t.set))...)
+}
+
+// onlyPaths restricts the encoding to the fields at the Go paths of the set
+// paths, and to the fields of the structs among them.  Unlike Only, fields
+// are told apart by their paths even when sharing parameter names.
+func onlyPaths(paths map[string]bool) Option {
+	return func(c *config) {
+		c.paths = paths
+	}
+}
+
+// EncodeString encodes the assigned fields of the wrapped struct like
+// EncodeString, in field order and with the escaping selected by opts.
+func (t *Tracked[T]) EncodeString(opts ...Option) (string, error) {
+	if len(t.set) == 0 {
+		if _, err := structType(t.v); err != nil {
+			return "", fmt.Errorf("query: Tracked.EncodeString() %v", err)
+		}
+		return "", nil
+	}
+	return EncodeString(t.v, append(opts[:len(opts):len(opts)], onlyPaths(t.set))...)
+}
+
+func (t *Tracked[T]) mark(path string) {
+	if t.set == nil {
+		t.set = make(map[string]bool)
+	}
+	t.set[path] = true
+}
+
+// field returns the full Go path of the named field of the wrapped struct,
+// naming embedded structs, and the field itself if settable.  Nil pointers
+// on the way are allocated if alloc is set.
+func (t *Tracked[T]) field(name string, alloc bool) (string, reflect.Value, error) {
+	v := reflect.ValueOf(&t.v).Elem()
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !alloc {
+				break
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	st := indirectType(v.Type())
+	if st.Kind() != reflect.Struct {
+		return "", reflect.Value{}, fmt.Errorf("query: Tracked expects struct type. Got %v", reflect.TypeOf(&t.v).Elem())
+	}
+
+	var path []string
+	for _, n := range strings.Split(name, ".") {
+		sf, ok := st.FieldByName(n)
+		if !ok || sf.PkgPath != "" {
+			return "", reflect.Value{}, fmt.Errorf("query: Tracked has no field %s in %v", name, reflect.TypeOf(&t.v).Elem())
+		}
+		path = append(path, goPath(st, sf.Index))
+		st = indirectType(sf.Type)
+		if !alloc {
+			continue
+		}
+		for i, x := range sf.Index {
+			if i > 0 || len(path) > 1 {
+				for v.Kind() == reflect.Ptr {
+					if v.IsNil() && !v.CanSet() {
+						return "", reflect.Value{}, fmt.Errorf("query: Tracked cannot allocate field %s", name)
+					}
+					if v.IsNil() {
+						v.Set(reflect.New(v.Type().Elem()))
+					}
+					v = v.Elem()
+				}
+			}
+			v = v.Field(x)
+		}
+	}
+	return strings.Join(path, "."), v, nil
+}
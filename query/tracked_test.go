@@ -0,0 +1,157 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+type trackedPage struct {
+	PerPage int `url:"per_page"`
+}
+
+type trackedOwner struct {
+	Name string `url:"name"`
+	ID   int64  `url:"id"`
+}
+
+type trackedUpdate struct {
+	trackedPage
+	Archived bool          `url:"archived"`
+	Title    string        `url:"title"`
+	Owner    *trackedOwner `url:"owner"`
+}
+
+func TestTracked(t *testing.T) {
+	var tr Tracked[trackedUpdate]
+	if got, err := tr.EncodeString(); err != nil || got != "" {
+		t.Errorf("EncodeString() of an empty Tracked returned %q, %v", got, err)
+	}
+
+	for _, s := range []struct {
+		field string
+		value interface{}
+	}{
+		{"Archived", false},
+		{"Owner.Name", "gopher"},
+		{"Owner.ID", 7},
+		{"PerPage", 0},
+	} {
+		if err := tr.Set(s.field, s.value); err != nil {
+			t.Fatalf("Set(%q, %v) returned error: %v", s.field, s.value, err)
+		}
+	}
+	got, err := tr.EncodeString()
+	if want := "archived=false&owner%5Bname%5D=gopher&owner%5Bid%5D=7&per_page=0"; err != nil || got != want {
+		t.Errorf("EncodeString() returned %q, %v, want %q", got, err, want)
+	}
+	if want := []string{"Archived", "Owner.ID", "Owner.Name", "trackedPage.PerPage"}; !reflect.DeepEqual(tr.Fields(), want) {
+		t.Errorf("Fields() returned %v, want %v", tr.Fields(), want)
+	}
+	if !tr.IsSet("PerPage") || tr.IsSet("Title") {
+		t.Errorf("IsSet() returned wrong results for %v", tr.Fields())
+	}
+
+	tr.Reset()
+	tr.Value().Title = "new"
+	if err := tr.Mark("Title"); err != nil {
+		t.Fatalf("Mark returned error: %v", err)
+	}
+	if got, err := tr.EncodeString(); err != nil || got != "title=new" {
+		t.Errorf("EncodeString() after Mark returned %q, %v, want %q", got, err, "title=new")
+	}
+
+	p := Track(&trackedUpdate{Title: "x"})
+	if err := p.Set("Owner", &trackedOwner{Name: "a"}); err != nil {
+		t.Fatalf("Set(Owner) returned error: %v", err)
+	}
+	if got, err := p.EncodeString(); err != nil || got != "owner%5Bname%5D=a&owner%5Bid%5D=0" {
+		t.Errorf("EncodeString() of a nested struct returned %q, %v", got, err)
+	}
+}
+
+func TestTracked_sharedNames(t *testing.T) {
+	type owner struct {
+		Name  string `url:"name"`
+		Email string `url:"email"`
+	}
+	type repo struct {
+		Name  string `url:"name"`
+		Owner owner  `url:"owner"`
+	}
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{[]string{"Name", "Owner.Email"}, "name=&owner%5Bemail%5D="},
+		{[]string{"Owner.Name"}, "owner%5Bname%5D="},
+		{[]string{"Owner"}, "owner%5Bname%5D=&owner%5Bemail%5D="},
+	}
+	for i, tt := range tests {
+		var tr Tracked[repo]
+		for _, f := range tt.fields {
+			if err := tr.Set(f, nil); err != nil {
+				t.Fatalf("%d. Set(%q) returned error: %v", i, f, err)
+			}
+		}
+		got, err := tr.EncodeString()
+		if err != nil || got != tt.want {
+			t.Errorf("%d. EncodeString() after setting %v returned %q, %v, want %q", i, tt.fields, got, err, tt.want)
+		}
+	}
+}
+
+func TestTracked_options(t *testing.T) {
+	type s struct {
+		Z int    `url:"z"`
+		A string `url:"a"`
+	}
+	var tr Tracked[s]
+	if err := tr.Set("A", "x y"); err != nil {
+		t.Fatalf("Set(A) returned error: %v", err)
+	}
+	if err := tr.Set("Z", 1); err != nil {
+		t.Fatalf("Set(Z) returned error: %v", err)
+	}
+	want, err := EncodeString(tr.v, WithStrictEscaping())
+	if err != nil {
+		t.Fatalf("EncodeString returned error: %v", err)
+	}
+	got, err := tr.EncodeString(WithStrictEscaping())
+	if err != nil || got != want || got != "z=1&a=x%20y" {
+		t.Errorf("EncodeString(WithStrictEscaping()) returned %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestTracked_Errors(t *testing.T) {
+	var tr Tracked[trackedUpdate]
+	tests := []struct {
+		field string
+		value interface{}
+	}{
+		{"Missing", 1},
+		{"Title", 1},
+		{"Archived", "true"},
+		{"Owner.Missing", ""},
+		{"trackedPage", trackedPage{}},
+	}
+	for i, tt := range tests {
+		if err := tr.Set(tt.field, tt.value); err == nil {
+			t.Errorf("%d. Set(%q, %v) did not return an error", i, tt.field, tt.value)
+		}
+	}
+	if err := tr.Mark("Missing"); err == nil {
+		t.Errorf("Mark(Missing) did not return an error")
+	}
+
+	var n Tracked[int]
+	if err := n.Set("A", 1); err == nil {
+		t.Errorf("Set on Tracked[int] did not return an error")
+	}
+	if _, err := n.Values(); err == nil {
+		t.Errorf("Values on Tracked[int] did not return an error")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// BuildURL parses base, merges the query parameters encoded from v into its
+// existing query, and returns the resulting URL.  Parameters produced by v
+// replace existing parameters of the same name; all other parameters of base
+// are kept.  The query is re-encoded by url.Values.Encode, so parameters are
+// sorted by name and escaped consistently.  International hosts are
+// converted to punycode, as described for Builder.Build.
+func BuildURL(base string, v interface{}, opts ...Option) (string, error) {
+	return URL(base).With(v, opts...).Build()
+}
+
+// SplitURL is like BuildURL, but splits a URL longer than maxLength bytes
+// into several URLs of at most maxLength bytes, for batch GET endpoints
+// taking many values of a parameter, as in "ids=1&ids=2&...".  The repeated
+// parameter of v with the most bytes is split into chunks, in order, each
+// URL holding a chunk along with all other parameters.  A URL that is not
+// too long is returned alone.
+//
+// An error is returned if v has no parameter with several values to split,
+// or if a URL is too long even with a single value of it.  Values joined
+// into one parameter, as with the "comma" option, are not split.
+func SplitURL(base string, v interface{}, maxLength int, opts ...Option) ([]string, error) {
+	values, err := Values(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	b := URL(base)
+	if b.err != nil {
+		return nil, b.err
+	}
+	mergeValues(b.values, values)
+	full, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	if len(full) <= maxLength {
+		return []string{full}, nil
+	}
+
+	// Split the repeated parameter with the most bytes, the first in name
+	// order on ties
+	key, most := "", 0
+	for k, vs := range values {
+		if len(vs) < 2 {
+			continue
+		}
+		n := 0
+		for _, s := range vs {
+			n += len(url.QueryEscape(k)) + len(url.QueryEscape(s)) + 2
+		}
+		if n > most || n == most && k < key {
+			key, most = k, n
+		}
+	}
+	if key == "" {
+		return nil, fmt.Errorf("query: SplitURL() URL of %d bytes is longer than %d, with no repeated parameter to split", len(full), maxLength)
+	}
+
+	build := func(chunk []string) (string, error) {
+		b.values[key] = chunk
+		u, _ := b.Build()
+		if len(u) > maxLength && len(chunk) == 1 {
+			return "", fmt.Errorf("query: SplitURL() URL with a single value of %q is %d bytes, longer than %d", key, len(u), maxLength)
+		}
+		return u, nil
+	}
+	var urls []string
+	var chunk []string
+	last := ""
+	for _, s := range values[key] {
+		chunk = append(chunk, s)
+		u, err := build(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(u) <= maxLength {
+			last = u
+			continue
+		}
+		urls = append(urls, last)
+		chunk = []string{s}
+		if last, err = build(chunk); err != nil {
+			return nil, err
+		}
+	}
+	return append(urls, last), nil
+}
+
+// BuildFragmentURL is like BuildURL, but places the parameters encoded from v
+// in the fragment of base rather than in its query, as in
+// "https://example.com/cb#access_token=...&state=...".  This is the form used
+// by the OAuth 2.0 implicit flow and by single page applications for deep
+// links, since fragments are not sent to servers.  Parameters already in the
+// fragment of base are kept unless v replaces them.
+func BuildFragmentURL(base string, v interface{}, opts ...Option) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	values, err := Values(v, opts...)
+	if err != nil {
+		return "", err
+	}
+	existing, err := url.ParseQuery(u.EscapedFragment())
+	if err != nil {
+		return "", err
+	}
+
+	fragment := mergeValues(existing, values).Encode()
+	u.Fragment, u.RawFragment = "", ""
+	if err := asciiHost(u); err != nil {
+		return "", err
+	}
+	if fragment == "" {
+		return u.String(), nil
+	}
+	return u.String() + "#" + fragment, nil
+}
+
+// DecodeFragment decodes the parameters in the fragment of rawurl into v,
+// using Decode.  It is the inverse of BuildFragmentURL.
+func DecodeFragment(rawurl string, v interface{}, opts ...Option) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(u.EscapedFragment())
+	if err != nil {
+		return err
+	}
+	return Decode(values, v, opts...)
+}
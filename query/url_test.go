@@ -0,0 +1,149 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildURL(t *testing.T) {
+	opt := struct {
+		Query string `url:"q"`
+		Page  int    `url:"page,omitempty"`
+	}{"a b&c", 2}
+
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"http://example.com/search", "http://example.com/search?page=2&q=a+b%26c"},
+		{"http://example.com/search?", "http://example.com/search?page=2&q=a+b%26c"},
+		{"http://example.com/search?page=1&sort=a%2Cb", "http://example.com/search?page=2&q=a+b%26c&sort=a%2Cb"},
+		{"http://example.com/a%20b/?x=1#frag", "http://example.com/a%20b/?page=2&q=a+b%26c&x=1#frag"},
+		{"/relative", "/relative?page=2&q=a+b%26c"},
+		{"https://Bücher.example:8443/straße#ä", "https://xn--bcher-kva.example:8443/stra%C3%9Fe?page=2&q=a+b%26c#%C3%A4"},
+		{"https://b%C3%BCcher.example/", "https://xn--bcher-kva.example/?page=2&q=a+b%26c"},
+		{"https://правительство.рф", "https://xn--80aealotwbjpid2k.xn--p1ai?page=2&q=a+b%26c"},
+	}
+
+	for i, tt := range tests {
+		got, err := BuildURL(tt.base, opt)
+		if err != nil {
+			t.Errorf("%d. BuildURL(%q, %v) returned error: %v", i, tt.base, opt, err)
+		}
+		if got != tt.want {
+			t.Errorf("%d. BuildURL(%q, %v) returned %q, want %q", i, tt.base, opt, got, tt.want)
+		}
+	}
+
+	if _, err := BuildURL("http://[::1", opt); err == nil {
+		t.Errorf("expected BuildURL() to return an error on an invalid base URL")
+	}
+	if _, err := BuildURL("http://example.com", ""); err == nil {
+		t.Errorf("expected BuildURL() to return an error on invalid input")
+	}
+}
+
+func TestBuildFragmentURL(t *testing.T) {
+	opt := struct {
+		Token string `url:"access_token"`
+		State string `url:"state,omitempty"`
+	}{"a b/c", "xyz"}
+
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"https://example.com/cb", "https://example.com/cb#access_token=a+b%2Fc&state=xyz"},
+		{"https://example.com/cb?x=1#", "https://example.com/cb?x=1#access_token=a+b%2Fc&state=xyz"},
+		{"https://example.com/cb#state=old&expires_in=3600", "https://example.com/cb#access_token=a+b%2Fc&expires_in=3600&state=xyz"},
+	}
+
+	for i, tt := range tests {
+		got, err := BuildFragmentURL(tt.base, opt)
+		if err != nil {
+			t.Errorf("%d. BuildFragmentURL(%q, %v) returned error: %v", i, tt.base, opt, err)
+		}
+		if got != tt.want {
+			t.Errorf("%d. BuildFragmentURL(%q, %v) returned %q, want %q", i, tt.base, opt, got, tt.want)
+		}
+	}
+
+	if got, err := BuildFragmentURL("https://example.com/cb", nil); err != nil || got != "https://example.com/cb" {
+		t.Errorf("BuildFragmentURL(nil) returned %q, %v, want no fragment", got, err)
+	}
+}
+
+func TestSplitURL(t *testing.T) {
+	opt := struct {
+		IDs    []string `url:"id"`
+		Tags   []string `url:"tag"`
+		Format string   `url:"format"`
+	}{[]string{"1", "22", "333", "4444"}, []string{"a", "b"}, "json"}
+
+	const (
+		base = "http://example.com/items"
+		rest = base + "?format=json"
+	)
+	tests := []struct {
+		maxLength int
+		want      []string
+	}{
+		{0, nil},
+		{50, nil},
+		{60, []string{
+			rest + "&id=1&id=22&tag=a&tag=b",
+			rest + "&id=333&tag=a&tag=b",
+			rest + "&id=4444&tag=a&tag=b",
+		}},
+		{66, []string{
+			rest + "&id=1&id=22&id=333&tag=a&tag=b",
+			rest + "&id=4444&tag=a&tag=b",
+		}},
+		{74, []string{rest + "&id=1&id=22&id=333&id=4444&tag=a&tag=b"}},
+		{1000, []string{rest + "&id=1&id=22&id=333&id=4444&tag=a&tag=b"}},
+	}
+
+	for i, tt := range tests {
+		got, err := SplitURL(base, opt, tt.maxLength)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%d. SplitURL(%q, %v, %d) returned %q, want error", i, base, opt, tt.maxLength, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d. SplitURL(%q, %v, %d) returned error: %v", i, base, opt, tt.maxLength, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. SplitURL(%q, %v, %d) returned %q, want %q", i, base, opt, tt.maxLength, got, tt.want)
+		}
+	}
+
+	single := struct {
+		Query string `url:"q"`
+	}{"long query"}
+	if _, err := SplitURL(base, single, 30); err == nil {
+		t.Errorf("expected SplitURL() to return an error without a repeated parameter")
+	}
+	if _, err := SplitURL("http://[::1", opt, 100); err == nil {
+		t.Errorf("expected SplitURL() to return an error on an invalid base URL")
+	}
+}
+
+func TestDecodeFragment(t *testing.T) {
+	var got struct {
+		Token     string `url:"access_token"`
+		ExpiresIn int    `url:"expires_in"`
+	}
+	rawurl := "https://example.com/cb?access_token=query#access_token=a+b%2Fc&expires_in=3600"
+	if err := DecodeFragment(rawurl, &got); err != nil {
+		t.Fatalf("DecodeFragment(%q) returned error: %v", rawurl, err)
+	}
+	if got.Token != "a b/c" || got.ExpiresIn != 3600 {
+		t.Errorf("DecodeFragment(%q) decoded %+v", rawurl, got)
+	}
+}
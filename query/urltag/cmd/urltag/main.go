@@ -0,0 +1,18 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The urltag command checks the url struct tags used by the query package.
+// It is meant to be run by go vet:
+//
+//	go vet -vettool=$(which urltag) ./...
+package main
+
+import (
+	"github.com/google/go-querystring/query/urltag"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(urltag.Analyzer)
+}
@@ -0,0 +1,283 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package urltag defines an Analyzer that reports mistakes in the url struct
+// tags read by the query package, so that they are caught at build time
+// rather than by producing unexpected queries.  It reports:
+//
+//   - url tags that cannot be parsed
+//   - unknown tag options and OpenAPI styles
+//   - conflicting delimiter options, and delimiter options on fields that
+//     are not slices or arrays
+//   - the "int" option on fields that are not bools, the "unix", "utc",
+//     "tz" and "dateonly" options on fields that are not time.Time values,
+//     and the "file" option on fields that are neither io.Readers nor []byte
+//   - the "start", "end" and "range" options on fields that are not
+//     query.TimeRange values, and the "precision" and "lnglat" options on
+//     fields that are not query.LatLng or query.BoundingBox values
+//   - the "minor" and "currency" options on fields that are not query.Money
+//     values
+//   - fields of a struct that encode to the same URL parameter name
+//
+// These are the static counterparts of the checks done by query.Check.  The
+// analyzer can be run by go vet through the command in the cmd/urltag
+// directory:
+//
+//	go install github.com/google/go-querystring/query/urltag/cmd/urltag@latest
+//	go vet -vettool=$(which urltag) ./...
+package urltag
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports mistakes in url struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name: "urltag",
+	Doc:  "check url struct tags used by github.com/google/go-querystring/query",
+	Run:  run,
+}
+
+// knownOptions lists the options recognized in url tags, as query.Check
+// does.  Options taking a value are mapped to true.
+var knownOptions = map[string]bool{
+	"omitempty": false,
+	"int":       false,
+	"unix":      false,
+	"comma":     false,
+	"space":     false,
+	"semicolon": false,
+	"brackets":  false,
+	"numbered":  false,
+	"required":  false,
+	"file":      false,
+	"style":     true,
+	"explode":   true,
+	"default":   true,
+	"redact":    false,
+	"min":       true,
+	"max":       true,
+	"len":       true,
+	"utc":       false,
+	"tz":        true,
+	"dateonly":  false,
+	"start":     true,
+	"end":       true,
+	"range":     true,
+	"precision": true,
+	"lnglat":    false,
+	"minor":     false,
+	"currency":  true,
+}
+
+// queryPath is the import path of the query package.
+const queryPath = "github.com/google/go-querystring/query"
+
+// delimiterOptions lists the options that control how slices and arrays are
+// encoded.
+var delimiterOptions = []string{"comma", "space", "semicolon", "brackets", "numbered"}
+
+var styles = map[string]bool{
+	"form":           true,
+	"spaceDelimited": true,
+	"pipeDelimited":  true,
+	"deepObject":     true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				checkStruct(pass, st)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// checkStruct checks the url tags of the fields of st.
+func checkStruct(pass *analysis.Pass, st *ast.StructType) {
+	// names maps each URL parameter name seen to the field producing it
+	names := make(map[string]string)
+
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			tag, _ = strconv.Unquote(field.Tag.Value)
+		}
+		value, ok := reflect.StructTag(tag).Lookup("url")
+		if !ok {
+			if strings.Contains(tag, "url:") {
+				pass.Reportf(field.Tag.Pos(), "malformed url tag %s", field.Tag.Value)
+			}
+			if len(field.Names) == 0 {
+				// embedded fields without a name are flattened
+				continue
+			}
+		}
+		if value == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(value, ",")
+		var options []string
+		if opts != "" {
+			options = strings.Split(opts, ",")
+		}
+		t := pass.TypesInfo.TypeOf(field.Type)
+		if t != nil && field.Tag != nil {
+			checkOptions(pass, field, t, options)
+		}
+
+		fieldNames := field.Names
+		if len(fieldNames) == 0 {
+			if name == "" {
+				continue
+			}
+			fieldNames = []*ast.Ident{{Name: name, NamePos: field.Pos()}}
+		}
+		for _, id := range fieldNames {
+			if !id.IsExported() && len(field.Names) > 0 {
+				continue
+			}
+			param := name
+			if param == "" {
+				param = id.Name
+			}
+			if contains(options, "brackets") {
+				param += "[]"
+			}
+			if prev, ok := names[param]; ok {
+				pass.Reportf(id.Pos(), "URL parameter %q of field %s is also produced by field %s", param, id.Name, prev)
+				continue
+			}
+			names[param] = id.Name
+		}
+	}
+}
+
+// checkOptions checks the tag options of field, of type t.
+func checkOptions(pass *analysis.Pass, field *ast.Field, t types.Type, options []string) {
+	pos := field.Tag.Pos()
+	var delims []string
+	for _, o := range options {
+		key, value, hasValue := strings.Cut(o, "=")
+		takesValue, known := knownOptions[key]
+		if !known || hasValue && !takesValue {
+			pass.Reportf(pos, "unknown url tag option %q", o)
+		}
+		if key == "style" && !styles[value] {
+			pass.Reportf(pos, "unknown OpenAPI style %q", value)
+		}
+	}
+	for _, o := range delimiterOptions {
+		if contains(options, o) {
+			delims = append(delims, o)
+		}
+	}
+	if len(delims) > 1 {
+		pass.Reportf(pos, "conflicting url tag options %s", strings.Join(delims, ", "))
+	}
+
+	under := deref(t).Underlying()
+	_, isSlice := under.(*types.Slice)
+	_, isArray := under.(*types.Array)
+	if len(delims) > 0 && !isSlice && !isArray {
+		pass.Reportf(pos, "url tag option %q requires a slice or array, not %s", delims[0], t)
+	}
+
+	et := elem(t)
+	if contains(options, "int") && !isBasic(et, types.IsBoolean) {
+		pass.Reportf(pos, `url tag option "int" requires a bool, not %s`, t)
+	}
+	for _, o := range options {
+		key, _, _ := strings.Cut(o, "=")
+		if (key == "unix" || key == "utc" || key == "tz" || key == "dateonly") && !isNamed(et, "time", "Time") && !isNamed(et, queryPath, "TimeRange") {
+			pass.Reportf(pos, "url tag option %q requires a time.Time, not %s", key, t)
+		}
+		if (key == "start" || key == "end" || key == "range") && !isNamed(deref(t), queryPath, "TimeRange") {
+			pass.Reportf(pos, "url tag option %q requires a query.TimeRange, not %s", key, t)
+		}
+		if (key == "minor" || key == "currency") && !isNamed(deref(t), queryPath, "Money") {
+			pass.Reportf(pos, "url tag option %q requires a query.Money, not %s", key, t)
+		}
+		if (key == "precision" || key == "lnglat") && !isNamed(et, queryPath, "LatLng") && !isNamed(et, queryPath, "BoundingBox") {
+			pass.Reportf(pos, "url tag option %q requires a query.LatLng or query.BoundingBox, not %s", key, t)
+		}
+	}
+	if contains(options, "file") && !isFile(t) {
+		pass.Reportf(pos, `url tag option "file" requires an io.Reader or []byte, not %s`, t)
+	}
+}
+
+func contains(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// deref returns the type t points to, following pointers.
+func deref(t types.Type) types.Type {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			return t
+		}
+		t = p.Elem()
+	}
+}
+
+// elem returns the type of the individual values encoded for a field of type
+// t, following pointers, slices and arrays.
+func elem(t types.Type) types.Type {
+	for {
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func isBasic(t types.Type, info types.BasicInfo) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&info != 0
+}
+
+func isNamed(t types.Type, pkg, name string) bool {
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == pkg && n.Obj().Name() == name
+}
+
+// isFile reports whether fields of type t may have the "file" option.
+func isFile(t types.Type) bool {
+	if s, ok := deref(t).Underlying().(*types.Slice); ok && isBasic(s.Elem(), types.IsInteger) {
+		b := s.Elem().Underlying().(*types.Basic)
+		return b.Kind() == types.Byte
+	}
+	for {
+		if types.NewMethodSet(t).Lookup(nil, "Read") != nil || types.NewMethodSet(types.NewPointer(t)).Lookup(nil, "Read") != nil {
+			return true
+		}
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			return false
+		}
+		t = p.Elem()
+	}
+}
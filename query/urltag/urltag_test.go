@@ -0,0 +1,115 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package urltag
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// runAnalyzer type checks a package holding a struct type with fields, and returns
+// the messages of the diagnostics reported by Analyzer.
+func runAnalyzer(t *testing.T, fields string) []string {
+	src := "package p\n\nimport (\n\t\"io\"\n\t\"time\"\n)\n\n" +
+		"var _ io.Reader\nvar _ time.Time\n\n" +
+		"type nested struct{ A string }\n\n" +
+		"type s struct {\n" + fields + "\n}\n"
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile returned error: %v", err)
+	}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	var got []string
+	pass := &analysis.Pass{
+		Analyzer:  Analyzer,
+		Fset:      fset,
+		Files:     []*ast.File{f},
+		Pkg:       pkg,
+		TypesInfo: info,
+		Report: func(d analysis.Diagnostic) {
+			got = append(got, d.Message)
+		},
+	}
+	if _, err := Analyzer.Run(pass); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	return got
+}
+
+func TestAnalyzer(t *testing.T) {
+	tests := []struct {
+		fields string
+		want   []string // substrings of the expected diagnostics, in order
+	}{
+		{
+			"Q string `url:\"q,omitempty\"`\n" +
+				"B bool `url:\"b,int\"`\n" +
+				"T time.Time `url:\"t,unix\"`\n" +
+				"S []string `url:\"s,comma\"`\n" +
+				"R io.Reader `url:\"r,file\"`\n" +
+				"D []byte `url:\"d,file\"`\n" +
+				"N nested `url:\"n,style=deepObject\"`\n" +
+				"P int `url:\"p,default=1\"`\n" +
+				"I string `url:\"-\"`\n" +
+				"J string `url:\"-\"`\n" +
+				"q string `url:\"q\"`\n" +
+				"nested",
+			nil,
+		},
+		{"A string `url:\"a,omitemtpy\"`", []string{`unknown url tag option "omitemtpy"`}},
+		{"A string `url:\"a,int=1\"`", []string{`unknown url tag option "int=1"`}},
+		{"A []string `url:\"a,style=pipe\"`", []string{`unknown OpenAPI style "pipe"`}},
+		{"A []string `url:\"a,comma,space\"`", []string{"conflicting url tag options comma, space"}},
+		{"A string `url:\"a,comma\"`", []string{`option "comma" requires a slice or array, not string`}},
+		{
+			"A int `url:\"a,int\"`\nB int64 `url:\"b,unix\"`\nC string `url:\"c,file\"`",
+			[]string{`option "int" requires a bool`, `option "unix" requires a time.Time`, `option "file" requires an io.Reader or []byte`},
+		},
+		{
+			"A time.Time `url:\"a,utc\"`\nB time.Time `url:\"b,tz=UTC\"`\nC string `url:\"c,utc\"`\nD int `url:\"d,tz=UTC\"`",
+			[]string{`option "utc" requires a time.Time`, `option "tz" requires a time.Time`},
+		},
+		{"A time.Time `url:\"a,range,start=b\"`", []string{`option "range" requires a query.TimeRange`, `option "start" requires a query.TimeRange`}},
+		{"A float64 `url:\"a,precision=2\"`", []string{`option "precision" requires a query.LatLng or query.BoundingBox`}},
+		{"A int64 `url:\"a,minor\"`", []string{`option "minor" requires a query.Money`}},
+		{"A string `url:\"a`", []string{"malformed url tag"}},
+		{
+			"A string `url:\"a\"`\nB string `url:\"a\"`\nC, A2 int",
+			[]string{`URL parameter "a" of field B is also produced by field A`},
+		},
+		{
+			"A []string `url:\"a,brackets\"`\nB string `url:\"a[]\"`",
+			[]string{`URL parameter "a[]" of field B is also produced by field A`},
+		},
+	}
+
+	for i, tt := range tests {
+		got := runAnalyzer(t, tt.fields)
+		if len(got) != len(tt.want) {
+			t.Errorf("%d. Analyzer reported %q, want %d diagnostics", i, got, len(tt.want))
+			continue
+		}
+		for j, want := range tt.want {
+			if !strings.Contains(got[j], want) {
+				t.Errorf("%d. diagnostic %d is %q, want it to contain %q", i, j, got[j], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A ValidationError reports a field value breaking a constraint of its tag.
+// Values and the other encoding functions check the following constraints
+// before encoding a field, so that invalid parameters are caught before
+// reaching a server:
+//
+//   - "min=n" and "max=n" options bound numbers, as in
+//     `url:"limit,min=1,max=500"`
+//   - a "len=n" option requires strings to have n characters, and slices,
+//     arrays and maps n elements
+//   - a "pattern" tag holds a regular expression, in the syntax of the
+//     regexp package, that strings must match, as in `pattern:"^[a-z]+$"`
+//
+// Constraints on numbers and patterns apply to each element of slices and
+// arrays.  Fields omitted by the "omitempty" option and nil pointers are not
+// checked.  ValidationErrors are returned wrapped in a *FieldError naming the
+// parameter.
+type ValidationError struct {
+	// Rule is the constraint broken: "min", "max", "len", "pattern", or
+	// "allowed" for values not allowed by WithAllowed.
+	Rule string
+
+	// Limit is the bound, length or pattern required by the tag, or the
+	// allowed values separated by commas.
+	Limit string
+
+	// Value is the offending value, or Redacted for redacted fields.  For
+	// "len", it is the length of the value.
+	Value string
+}
+
+func (e *ValidationError) Error() string {
+	switch e.Rule {
+	case "min":
+		return fmt.Sprintf("value %s is less than the minimum %s", e.Value, e.Limit)
+	case "max":
+		return fmt.Sprintf("value %s is greater than the maximum %s", e.Value, e.Limit)
+	case "len":
+		return fmt.Sprintf("length %s is not %s", e.Value, e.Limit)
+	case "allowed":
+		return fmt.Sprintf("value %q is not one of %s", e.Value, e.Limit)
+	}
+	return fmt.Sprintf("value %q does not match pattern %q", e.Value, e.Limit)
+}
+
+// validate checks the value v of the field sf against the constraints of its
+// tag options opts.
+func (e *encoder) validate(sf reflect.StructField, v reflect.Value, opts tagOptions) error {
+	min, hasMin := opts.Value("min")
+	max, hasMax := opts.Value("max")
+	n, hasLen := opts.Value("len")
+	pattern, hasPattern := sf.Tag.Lookup("pattern")
+	if !hasMin && !hasMax && !hasLen && !hasPattern {
+		return nil
+	}
+
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	if hasLen {
+		if err := checkLen(v, n); err != nil {
+			return e.redactValidation(err)
+		}
+	}
+
+	var check func(reflect.Value) error
+	check = func(v reflect.Value) error {
+		v = reflect.Indirect(v)
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			if v.Type().Elem().Kind() == reflect.Uint8 && !hasMin && !hasMax {
+				return nil
+			}
+			for i := 0; i < v.Len(); i++ {
+				if err := check(v.Index(i)); err != nil {
+					return err
+				}
+			}
+		case reflect.String:
+			if hasPattern {
+				re, err := compilePattern(pattern)
+				if err != nil {
+					return err
+				}
+				if !re.MatchString(v.String()) {
+					return &ValidationError{Rule: "pattern", Limit: pattern, Value: v.String()}
+				}
+			}
+		default:
+			if !isNumberKind(v.Kind()) {
+				return nil
+			}
+			if hasMin {
+				if err := checkBound(v, "min", min); err != nil {
+					return err
+				}
+			}
+			if hasMax {
+				if err := checkBound(v, "max", max); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return e.redactValidation(check(v))
+}
+
+// WithAllowed restricts the values of the parameter name to values, so that
+// values built at run time can be checked against the capabilities of a
+// server, such as the sort orders it supports.  Values of name outside values
+// make Values, and the other encoding functions, return a *ValidationError
+// with the rule "allowed", wrapped in a *FieldError.  Each element of a slice
+// is checked separately, whatever its delimiter; values added by custom
+// Encoders are not checked.  Later calls for the same name replace the
+// allowed values.
+func WithAllowed(name string, values ...string) Option {
+	return func(c *config) {
+		if c.allowed == nil {
+			c.allowed = make(map[string][]string)
+		}
+		c.allowed[name] = values
+	}
+}
+
+// checkAllowed checks that s is allowed for the parameter name, which may
+// have the "[]" suffix of the "brackets" option.
+func (c *config) checkAllowed(name, s string) error {
+	if c.allowed == nil {
+		return nil
+	}
+	allowed, ok := c.allowed[name]
+	if !ok {
+		allowed, ok = c.allowed[strings.TrimSuffix(name, "[]")]
+	}
+	if !ok {
+		return nil
+	}
+	for _, a := range allowed {
+		if s == a {
+			return nil
+		}
+	}
+	return &ValidationError{Rule: "allowed", Limit: strings.Join(allowed, ","), Value: s}
+}
+
+// redactValidation hides the value reported by err if the field being
+// encoded is redacted.
+func (e *encoder) redactValidation(err error) error {
+	if ve, ok := err.(*ValidationError); ok && e.redacting && ve.Rule != "len" {
+		ve.Value = Redacted
+	}
+	return err
+}
+
+// checkLen checks that v, a string, slice, array or map, has length n.
+func checkLen(v reflect.Value, n string) error {
+	want, err := strconv.Atoi(n)
+	if err != nil {
+		return fmt.Errorf("invalid len option %q", n)
+	}
+	var got int
+	switch v.Kind() {
+	case reflect.String:
+		got = utf8.RuneCountInString(v.String())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		got = v.Len()
+	default:
+		return fmt.Errorf("option len does not apply to %v", v.Type())
+	}
+	if got != want {
+		return &ValidationError{Rule: "len", Limit: n, Value: strconv.Itoa(got)}
+	}
+	return nil
+}
+
+// checkBound checks the number v against the bound limit of rule, "min" or
+// "max".
+func checkBound(v reflect.Value, rule, limit string) error {
+	var below, above bool
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		l, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s option %q", rule, limit)
+		}
+		below, above = v.Int() < l, v.Int() > l
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		l, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s option %q", rule, limit)
+		}
+		below, above = v.Uint() < l, v.Uint() > l
+	default:
+		l, err := strconv.ParseFloat(limit, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s option %q", rule, limit)
+		}
+		below, above = v.Float() < l, v.Float() > l
+	}
+	if rule == "min" && below || rule == "max" && above {
+		return &ValidationError{Rule: rule, Limit: limit, Value: fmt.Sprint(v)}
+	}
+	return nil
+}
+
+// patterns caches the regular expressions of pattern tags.
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// compilePattern returns the compiled regular expression of a pattern tag.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type validated struct {
+	Limit  int      `url:"limit,min=1,max=500"`
+	Ratio  float64  `url:"ratio,omitempty,max=1"`
+	Code   string   `url:"code,omitempty,len=3" pattern:"^[A-Z]+$"`
+	IDs    []uint   `url:"id,omitempty,max=99"`
+	Pair   []string `url:"pair,omitempty,len=2"`
+	Secret *string  `url:"secret,redact" pattern:"^[0-9]+$"`
+}
+
+func TestValues_validation(t *testing.T) {
+	secret := "s3cret"
+	tests := []struct {
+		in   validated
+		want *ValidationError // nil if valid
+	}{
+		{validated{Limit: 1}, nil},
+		{validated{Limit: 500, Ratio: 0.5, Code: "ABC", IDs: []uint{1, 99}, Pair: []string{"a", "b"}}, nil},
+		{validated{Limit: 0}, &ValidationError{"min", "1", "0"}},
+		{validated{Limit: 501}, &ValidationError{"max", "500", "501"}},
+		{validated{Limit: 1, Ratio: 1.5}, &ValidationError{"max", "1", "1.5"}},
+		{validated{Limit: 1, Code: "ABCD"}, &ValidationError{"len", "3", "4"}},
+		{validated{Limit: 1, Code: "abc"}, &ValidationError{"pattern", "^[A-Z]+$", "abc"}},
+		{validated{Limit: 1, IDs: []uint{1, 100}}, &ValidationError{"max", "99", "100"}},
+		{validated{Limit: 1, Pair: []string{"a"}}, &ValidationError{"len", "2", "1"}},
+		{validated{Limit: 1, Secret: &secret}, &ValidationError{"pattern", "^[0-9]+$", Redacted}},
+	}
+
+	for i, tt := range tests {
+		_, err := Values(tt.in)
+		var got *ValidationError
+		if err != nil && !errors.As(err, &got) {
+			t.Errorf("%d. Values(%+v) returned error %v, want a *ValidationError", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%+v) returned error %v, want %v", i, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValues_validationCollected(t *testing.T) {
+	in := validated{Limit: 0, Code: "abc"}
+	v, err := Values(in, WithCollectErrors())
+	if err == nil {
+		t.Fatalf("Values(%+v) returned no error", in)
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Name != "limit" {
+		t.Errorf("Values(%+v) returned error %v, want a *FieldError for limit", in, err)
+	}
+	if _, ok := v["limit"]; ok {
+		t.Errorf("Values(%+v) encoded invalid field limit: %v", in, v)
+	}
+	if _, ok := v["code"]; ok {
+		t.Errorf("Values(%+v) encoded invalid field code: %v", in, v)
+	}
+}
+
+func TestValues_allowed(t *testing.T) {
+	type Options struct {
+		Sort   string   `url:"sort,omitempty"`
+		Fields []string `url:"fields,omitempty,comma"`
+		Tags   []string `url:"tag,omitempty,brackets"`
+	}
+	opts := []Option{
+		WithAllowed("sort", "asc", "desc"),
+		WithAllowed("fields", "id", "name"),
+		WithAllowed("tag", "a"),
+	}
+
+	tests := []struct {
+		in   Options
+		want *ValidationError // nil if valid
+	}{
+		{Options{}, nil},
+		{Options{Sort: "asc", Fields: []string{"id", "name"}, Tags: []string{"a"}}, nil},
+		{Options{Sort: "random"}, &ValidationError{"allowed", "asc,desc", "random"}},
+		{Options{Fields: []string{"id", "email"}}, &ValidationError{"allowed", "id,name", "email"}},
+		{Options{Tags: []string{"a", "b"}}, &ValidationError{"allowed", "a", "b"}},
+	}
+
+	for i, tt := range tests {
+		_, err := Values(tt.in, opts...)
+		var got *ValidationError
+		if err != nil && !errors.As(err, &got) {
+			t.Errorf("%d. Values(%+v) returned error %v, want a *ValidationError", i, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d. Values(%+v) returned error %v, want %v", i, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// WithValidator runs fn on the value being encoded before encoding it, so that
+// struct validation libraries can be used alongside url tags.  For
+// github.com/go-playground/validator:
+//
+//	validate := validator.New()
+//	v, err := query.Values(opt, query.WithValidator(validate.Struct))
+//
+// If fn returns an error, encoding stops and the error is returned.  Field
+// errors, that is errors with a StructNamespace method returning the path of
+// the field as in "Options.Filter.State", are wrapped in a *FieldError naming
+// the URL parameter of the field and joined with errors.Join.  They may be
+// returned as a slice of errors, as validator.ValidationErrors is, or joined
+// with errors.Join.  Other errors are returned unchanged.
+func WithValidator(fn func(v interface{}) error) Option {
+	return func(c *config) {
+		c.validator = fn
+	}
+}
+
+// structNamespacer is implemented by the field errors of validators, such as
+// validator.FieldError.
+type structNamespacer interface {
+	error
+	StructNamespace() string
+}
+
+// indexes matches the indexes in struct namespaces, as in "Items[0]".
+var indexes = regexp.MustCompile(`\[[^\]]*\]`)
+
+// validateValue runs the validator of c on v, which is a value of the struct
+// type t.
+func (c *config) validateValue(v interface{}, t reflect.Type) error {
+	err := c.validator(v)
+	if err == nil {
+		return err
+	}
+	fieldErrs := namespacedErrors(err)
+	if fieldErrs == nil {
+		return err
+	}
+
+	params := make(map[string]string)
+	c.fieldParams(params, t, "", "", make(map[reflect.Type]bool))
+	var errs []error
+	for _, fe := range fieldErrs {
+		// Drop the name of the struct type itself and any indexes
+		path := indexes.ReplaceAllString(fe.StructNamespace(), "")
+		if _, rest, ok := strings.Cut(path, "."); ok {
+			path = rest
+		}
+		name, ok := params[path]
+		if !ok {
+			name = path
+		}
+		errs = append(errs, &FieldError{Name: name, Err: fe})
+	}
+	return errors.Join(errs...)
+}
+
+// namespacedErrors returns the field errors held by err, or nil if it holds
+// other errors.
+func namespacedErrors(err error) []structNamespacer {
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else if v := reflect.ValueOf(err); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			e, ok := v.Index(i).Interface().(error)
+			if !ok {
+				return nil
+			}
+			errs = append(errs, e)
+		}
+	} else {
+		errs = []error{err}
+	}
+
+	var fieldErrs []structNamespacer
+	for _, e := range errs {
+		fe, ok := e.(structNamespacer)
+		if !ok {
+			return nil
+		}
+		fieldErrs = append(fieldErrs, fe)
+	}
+	return fieldErrs
+}
+
+// fieldParams maps the Go paths of the fields of the struct type t, prefixed
+// by prefix, to their URL parameter names scoped in scope.  visiting holds the
+// struct types being walked, to stop at recursive types.
+func (c *config) fieldParams(params map[string]string, t reflect.Type, prefix, scope string, visiting map[reflect.Type]bool) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	c.walkFields(t, func(f typeField) {
+		path := goPath(t, f.index)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		name := c.scopedName(scope, f.name)
+		params[path] = name
+
+		ft := indirectType(f.sf.Type)
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			ft = indirectType(ft.Elem())
+		}
+		if nestedStructType(ft) {
+			c.fieldParams(params, ft, path, name, visiting)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+// testFieldError and testValidationErrors mimic the errors of
+// github.com/go-playground/validator.
+type testFieldError struct {
+	ns, tag string
+}
+
+func (e testFieldError) Error() string           { return "failed on the '" + e.tag + "' tag" }
+func (e testFieldError) StructNamespace() string { return e.ns }
+
+type testValidationErrors []testFieldError
+
+func (ve testValidationErrors) Error() string { return "validation failed" }
+
+func TestValues_validator(t *testing.T) {
+	type Filter struct {
+		State string `url:"state"`
+	}
+	type Options struct {
+		Q       string   `url:"q"`
+		Filter  Filter   `url:"filter"`
+		Filters []Filter `url:"filters"`
+	}
+
+	tests := []struct {
+		err  error
+		want string // the expected error, or "" for none
+	}{
+		{nil, ""},
+		{errors.New("bad input"), "bad input"},
+		{
+			testValidationErrors{{"Options.Q", "required"}, {"Options.Filter.State", "oneof"}},
+			"query: parameter \"q\": failed on the 'required' tag\n" +
+				"query: parameter \"filter[state]\": failed on the 'oneof' tag",
+		},
+		{
+			errors.Join(testFieldError{"Options.Filters[1].State", "oneof"}, testFieldError{"Options.Other", "max"}),
+			"query: parameter \"filters[state]\": failed on the 'oneof' tag\n" +
+				"query: parameter \"Other\": failed on the 'max' tag",
+		},
+	}
+
+	for i, tt := range tests {
+		var validated interface{}
+		validate := func(v interface{}) error {
+			validated = v
+			return tt.err
+		}
+		in := &Options{Q: "go"}
+		_, err := Values(in, WithValidator(validate))
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%d. Values returned error %q, want %q", i, got, tt.want)
+		}
+		if validated != in {
+			t.Errorf("%d. validator called with %v, want %v", i, validated, in)
+		}
+	}
+
+	// field errors keep their type
+	_, err := Values(Options{}, WithValidator(func(interface{}) error {
+		return testValidationErrors{{"Options.Q", "required"}}
+	}))
+	var fe testFieldError
+	if !errors.As(err, &fe) || fe.tag != "required" {
+		t.Errorf("Values returned error %v, want one wrapping the field error", err)
+	}
+}